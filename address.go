@@ -179,15 +179,7 @@ func (addr *Address) UnmarshalJSON(b []byte) error {
 
 // Bytes returns the CBOR encoding of the Address as bytes.
 func (addr *Address) Bytes() []byte {
-	var networkByte uint8
-	switch addr.Network {
-	case Testnet, Preprod:
-		networkByte = 0
-	case Mainnet:
-		networkByte = 1
-	}
-
-	addrBytes := []byte{byte(addr.Type<<4) | (networkByte & 0xFF)}
+	addrBytes := []byte{byte(addr.Type<<4) | addr.Network.ID()}
 	switch addr.Type {
 	case Base, Base + 1, Base + 2, Base + 3:
 		addrBytes = append(addrBytes, addr.Payment.Hash()...)
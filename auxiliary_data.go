@@ -1,9 +1,11 @@
 package cardano
 
 import (
+	"bytes"
 	"reflect"
 
 	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+	"golang.org/x/crypto/blake2b"
 )
 
 // Metadata represents the transaction metadata.
@@ -14,10 +16,42 @@ type AuxiliaryData struct {
 	Metadata      Metadata `cbor:"0,keyasint,omitempty"`
 	NativeScripts any      `cbor:"1,keyasint,omitempty"`
 	PlutusScripts any      `cbor:"2,keyasint,omitempty"`
+
+	// raw is the original encoding of decoded auxiliary data.
+	raw []byte
+	// encoded is the re-encoding of the decoded auxiliary data, used to detect
+	// changes.
+	encoded []byte
 }
 
 // MarshalCBOR implements cbor.Marshaler
+//
+// Decoded auxiliary data are encoded as received unless they were modified,
+// as the body hashes their original encoding.
 func (d *AuxiliaryData) MarshalCBOR() ([]byte, error) {
+	encoded, err := d.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if d.raw != nil && bytes.Equal(encoded, d.encoded) {
+		return d.raw, nil
+	}
+	return encoded, nil
+}
+
+// Hash returns the auxiliary data hash using blake2b256. The hash of decoded
+// auxiliary data covers their original encoding.
+func (d *AuxiliaryData) Hash() (Hash32, error) {
+	data, err := d.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+	hash := blake2b.Sum256(data)
+	return hash[:], nil
+}
+
+// marshal returns the encoding of the auxiliary data as a tagged map.
+func (d *AuxiliaryData) marshal() ([]byte, error) {
 	type auxiliaryData AuxiliaryData
 
 	// Register tag 259 for maps
@@ -34,10 +68,46 @@ func (d *AuxiliaryData) MarshalCBOR() ([]byte, error) {
 	return em.Marshal(auxiliaryData(*d))
 }
 
-// UnmarshalCBOR implements cbor.Unmarshaler
+// UnmarshalCBOR implements cbor.Unmarshaler. Besides the tagged map, the
+// Shelley format, a metadata map, and the Allegra format, an array of the
+// metadata and the native scripts, are accepted.
 func (d *AuxiliaryData) UnmarshalCBOR(data []byte) error {
+	if err := d.unmarshal(data); err != nil {
+		return err
+	}
+	encoded, err := d.marshal()
+	if err != nil {
+		return err
+	}
+	d.raw = append([]byte{}, data...)
+	d.encoded = encoded
+	return nil
+}
+
+func (d *AuxiliaryData) unmarshal(data []byte) error {
 	type auxiliaryData AuxiliaryData
 
+	if len(data) > 0 {
+		switch data[0] >> 5 {
+		case 5: // map
+			return cborDec.Unmarshal(data, &d.Metadata)
+		case 4: // array
+			var aux struct {
+				_             struct{} `cbor:",toarray"`
+				Metadata      Metadata
+				NativeScripts []NativeScript
+			}
+			if err := cborDec.Unmarshal(data, &aux); err != nil {
+				return err
+			}
+			d.Metadata = aux.Metadata
+			if len(aux.NativeScripts) > 0 {
+				d.NativeScripts = aux.NativeScripts
+			}
+			return nil
+		}
+	}
+
 	// Register tag 259 for maps
 	tags, err := d.tagSet(auxiliaryData{})
 	if err != nil {
@@ -0,0 +1,265 @@
+package cardano
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TxOutcome is the outcome of waiting for a transaction to be confirmed.
+type TxOutcome int
+
+const (
+	// TxConfirmed is the outcome of a transaction in a block buried under the
+	// confirmation depth.
+	TxConfirmed TxOutcome = iota
+
+	// TxExpired is the outcome of a transaction not in a block at the end of
+	// its validity interval, it can no longer be included.
+	TxExpired
+
+	// TxRolledBack is the outcome of a transaction seen in a block and then
+	// rolled back before reaching the confirmation depth. It may be included
+	// again while its validity interval is not over.
+	TxRolledBack
+)
+
+// String implements fmt.Stringer.
+func (o TxOutcome) String() string {
+	switch o {
+	case TxConfirmed:
+		return "confirmed"
+	case TxExpired:
+		return "expired"
+	case TxRolledBack:
+		return "rolled back"
+	default:
+		return "unknown"
+	}
+}
+
+// TxConfirmation is the result of waiting for a transaction to be confirmed.
+type TxConfirmation struct {
+	TxHash  Hash32
+	Outcome TxOutcome
+
+	// Block and Slot are the number and slot of the block including the
+	// transaction when confirmed, Slot is zero if unknown to the node.
+	Block         uint64
+	Slot          uint64
+	Confirmations uint64
+}
+
+// AwaitOptions are the options of AwaitTx.
+type AwaitOptions struct {
+	// Depth is the number of confirmations awaited, the block including the
+	// transaction being the first one, 1 if zero.
+	Depth uint64
+
+	// PollInterval is the interval between queries to the node, 5 seconds
+	// if zero.
+	PollInterval time.Duration
+}
+
+const defaultPollInterval = 5 * time.Second
+
+// SubmitAndAwait submits a transaction to the node and waits for its
+// confirmation, see AwaitTx.
+func SubmitAndAwait(ctx context.Context, node Node, tx *Tx, opts *AwaitOptions) (*TxConfirmation, error) {
+	if _, err := node.SubmitTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	return AwaitTx(ctx, node, tx, opts)
+}
+
+// ErrConfirmationUnknown is returned by AwaitTx when the transaction seen in a
+// block is no longer found and the node cannot tell whether it was rolled
+// back or its outputs spent.
+var ErrConfirmationUnknown = errors.New("transaction confirmation cannot be determined")
+
+// AwaitTx polls the node until the transaction is confirmed at the depth,
+// expires or is rolled back, or the context is done.
+//
+// The inclusion is read from the node if it supports CapabilityTxStatus.
+// Otherwise the transaction is found in the unspent outputs at the addresses
+// of its outputs, and the block including it is the tip of the chain when
+// first found. Once its outputs are all spent, the transaction is rolled back
+// if one of its inputs is unspent again, and still in a block if none is. The
+// inputs are resolved by reference if the node supports CapabilityUTxOByRef,
+// or else at the address of their Spender, and ErrConfirmationUnknown is
+// returned if they cannot be.
+func AwaitTx(ctx context.Context, node Node, tx *Tx, opts *AwaitOptions) (*TxConfirmation, error) {
+	depth, interval := uint64(1), defaultPollInterval
+	if opts != nil {
+		if opts.Depth > 0 {
+			depth = opts.Depth
+		}
+		if opts.PollInterval > 0 {
+			interval = opts.PollInterval
+		}
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	poller := &txPoller{node: node, tx: tx, txHash: txHash}
+
+	for {
+		status, err := poller.status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case status.Confirmed && status.Confirmations >= depth:
+			return &TxConfirmation{
+				TxHash:        txHash,
+				Outcome:       TxConfirmed,
+				Block:         status.Block,
+				Slot:          status.Slot,
+				Confirmations: status.Confirmations,
+			}, nil
+		case status.Confirmed:
+			poller.seen = true
+		case poller.seen:
+			return &TxConfirmation{TxHash: txHash, Outcome: TxRolledBack}, nil
+		default:
+			expired, err := poller.expired(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if expired {
+				return &TxConfirmation{TxHash: txHash, Outcome: TxExpired}, nil
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// txPoller queries the inclusion of a transaction.
+type txPoller struct {
+	node   Node
+	tx     *Tx
+	txHash Hash32
+
+	seen      bool
+	seenBlock uint64 // tip when first found, without TxStatusNode
+}
+
+func (p *txPoller) status(ctx context.Context) (*TxStatus, error) {
+	if Supports(p.node, CapabilityTxStatus) {
+		return p.node.(TxStatusNode).TxStatus(ctx, p.txHash)
+	}
+
+	if len(p.tx.Body.Outputs) == 0 {
+		return nil, errors.New("cannot await a transaction without outputs")
+	}
+	status := &TxStatus{TxHash: p.txHash}
+	found, err := p.outputsUnspent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !found && p.seen {
+		// the outputs are spent, or the transaction rolled back
+		rolledBack, err := p.inputsUnspent(ctx)
+		if err != nil {
+			return nil, err
+		}
+		found = !rolledBack
+	}
+	if !found {
+		return status, nil
+	}
+	status.Confirmed = true
+
+	tip, err := p.node.Tip(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !p.seen || tip.Block < p.seenBlock {
+		p.seenBlock = tip.Block
+	}
+	status.Block = p.seenBlock
+	status.Confirmations = tip.Block - p.seenBlock + 1
+	return status, nil
+}
+
+// outputsUnspent reports whether an output of the transaction is unspent.
+func (p *txPoller) outputsUnspent(ctx context.Context) (bool, error) {
+	queried := make(map[string]bool)
+	for _, output := range p.tx.Body.Outputs {
+		addr := output.Address.String()
+		if queried[addr] {
+			continue
+		}
+		queried[addr] = true
+		utxos, err := p.node.UTxOs(ctx, output.Address)
+		if err != nil {
+			return false, err
+		}
+		for _, utxo := range utxos {
+			if utxo.TxHash.String() == p.txHash.String() {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// inputsUnspent reports whether an input of the transaction is unspent, the
+// transaction not being in a block.
+func (p *txPoller) inputsUnspent(ctx context.Context) (bool, error) {
+	byRef := Supports(p.node, CapabilityUTxOByRef)
+	for _, input := range p.tx.Body.Inputs {
+		if byRef {
+			_, err := p.node.(UTxOByRefNode).UTxOByRef(ctx, input.TxHash, input.Index)
+			if err == nil {
+				return true, nil
+			}
+			if !errors.Is(err, ErrNotFound) {
+				return false, err
+			}
+			continue
+		}
+		if input.Spender == nil {
+			return false, ErrConfirmationUnknown
+		}
+		utxos, err := p.node.UTxOs(ctx, *input.Spender)
+		if err != nil {
+			return false, err
+		}
+		for _, utxo := range utxos {
+			if utxo.TxHash.String() == input.TxHash.String() && utxo.Index == input.Index {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// expired reports whether the transaction, not in a block, is past its
+// validity interval.
+func (p *txPoller) expired(ctx context.Context) (bool, error) {
+	if p.tx.Body.TTL == nil {
+		return false, nil
+	}
+	tip, err := p.node.Tip(ctx)
+	if err != nil {
+		return false, err
+	}
+	if tip.Slot < *p.tx.Body.TTL {
+		return false, nil
+	}
+	// the transaction may have been included since its status was queried
+	status, err := p.status(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !status.Confirmed, nil
+}
@@ -0,0 +1,215 @@
+package cardano
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// pollNode is a Node answering the polls of AwaitTx from scripted states, the
+// last state being repeated.
+type pollNode struct {
+	tx     *Tx
+	states []pollState
+	polls  int
+}
+
+type pollState struct {
+	included bool
+	block    uint64
+	slot     uint64
+}
+
+// poll returns the state of the next poll.
+func (n *pollNode) poll() pollState {
+	state := n.states[min(n.polls, len(n.states)-1)]
+	n.polls++
+	return state
+}
+
+func (n *pollNode) UTxOs(_ context.Context, addr Address) ([]UTxO, error) {
+	if !n.poll().included {
+		return nil, nil
+	}
+	txHash, err := n.tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return []UTxO{{TxHash: txHash, Spender: addr, Amount: n.tx.Body.Outputs[0].Amount}}, nil
+}
+
+// Tip returns the tip of the last poll.
+func (n *pollNode) Tip(context.Context) (*NodeTip, error) {
+	state := n.states[min(max(n.polls-1, 0), len(n.states)-1)]
+	return &NodeTip{Block: state.block, Slot: state.slot}, nil
+}
+
+func (n *pollNode) SubmitTx(context.Context, *Tx) (*Hash32, error) {
+	txHash, err := n.tx.Hash()
+	return &txHash, err
+}
+
+func (n *pollNode) ProtocolParams(context.Context) (*ProtocolParams, error) {
+	return alonzoProtocol, nil
+}
+
+func (n *pollNode) Network() Network {
+	return Testnet
+}
+
+// statusNode is a pollNode implementing TxStatusNode.
+type statusNode struct {
+	*pollNode
+}
+
+func (n *statusNode) TxStatus(_ context.Context, txHash Hash32) (*TxStatus, error) {
+	state := n.poll()
+	status := &TxStatus{TxHash: txHash}
+	if state.included {
+		status.Confirmed = true
+		status.Block = state.block
+		status.Confirmations = 1
+	}
+	return status, nil
+}
+
+// unsupportedNode is a pollNode implementing TxStatusNode without supporting
+// it, as a wrapper of a node without TxStatus.
+type unsupportedNode struct {
+	*pollNode
+}
+
+func (n *unsupportedNode) TxStatus(context.Context, Hash32) (*TxStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (n *unsupportedNode) Supports(capability Capability) bool {
+	return capability != CapabilityTxStatus
+}
+
+// byRefNode is a pollNode implementing UTxOByRefNode, the inputs of the
+// transaction being unspent or not.
+type byRefNode struct {
+	*pollNode
+	inputsUnspent bool
+}
+
+func (n *byRefNode) UTxOByRef(_ context.Context, txHash Hash32, index uint64) (*UTxO, error) {
+	if !n.inputsUnspent {
+		return nil, ErrNotFound
+	}
+	return &UTxO{TxHash: txHash, Index: index}, nil
+}
+
+func TestAwaitTx(t *testing.T) {
+	ctx := context.Background()
+	tx := newBlockTestTx(t)
+	tx.Body.TTL = NewUint64(100)
+	opts := &AwaitOptions{Depth: 2, PollInterval: time.Millisecond}
+
+	testcases := []struct {
+		name      string
+		node      Node
+		want      TxOutcome
+		wantBlock uint64
+		wantConfs uint64
+	}{
+		{
+			name: "Confirmed",
+			node: &pollNode{tx: tx, states: []pollState{
+				{block: 10, slot: 50},
+				{included: true, block: 11, slot: 60},
+				{included: true, block: 12, slot: 70},
+			}},
+			want:      TxConfirmed,
+			wantBlock: 11,
+			wantConfs: 2,
+		},
+		{
+			name: "Expired",
+			node: &pollNode{tx: tx, states: []pollState{
+				{block: 10, slot: 50},
+				{block: 11, slot: 100},
+			}},
+			want: TxExpired,
+		},
+		{
+			name: "RolledBack",
+			node: &statusNode{&pollNode{tx: tx, states: []pollState{
+				{included: true, block: 11},
+				{},
+			}}},
+			want: TxRolledBack,
+		},
+		{
+			name: "OutputsSpent",
+			node: &byRefNode{pollNode: &pollNode{tx: tx, states: []pollState{
+				{included: true, block: 11, slot: 60},
+				{block: 12, slot: 70},
+			}}},
+			want:      TxConfirmed,
+			wantBlock: 11,
+			wantConfs: 2,
+		},
+		{
+			name: "InputsUnspent",
+			node: &byRefNode{pollNode: &pollNode{tx: tx, states: []pollState{
+				{included: true, block: 11, slot: 60},
+				{block: 11, slot: 70},
+			}}, inputsUnspent: true},
+			want: TxRolledBack,
+		},
+		{
+			name: "StatusUnsupported",
+			node: &unsupportedNode{&pollNode{tx: tx, states: []pollState{
+				{included: true, block: 11, slot: 60},
+				{included: true, block: 12, slot: 70},
+			}}},
+			want:      TxConfirmed,
+			wantBlock: 11,
+			wantConfs: 2,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := AwaitTx(ctx, tc.node, tx, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Outcome != tc.want {
+				t.Fatalf("invalid outcome: got %v want %v", got.Outcome, tc.want)
+			}
+			if got.Block != tc.wantBlock || got.Confirmations != tc.wantConfs {
+				t.Errorf("invalid confirmation: got block %v confirmations %v want block %v confirmations %v", got.Block, got.Confirmations, tc.wantBlock, tc.wantConfs)
+			}
+		})
+	}
+}
+
+func TestAwaitTxConfirmationUnknown(t *testing.T) {
+	tx := newBlockTestTx(t)
+	tx.Body.Inputs[0].Spender = nil
+	node := &pollNode{tx: tx, states: []pollState{
+		{included: true, block: 11, slot: 60},
+		{block: 11, slot: 70},
+	}}
+
+	_, err := AwaitTx(context.Background(), node, tx, &AwaitOptions{Depth: 2, PollInterval: time.Millisecond})
+	if !errors.Is(err, ErrConfirmationUnknown) {
+		t.Errorf("invalid error: got %v want %v", err, ErrConfirmationUnknown)
+	}
+}
+
+func TestAwaitTxCancel(t *testing.T) {
+	tx := newBlockTestTx(t)
+	node := &pollNode{tx: tx, states: []pollState{{block: 10}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := AwaitTx(ctx, node, tx, &AwaitOptions{PollInterval: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("invalid error: got %v want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,260 @@
+package cardano
+
+import (
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Point is a point of the chain, the slot and the hash of a block.
+// The zero Point is the origin of the chain.
+type Point struct {
+	Slot uint64
+	Hash Hash32
+}
+
+// NewPoint returns the point of the block with the given hex encoded hash.
+func NewPoint(slot uint64, hash string) (Point, error) {
+	h, err := NewHash32(hash)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{Slot: slot, Hash: h}, nil
+}
+
+// IsOrigin reports whether the point is the origin of the chain.
+func (p Point) IsOrigin() bool {
+	return p.Hash == nil
+}
+
+// String implements Stringer.
+func (p Point) String() string {
+	if p.IsOrigin() {
+		return "origin"
+	}
+	return fmt.Sprintf("%v.%v", p.Slot, p.Hash)
+}
+
+// MarshalCBOR implements cbor.Marshaler, the origin is encoded as an empty array.
+func (p Point) MarshalCBOR() ([]byte, error) {
+	if p.IsOrigin() {
+		return cborEnc.Marshal([]any{})
+	}
+	return cborEnc.Marshal([]any{p.Slot, []byte(p.Hash)})
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (p *Point) UnmarshalCBOR(data []byte) error {
+	var raw []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch len(raw) {
+	case 0:
+		*p = Point{}
+		return nil
+	case 2:
+		var hash []byte
+		if err := cborDec.Unmarshal(raw[0], &p.Slot); err != nil {
+			return err
+		}
+		if err := cborDec.Unmarshal(raw[1], &hash); err != nil {
+			return err
+		}
+		p.Hash = hash
+		return nil
+	default:
+		return fmt.Errorf("invalid point of length %v", len(raw))
+	}
+}
+
+// Block is a block of the chain.
+type Block struct {
+	Era      Era
+	Hash     Hash32
+	PrevHash Hash32 // nil for the first block of the chain
+	Number   uint64
+	Slot     uint64
+
+	// Txs are the transactions of the block, the transactions of the Byron
+	// blocks are not decoded.
+	Txs []*Tx
+
+	// TxHashes are the hashes of the transactions, as encoded in the block.
+	TxHashes []Hash32
+}
+
+// Point returns the point of the block.
+func (b *Block) Point() Point {
+	return Point{Slot: b.Slot, Hash: b.Hash}
+}
+
+// byronEpochLength is the number of slots of the Byron epochs on the networks
+// started in the Byron era, by protocol magic.
+var byronEpochLength = map[uint32]uint64{
+	MainnetProtocolMagic: 21600,
+	PreprodProtocolMagic: 21600,
+}
+
+// NewBlockFromCBOR decodes a block of the era from its CBOR encoding.
+func NewBlockFromCBOR(era Era, data []byte) (*Block, error) {
+	var raw []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) < 3 {
+		return nil, fmt.Errorf("invalid block of length %v", len(raw))
+	}
+	if era == ByronEra {
+		return newByronBlock(raw[0])
+	}
+	if len(raw) < 4 {
+		return nil, fmt.Errorf("invalid %v block of length %v", era, len(raw))
+	}
+
+	var header struct {
+		_         struct{} `cbor:",toarray"`
+		Body      []cbor.RawMessage
+		Signature cbor.RawMessage
+	}
+	if err := cborDec.Unmarshal(raw[0], &header); err != nil {
+		return nil, err
+	}
+	if len(header.Body) < 3 {
+		return nil, fmt.Errorf("invalid block header")
+	}
+	hash := blake2b.Sum256(raw[0])
+	block := &Block{Era: era, Hash: hash[:]}
+	if err := cborDec.Unmarshal(header.Body[0], &block.Number); err != nil {
+		return nil, err
+	}
+	if err := cborDec.Unmarshal(header.Body[1], &block.Slot); err != nil {
+		return nil, err
+	}
+	var prevHash []byte
+	if err := cborDec.Unmarshal(header.Body[2], &prevHash); err != nil {
+		return nil, err
+	}
+	if prevHash != nil {
+		block.PrevHash = prevHash
+	}
+
+	var bodies, witnesses []cbor.RawMessage
+	var auxData map[uint64]cbor.RawMessage
+	var invalid []uint64
+	if err := cborDec.Unmarshal(raw[1], &bodies); err != nil {
+		return nil, err
+	}
+	if err := cborDec.Unmarshal(raw[2], &witnesses); err != nil {
+		return nil, err
+	}
+	if err := cborDec.Unmarshal(raw[3], &auxData); err != nil {
+		return nil, err
+	}
+	if len(raw) > 4 {
+		if err := cborDec.Unmarshal(raw[4], &invalid); err != nil {
+			return nil, err
+		}
+	}
+	if len(bodies) != len(witnesses) {
+		return nil, fmt.Errorf("invalid block: %v transaction bodies for %v witness sets", len(bodies), len(witnesses))
+	}
+
+	for i := range bodies {
+		tx := &Tx{IsValid: true}
+		if err := cborDec.Unmarshal(bodies[i], &tx.Body); err != nil {
+			return nil, fmt.Errorf("invalid transaction %v: %w", i, err)
+		}
+		if err := cborDec.Unmarshal(witnesses[i], &tx.WitnessSet); err != nil {
+			return nil, fmt.Errorf("invalid witness set %v: %w", i, err)
+		}
+		if aux, ok := auxData[uint64(i)]; ok {
+			tx.AuxiliaryData = &AuxiliaryData{}
+			if err := tx.AuxiliaryData.UnmarshalCBOR(aux); err != nil {
+				return nil, fmt.Errorf("invalid auxiliary data %v: %w", i, err)
+			}
+		}
+		for _, index := range invalid {
+			if index == uint64(i) {
+				tx.IsValid = false
+			}
+		}
+		txHash := blake2b.Sum256(bodies[i])
+		block.Txs = append(block.Txs, tx)
+		block.TxHashes = append(block.TxHashes, txHash[:])
+	}
+	return block, nil
+}
+
+// newByronBlock decodes the header of a Byron block, a main block or an
+// epoch boundary block. The hash of a Byron block is the hash of its header
+// prefixed by its kind.
+func newByronBlock(data cbor.RawMessage) (*Block, error) {
+	var header []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if len(header) != 5 {
+		return nil, fmt.Errorf("invalid byron block header")
+	}
+	var magic uint32
+	var prevHash []byte
+	var consensus []cbor.RawMessage
+	if err := cborDec.Unmarshal(header[0], &magic); err != nil {
+		return nil, err
+	}
+	if err := cborDec.Unmarshal(header[1], &prevHash); err != nil {
+		return nil, err
+	}
+	if err := cborDec.Unmarshal(header[3], &consensus); err != nil {
+		return nil, err
+	}
+	epochLength, ok := byronEpochLength[magic]
+	if !ok {
+		return nil, fmt.Errorf("unknown byron epoch length for protocol magic %v", magic)
+	}
+
+	var kind, epoch, slot uint64
+	var difficulty []uint64
+	switch len(consensus) {
+	case 2: // epoch boundary block
+		if err := cborDec.Unmarshal(consensus[0], &epoch); err != nil {
+			return nil, err
+		}
+		if err := cborDec.Unmarshal(consensus[1], &difficulty); err != nil {
+			return nil, err
+		}
+	case 4: // main block
+		kind = 1
+		var slotID []uint64
+		if err := cborDec.Unmarshal(consensus[0], &slotID); err != nil {
+			return nil, err
+		}
+		if len(slotID) != 2 {
+			return nil, fmt.Errorf("invalid byron slot id")
+		}
+		epoch, slot = slotID[0], slotID[1]
+		if err := cborDec.Unmarshal(consensus[2], &difficulty); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid byron consensus data")
+	}
+	if len(difficulty) != 1 {
+		return nil, fmt.Errorf("invalid byron difficulty")
+	}
+
+	prefixed, err := cborEnc.Marshal([]any{kind, data})
+	if err != nil {
+		return nil, err
+	}
+	hash := blake2b.Sum256(prefixed)
+	return &Block{
+		Era:      ByronEra,
+		Hash:     hash[:],
+		PrevHash: prevHash,
+		Number:   difficulty[0],
+		Slot:     epoch*epochLength + slot,
+	}, nil
+}
@@ -0,0 +1,203 @@
+package cardano
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+	"golang.org/x/crypto/blake2b"
+)
+
+func newBlockTestTx(t *testing.T) *Tx {
+	t.Helper()
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBuilder := NewTxBuilder(alonzoProtocol)
+	txBuilder.AddInputs(NewTxInput(txHash, 0, NewValue(10e6)))
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.Sign(paymentKey.PrvKey())
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestBlockCBORDecoding(t *testing.T) {
+	tx := newBlockTestTx(t)
+	wantTxHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevHash := bytes.Repeat([]byte{1}, 32)
+	header, err := cborEnc.Marshal([]any{
+		[]any{42, 1000, prevHash, []byte{}, []byte{}, []any{}, 100, []byte{}, []any{}, []any{10, 0}},
+		[]byte{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shelleyMetadata, err := cborEnc.Marshal(map[uint]any{674: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := cborEnc.Marshal([]any{
+		cbor.RawMessage(header),
+		[]any{&tx.Body, &tx.Body},
+		[]any{&tx.WitnessSet, &tx.WitnessSet},
+		map[uint64]any{1: cbor.RawMessage(shelleyMetadata)},
+		[]uint64{1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := NewBlockFromCBOR(ConwayEra, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash := blake2b.Sum256(header)
+	if got, want := block.Hash.String(), Hash32(wantHash[:]).String(); got != want {
+		t.Errorf("invalid block hash: got %v want %v", got, want)
+	}
+	if got, want := block.Point(), (Point{Slot: 1000, Hash: wantHash[:]}); got.String() != want.String() {
+		t.Errorf("invalid point: got %v want %v", got, want)
+	}
+	if block.Number != 42 || !bytes.Equal(block.PrevHash, prevHash) {
+		t.Errorf("invalid block header: got %+v", block)
+	}
+	if got, want := len(block.Txs), 2; got != want {
+		t.Fatalf("invalid number of txs: got %v want %v", got, want)
+	}
+	for i, txHash := range block.TxHashes {
+		if got, want := txHash.String(), wantTxHash.String(); got != want {
+			t.Errorf("invalid tx %v hash: got %v want %v", i, got, want)
+		}
+	}
+	if !block.Txs[0].IsValid || block.Txs[1].IsValid {
+		t.Errorf("invalid validity flags: got %v and %v", block.Txs[0].IsValid, block.Txs[1].IsValid)
+	}
+	if block.Txs[1].AuxiliaryData == nil || block.Txs[1].AuxiliaryData.Metadata[674] != "hello" {
+		t.Errorf("invalid auxiliary data: got %+v", block.Txs[1].AuxiliaryData)
+	}
+}
+
+func TestByronBlockCBORDecoding(t *testing.T) {
+	prevHash := bytes.Repeat([]byte{1}, 32)
+	testcases := []struct {
+		name       string
+		magic      uint32
+		consensus  []any
+		kind       uint64
+		wantSlot   uint64
+		wantNumber uint64
+		wantErr    bool
+	}{
+		{
+			name:       "Main",
+			magic:      MainnetProtocolMagic,
+			consensus:  []any{[]any{2, 100}, []byte{}, []any{5000}, []any{}},
+			kind:       1,
+			wantSlot:   2*21600 + 100,
+			wantNumber: 5000,
+		},
+		{
+			name:       "EpochBoundary",
+			magic:      PreprodProtocolMagic,
+			consensus:  []any{3, []any{6000}},
+			wantSlot:   3 * 21600,
+			wantNumber: 6000,
+		},
+		{
+			name:      "UnknownMagic",
+			magic:     42,
+			consensus: []any{3, []any{6000}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			header, err := cborEnc.Marshal([]any{tc.magic, prevHash, []any{}, tc.consensus, []any{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := cborEnc.Marshal([]any{cbor.RawMessage(header), []any{}, []any{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			block, err := NewBlockFromCBOR(ByronEra, data)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			prefixed, err := cborEnc.Marshal([]any{tc.kind, cbor.RawMessage(header)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantHash := blake2b.Sum256(prefixed)
+			if got, want := block.Hash.String(), Hash32(wantHash[:]).String(); got != want {
+				t.Errorf("invalid block hash: got %v want %v", got, want)
+			}
+			if block.Slot != tc.wantSlot || block.Number != tc.wantNumber {
+				t.Errorf("invalid block: got slot %v number %v want slot %v number %v", block.Slot, block.Number, tc.wantSlot, tc.wantNumber)
+			}
+		})
+	}
+}
+
+func TestPointCBOREncoding(t *testing.T) {
+	point, err := NewPoint(1000, "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []Point{{}, point} {
+		data, err := cborEnc.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Point
+		if err := cborDec.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("invalid point: got %v want %v", got, want)
+		}
+	}
+}
+
+func TestShelleyTxDecoding(t *testing.T) {
+	tx := newBlockTestTx(t)
+	data, err := cborEnc.Marshal([]any{&tx.Body, &tx.WitnessSet, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Tx{}
+	if err := got.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	gotHash, err := got.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotHash.String() != wantHash.String() || !got.IsValid || got.AuxiliaryData != nil {
+		t.Errorf("invalid tx: got %+v", got)
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/blockfrost/blockfrost-go"
 	"github.com/cryptogarageinc/cardano-go"
@@ -21,8 +27,20 @@ type BlockfrostNode struct {
 	server    string
 }
 
+// ErrNotFound is returned when Blockfrost knows nothing of a lookup.
+var ErrNotFound = fmt.Errorf("blockfrost: %w", cardano.ErrNotFound)
+
 // check interface
-var _ cardano.Node = (*BlockfrostNode)(nil)
+var (
+	_ cardano.Node               = (*BlockfrostNode)(nil)
+	_ cardano.UTxOByRefNode      = (*BlockfrostNode)(nil)
+	_ cardano.TxStatusNode       = (*BlockfrostNode)(nil)
+	_ cardano.AccountInfoNode    = (*BlockfrostNode)(nil)
+	_ cardano.DatumByHashNode    = (*BlockfrostNode)(nil)
+	_ cardano.ScriptByHashNode   = (*BlockfrostNode)(nil)
+	_ cardano.EvaluateTxNode     = (*BlockfrostNode)(nil)
+	_ cardano.AddressHistoryNode = (*BlockfrostNode)(nil)
+)
 
 // NewNode returns a new instance of BlockfrostNode.
 func NewNode(network cardano.Network, projectID string) *BlockfrostNode {
@@ -49,71 +67,150 @@ func (b *BlockfrostNode) UTxOs(ctx context.Context, addr cardano.Address) ([]car
 	butxos, err := b.addressUTXOsAll(ctx, addr.Bech32(), blockfrost.APIQueryParams{})
 	if err != nil {
 		// Addresses without UTXOs return NotFound error
-		if err, ok := err.(*blockfrost.APIError); ok {
-			if _, ok := err.Response.(blockfrost.NotFound); ok {
-				return []cardano.UTxO{}, nil
-			}
+		if isNotFound(err) {
+			return []cardano.UTxO{}, nil
 		}
 		return nil, err
 	}
 
 	utxos := make([]cardano.UTxO, len(butxos))
-
+	scripts := map[string]*cardano.ScriptRef{}
 	for i, butxo := range butxos {
 		txHash, err := cardano.NewHash32(butxo.TxHash)
 		if err != nil {
 			return nil, err
 		}
-
-		amount := cardano.NewValue(0)
-		for _, a := range butxo.Amount {
-			if a.Unit == "lovelace" {
-				lovelace, err := strconv.ParseUint(a.Quantity, 10, 64)
-				if err != nil {
-					return nil, err
-				}
-				amount.Coin += cardano.Coin(lovelace)
-			} else {
-				unitBytes, err := hex.DecodeString(a.Unit)
-				if err != nil {
-					return nil, err
-				}
-				policyID := cardano.NewPolicyIDFromHash(unitBytes[:28])
-				assetName := string(unitBytes[28:])
-				assetValue, err := strconv.ParseUint(a.Quantity, 10, 64)
-				if err != nil {
-					return nil, err
-				}
-				currentAssets := amount.MultiAsset.Get(policyID)
-				if currentAssets != nil {
-					currentAssets.Set(
-						cardano.NewAssetName(assetName),
-						cardano.BigNum(assetValue),
-					)
-				} else {
-					amount.MultiAsset.Set(
-						policyID,
-						cardano.NewAssets().
-							Set(
-								cardano.NewAssetName(assetName),
-								cardano.BigNum(assetValue),
-							),
-					)
-				}
-			}
-		}
-
 		utxos[i] = cardano.UTxO{
 			Spender: addr,
 			TxHash:  txHash,
-			Amount:  amount,
+			Amount:  cardano.NewValue(0),
 			Index:   uint64(butxo.OutputIndex),
 		}
+		for _, a := range butxo.Amount {
+			if err := addAmount(utxos[i].Amount, a.Unit, a.Quantity); err != nil {
+				return nil, err
+			}
+		}
+		if err := b.setOutputData(ctx, &utxos[i], butxo.DataHash, butxo.InlineDatum, butxo.ReferenceScriptHash, scripts); err != nil {
+			return nil, err
+		}
 	}
 
 	return utxos, nil
 }
 
+// setOutputData sets the datum and reference script of an output. The inline
+// datums are set as Datum, the other datums by their DatumHash. The reference
+// scripts are fetched by hash, once for all the outputs sharing scripts.
+func (b *BlockfrostNode) setOutputData(ctx context.Context, utxo *cardano.UTxO, dataHash, inlineDatum, scriptHash *string, scripts map[string]*cardano.ScriptRef) error {
+	var err error
+	if inlineDatum != nil {
+		if utxo.Datum, err = cardano.NewPlutusData(*inlineDatum); err != nil {
+			return err
+		}
+	} else if dataHash != nil {
+		if utxo.DatumHash, err = cardano.NewHash32(*dataHash); err != nil {
+			return err
+		}
+	}
+
+	if scriptHash == nil {
+		return nil
+	}
+	if scriptRef, ok := scripts[*scriptHash]; ok {
+		utxo.ScriptRef = scriptRef
+		return nil
+	}
+	hash, err := cardano.NewHash28(*scriptHash)
+	if err != nil {
+		return err
+	}
+	if utxo.ScriptRef, err = b.ScriptByHash(ctx, hash); err != nil {
+		return err
+	}
+	scripts[*scriptHash] = utxo.ScriptRef
+	return nil
+}
+
+// apiError returns the error of a request to Blockfrost, the API errors as
+// *cardano.HTTPError.
+func apiError(err error) error {
+	var apiErr *blockfrost.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	// the responses of the API errors share their fields, but for the
+	// unexpected status codes returned as a string
+	httpErr := &cardano.HTTPError{Message: fmt.Sprint(apiErr.Response)}
+	if data, err := json.Marshal(apiErr.Response); err == nil {
+		var resp struct {
+			StatusCode int    `json:"status_code"`
+			Message    string `json:"message"`
+		}
+		if err := json.Unmarshal(data, &resp); err == nil {
+			httpErr.StatusCode, httpErr.Message = resp.StatusCode, resp.Message
+		}
+	}
+	return fmt.Errorf("blockfrost: %w", httpErr)
+}
+
+// isNotFound reports whether err is a 404 response of Blockfrost.
+func isNotFound(err error) bool {
+	var httpErr *cardano.HTTPError
+	return errors.As(apiError(err), &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// addAmount adds the quantity of a unit, lovelace or the hex encoded policy id
+// and asset name of a native asset, to amount.
+func addAmount(amount *cardano.Value, unit, quantity string) error {
+	q, err := parseQuantity(quantity)
+	if err != nil {
+		return err
+	}
+
+	if unit == "lovelace" {
+		if uint64(amount.Coin) > math.MaxUint64-q {
+			return fmt.Errorf("blockfrost: lovelace quantity overflow")
+		}
+		amount.Coin += cardano.Coin(q)
+		return nil
+	}
+
+	unitBytes, err := hex.DecodeString(unit)
+	if err != nil {
+		return err
+	}
+	if len(unitBytes) < 28 {
+		return fmt.Errorf("blockfrost: invalid unit %v", unit)
+	}
+	policyID := cardano.NewPolicyIDFromHash(unitBytes[:28])
+	assetName := cardano.NewAssetName(string(unitBytes[28:]))
+	assets := amount.MultiAsset.Get(policyID)
+	if assets == nil {
+		assets = cardano.NewAssets()
+		amount.MultiAsset.Set(policyID, assets)
+	}
+	current := uint64(assets.Get(assetName))
+	if current > math.MaxUint64-q {
+		return fmt.Errorf("blockfrost: quantity overflow of unit %v", unit)
+	}
+	assets.Set(assetName, cardano.BigNum(current+q))
+	return nil
+}
+
+// parseQuantity parses a decimal quantity of any size, which the ledger bounds
+// to 64 bits in the outputs.
+func parseQuantity(quantity string) (uint64, error) {
+	q, ok := new(big.Int).SetString(quantity, 10)
+	if !ok {
+		return 0, fmt.Errorf("blockfrost: invalid quantity %q", quantity)
+	}
+	if !q.IsUint64() {
+		return 0, fmt.Errorf("blockfrost: quantity %v out of range", q)
+	}
+	return q.Uint64(), nil
+}
+
 func (b *BlockfrostNode) addressUTXOsAll(ctx context.Context, address string, queryParams blockfrost.APIQueryParams) ([]blockfrost.AddressUTXO, error) {
 	result := make([]blockfrost.AddressUTXO, 0, 100)
 
@@ -124,7 +221,7 @@ func (b *BlockfrostNode) addressUTXOsAll(ctx context.Context, address string, qu
 		utxo, err := b.client.AddressUTXOs(ctx, address, query)
 		switch {
 		case err != nil:
-			return nil, err
+			return nil, apiError(err)
 		case len(utxo) == 0:
 			return result, nil
 		}
@@ -138,7 +235,7 @@ func (b *BlockfrostNode) addressUTXOsAll(ctx context.Context, address string, qu
 func (b *BlockfrostNode) Tip(ctx context.Context) (*cardano.NodeTip, error) {
 	block, err := b.client.BlockLatest(ctx)
 	if err != nil {
-		return nil, err
+		return nil, apiError(err)
 	}
 
 	return &cardano.NodeTip{
@@ -148,17 +245,18 @@ func (b *BlockfrostNode) Tip(ctx context.Context) (*cardano.NodeTip, error) {
 	}, nil
 }
 
-func (b *BlockfrostNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
-	url := b.server + "/tx/submit"
-	txBytes := tx.Bytes()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(txBytes))
+// do sends a request to the API outside of the client, for the endpoints it
+// does not support or decodes partially, and returns the response body.
+func (b *BlockfrostNode) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.server+path, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("project_id", b.projectID)
-	req.Header.Add("Content-Type", "application/cbor")
+	if contentType != "" {
+		req.Header.Add("Content-Type", contentType)
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -172,7 +270,21 @@ func (b *BlockfrostNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(string(respBody))
+		httpErr := &cardano.HTTPError{StatusCode: resp.StatusCode, Message: string(respBody)}
+		var berr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &berr) == nil && berr.Message != "" {
+			httpErr.Message = berr.Message
+		}
+		return nil, fmt.Errorf("blockfrost: %w", httpErr)
+	}
+	return respBody, nil
+}
+
+func (b *BlockfrostNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	if _, err := b.do(ctx, http.MethodPost, "/tx/submit", "application/cbor", tx.Bytes()); err != nil {
+		return nil, err
 	}
 
 	txHash, err := tx.Hash()
@@ -183,42 +295,312 @@ func (b *BlockfrostNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano
 	return &txHash, nil
 }
 
+// ProtocolParams returns the protocol parameters of the current epoch. They
+// are decoded from the raw response, the client dropping the cost models and
+// the Conway parameters.
 func (b *BlockfrostNode) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
-	eparams, err := b.client.LatestEpochParameters(ctx)
+	data, err := b.do(ctx, http.MethodGet, "/epochs/latest/parameters", "", nil)
 	if err != nil {
 		return nil, err
 	}
+	return cardano.NewProtocolParamsFromBlockfrostJSON(data)
+}
 
-	minUTXO, err := strconv.ParseUint(eparams.MinUtxo, 10, 64)
+func (b *BlockfrostNode) Network() cardano.Network {
+	return b.network
+}
+
+// UTxOByRef returns the unspent output at index of a transaction, or
+// ErrNotFound if it is unknown or spent.
+func (b *BlockfrostNode) UTxOByRef(ctx context.Context, txHash cardano.Hash32, index uint64) (*cardano.UTxO, error) {
+	butxos, err := b.client.TransactionUTXOs(ctx, txHash.String())
 	if err != nil {
-		return nil, err
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, apiError(err)
 	}
 
-	poolDeposit, err := strconv.ParseUint(eparams.PoolDeposit, 10, 64)
+	for _, output := range butxos.Outputs {
+		if uint64(output.OutputIndex) != index {
+			continue
+		}
+		if output.ConsumedByTx != nil {
+			return nil, ErrNotFound
+		}
+		spender, err := cardano.NewAddress(output.Address)
+		if err != nil {
+			return nil, err
+		}
+		utxo := &cardano.UTxO{
+			Spender: spender,
+			TxHash:  txHash,
+			Amount:  cardano.NewValue(0),
+			Index:   index,
+		}
+		for _, a := range output.Amount {
+			if err := addAmount(utxo.Amount, a.Unit, a.Quantity); err != nil {
+				return nil, err
+			}
+		}
+		err = b.setOutputData(ctx, utxo, output.DataHash, output.InlineDatum, output.ReferenceScriptHash, map[string]*cardano.ScriptRef{})
+		if err != nil {
+			return nil, err
+		}
+		return utxo, nil
+	}
+	return nil, ErrNotFound
+}
+
+// TxStatus returns the confirmation status of a transaction.
+func (b *BlockfrostNode) TxStatus(ctx context.Context, txHash cardano.Hash32) (*cardano.TxStatus, error) {
+	tx, err := b.client.Transaction(ctx, txHash.String())
 	if err != nil {
-		return nil, err
+		if isNotFound(err) {
+			return &cardano.TxStatus{TxHash: txHash}, nil
+		}
+		return nil, apiError(err)
+	}
+	tip, err := b.client.BlockLatest(ctx)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	status := &cardano.TxStatus{
+		TxHash:    txHash,
+		Confirmed: true,
+		Block:     uint64(tx.BlockHeight),
+		Slot:      uint64(tx.Slot),
+	}
+	if tip.Height >= tx.BlockHeight {
+		status.Confirmations = uint64(tip.Height-tx.BlockHeight) + 1
+	}
+	return status, nil
+}
+
+// AddressUsed reports whether an address is in a transaction, using the
+// transaction count of the address.
+func (b *BlockfrostNode) AddressUsed(ctx context.Context, addr cardano.Address) (bool, error) {
+	details, err := b.client.AddressDetails(ctx, addr.Bech32())
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, apiError(err)
+	}
+	return details.TxCount > 0, nil
+}
+
+// AccountInfo returns the state of a stake address. Blockfrost does not report
+// the deposit of the stake address.
+func (b *BlockfrostNode) AccountInfo(ctx context.Context, stakeAddr cardano.Address) (*cardano.AccountInfo, error) {
+	account, err := b.client.Account(ctx, stakeAddr.Bech32())
+	if err != nil {
+		if isNotFound(err) {
+			return &cardano.AccountInfo{StakeAddress: stakeAddr}, nil
+		}
+		return nil, apiError(err)
+	}
+
+	info := &cardano.AccountInfo{StakeAddress: stakeAddr, Registered: account.Active}
+	for _, f := range []struct {
+		coin     *cardano.Coin
+		quantity string
+	}{
+		{&info.TotalBalance, account.ControlledAmount},
+		{&info.Rewards, account.RewardsSum},
+		{&info.Withdrawals, account.WithdrawalsSum},
+		{&info.RewardsAvailable, account.WithdrawableAmount},
+	} {
+		coin, err := strconv.ParseUint(f.quantity, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		*f.coin = cardano.Coin(coin)
+	}
+	if info.TotalBalance > info.RewardsAvailable {
+		info.UTxO = info.TotalBalance - info.RewardsAvailable
+	}
+	if account.PoolID != nil {
+		info.DelegatedPool = *account.PoolID
+	}
+	return info, nil
+}
+
+// DatumByHash returns the datum with the given hash, or ErrNotFound.
+func (b *BlockfrostNode) DatumByHash(ctx context.Context, datumHash cardano.Hash32) (cardano.PlutusData, error) {
+	datum, err := b.client.ScriptDatumCBOR(ctx, datumHash.String())
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, apiError(err)
+	}
+	return cardano.NewPlutusData(datum.CBOR)
+}
+
+var plutusLanguages = map[string]cardano.ScriptHashNamespace{
+	"plutusV1": cardano.PlutusScriptNamespace,
+	"plutusV2": cardano.PlutusV2ScriptNamespace,
+	"plutusV3": cardano.PlutusV3ScriptNamespace,
+}
+
+// ScriptByHash returns the script with the given hash, or ErrNotFound.
+func (b *BlockfrostNode) ScriptByHash(ctx context.Context, scriptHash cardano.Hash28) (*cardano.ScriptRef, error) {
+	script, err := b.client.Script(ctx, scriptHash.String())
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, apiError(err)
+	}
+
+	var scriptRef *cardano.ScriptRef
+	if script.Type == "timelock" {
+		scriptJSON, err := b.client.ScriptJSON(ctx, scriptHash.String())
+		if err != nil {
+			return nil, apiError(err)
+		}
+		data, err := json.Marshal(scriptJSON.JSON)
+		if err != nil {
+			return nil, err
+		}
+		var ns jsonNativeScript
+		if err := json.Unmarshal(data, &ns); err != nil {
+			return nil, err
+		}
+		nativeScript, err := ns.nativeScript()
+		if err != nil {
+			return nil, err
+		}
+		scriptRef = cardano.NewNativeScriptRef(nativeScript)
+	} else {
+		version, ok := plutusLanguages[script.Type]
+		if !ok {
+			return nil, fmt.Errorf("blockfrost: unknown script type %v", script.Type)
+		}
+		scriptCBOR, err := b.client.ScriptCBOR(ctx, scriptHash.String())
+		if err != nil {
+			return nil, apiError(err)
+		}
+		if scriptCBOR.CBOR == nil {
+			return nil, fmt.Errorf("blockfrost: missing cbor of script %v", scriptHash)
+		}
+		plutusScript, err := cardano.NewPlutusScript(*scriptCBOR.CBOR)
+		if err != nil {
+			return nil, err
+		}
+		scriptRef = cardano.NewPlutusScriptRef(version, plutusScript)
 	}
-	keyDeposit, err := strconv.ParseUint(eparams.KeyDeposit, 10, 64)
+
+	hash, err := scriptRef.Hash()
 	if err != nil {
 		return nil, err
 	}
+	if hash.String() != scriptHash.String() {
+		return nil, fmt.Errorf("blockfrost: script hash mismatch: got %v want %v", hash, scriptHash)
+	}
+	return scriptRef, nil
+}
 
-	pparams := &cardano.ProtocolParams{
-		MinFeeA:            cardano.Coin(eparams.MinFeeA),
-		MinFeeB:            cardano.Coin(eparams.MinFeeB),
-		MaxBlockBodySize:   uint(eparams.MaxBlockSize),
-		MaxTxSize:          uint(eparams.MaxTxSize),
-		MaxBlockHeaderSize: uint(eparams.MaxBlockHeaderSize),
-		KeyDeposit:         cardano.Coin(keyDeposit),
-		PoolDeposit:        cardano.Coin(poolDeposit),
-		MaxEpoch:           uint(eparams.Epoch),
-		NOpt:               uint(eparams.NOpt),
-		CoinsPerUTXOWord:   cardano.Coin(minUTXO),
+// jsonNativeScript is a native script in the JSON format of cardano-cli.
+type jsonNativeScript struct {
+	Type     string             `json:"type"`
+	KeyHash  string             `json:"keyHash"`
+	Required uint64             `json:"required"`
+	Slot     uint64             `json:"slot"`
+	Scripts  []jsonNativeScript `json:"scripts"`
+}
+
+func (s *jsonNativeScript) nativeScript() (cardano.NativeScript, error) {
+	var ns cardano.NativeScript
+	switch s.Type {
+	case "sig":
+		keyHash, err := hex.DecodeString(s.KeyHash)
+		if err != nil {
+			return ns, err
+		}
+		ns.Type, ns.KeyHash = cardano.ScriptPubKey, keyHash
+		return ns, nil
+	case "all":
+		ns.Type = cardano.ScriptAll
+	case "any":
+		ns.Type = cardano.ScriptAny
+	case "atLeast":
+		ns.Type, ns.N = cardano.ScriptNofK, s.Required
+	case "after":
+		ns.Type, ns.IntervalValue = cardano.ScriptInvalidBefore, s.Slot
+		return ns, nil
+	case "before":
+		ns.Type, ns.IntervalValue = cardano.ScriptInvalidAfter, s.Slot
+		return ns, nil
+	default:
+		return ns, fmt.Errorf("blockfrost: unknown native script type %v", s.Type)
 	}
+	ns.Scripts = []cardano.NativeScript{}
+	for _, script := range s.Scripts {
+		nativeScript, err := script.nativeScript()
+		if err != nil {
+			return ns, err
+		}
+		ns.Scripts = append(ns.Scripts, nativeScript)
+	}
+	return ns, nil
+}
 
-	return pparams, nil
+var redeemerTags = map[string]cardano.RedeemerTag{
+	"spend":       cardano.RedeemerTagSpend,
+	"mint":        cardano.RedeemerTagMint,
+	"certificate": cardano.RedeemerTagCert,
+	"withdrawal":  cardano.RedeemerTagReward,
 }
 
-func (b *BlockfrostNode) Network() cardano.Network {
-	return b.network
+type evaluationResult struct {
+	EvaluationResult map[string]struct {
+		Memory uint64 `json:"memory"`
+		Steps  uint64 `json:"steps"`
+	} `json:"EvaluationResult"`
+	EvaluationFailure json.RawMessage `json:"EvaluationFailure"`
+}
+
+// EvaluateTx evaluates the scripts of a transaction and returns the execution
+// units of its redeemers. The returned redeemers only have their Tag, Index and
+// ExUnits set.
+func (b *BlockfrostNode) EvaluateTx(ctx context.Context, tx *cardano.Tx) (cardano.Redeemers, error) {
+	resp, err := b.client.TransactionEvaluate(ctx, []byte(tx.Hex()))
+	if err != nil {
+		return nil, apiError(err)
+	}
+	var result evaluationResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, err
+	}
+	if result.EvaluationFailure != nil {
+		return nil, fmt.Errorf("blockfrost: evaluation failure: %s", result.EvaluationFailure)
+	}
+
+	redeemers := make(cardano.Redeemers, 0, len(result.EvaluationResult))
+	for pointer, budget := range result.EvaluationResult {
+		purpose, index, ok := strings.Cut(pointer, ":")
+		tag, known := redeemerTags[purpose]
+		if !ok || !known {
+			return nil, fmt.Errorf("blockfrost: invalid redeemer pointer %v", pointer)
+		}
+		i, err := strconv.ParseUint(index, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("blockfrost: invalid redeemer pointer %v", pointer)
+		}
+		redeemers = append(redeemers, cardano.Redeemer{
+			Tag:     tag,
+			Index:   i,
+			ExUnits: cardano.ExUnits{Mem: budget.Memory, Steps: budget.Steps},
+		})
+	}
+	sort.Slice(redeemers, func(i, j int) bool {
+		if redeemers[i].Tag != redeemers[j].Tag {
+			return redeemers[i].Tag < redeemers[j].Tag
+		}
+		return redeemers[i].Index < redeemers[j].Index
+	})
+	return redeemers, nil
 }
@@ -2,11 +2,20 @@ package blockfrost
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/blockfrost/blockfrost-go"
 	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestUTxOs(t *testing.T) {
@@ -60,3 +69,514 @@ func TestProtocolParams(t *testing.T) {
 	}
 	fmt.Printf("protocolParams: %v\n", protocolParams)
 }
+
+// newTestNode returns a BlockfrostNode querying a server answering the paths
+// with the JSON encoding of the responses, or the responses if handlers, and
+// 404 to the other paths.
+func newTestNode(t *testing.T, responses map[string]any) *BlockfrostNode {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		resp, ok := responses[r.Method+" "+r.URL.Path]
+		if handler, isHandler := resp.(http.Handler); isHandler {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			resp = map[string]any{"status_code": 404, "error": "Not Found", "message": "The requested component has not been found."}
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return &BlockfrostNode{
+		network: cardano.Preprod,
+		server:  server.URL,
+		client:  blockfrost.NewAPIClient(blockfrost.APIClientOptions{Server: server.URL, Client: server.Client()}),
+	}
+}
+
+func TestUTxOByRef(t *testing.T) {
+	const txHash = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+	const addr = "addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8"
+	const policyID = "0b0d621b5c26d0a1fd0893a4b04c19d860296a69ede1fbcfc5179882"
+	consumed := "1111111111111111111111111111111111111111111111111111111111111111"
+	node := newTestNode(t, map[string]any{
+		"GET /txs/" + txHash + "/utxos": map[string]any{
+			"hash": txHash,
+			"outputs": []any{
+				map[string]any{"address": addr, "output_index": 0, "consumed_by_tx": consumed, "amount": []any{
+					map[string]any{"unit": "lovelace", "quantity": "1000000"},
+				}},
+				map[string]any{"address": addr, "output_index": 1, "amount": []any{
+					map[string]any{"unit": "lovelace", "quantity": "2000000"},
+					map[string]any{"unit": policyID + "74657374", "quantity": "42"},
+				}},
+			},
+		},
+	})
+	ctx := context.Background()
+	hash, err := cardano.NewHash32(txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxo, err := node.UTxOByRef(ctx, hash, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := utxo.Spender.Bech32(), addr; got != want {
+		t.Errorf("invalid spender: got %v want %v", got, want)
+	}
+	if got, want := utxo.Amount.Coin, cardano.Coin(2000000); got != want {
+		t.Errorf("invalid coin: got %v want %v", got, want)
+	}
+	policyHash, err := cardano.NewHash28(policyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := utxo.Amount.MultiAsset.Get(cardano.NewPolicyIDFromHash(policyHash))
+	if assets == nil || assets.Get(cardano.NewAssetName("test")) != 42 {
+		t.Errorf("invalid assets: got %v", utxo.Amount.MultiAsset)
+	}
+
+	for _, index := range []uint64{0, 2} {
+		if _, err := node.UTxOByRef(ctx, hash, index); !errors.Is(err, cardano.ErrNotFound) {
+			t.Errorf("invalid error of output %v: got %v want %v", index, err, cardano.ErrNotFound)
+		}
+	}
+	if _, err := node.UTxOByRef(ctx, make(cardano.Hash32, 32), 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestTxStatus(t *testing.T) {
+	const txHash = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+	node := newTestNode(t, map[string]any{
+		"GET /txs/" + txHash: map[string]any{"hash": txHash, "block_height": 100, "slot": 5000},
+		"GET /blocks/latest": map[string]any{"height": 102, "slot": 5060},
+	})
+	ctx := context.Background()
+	hash, err := cardano.NewHash32(txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := node.TxStatus(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &cardano.TxStatus{TxHash: hash, Confirmed: true, Confirmations: 3, Block: 100, Slot: 5000}
+	if diff := cmp.Diff(want, status); diff != "" {
+		t.Errorf("invalid status (-want +got):\n%s", diff)
+	}
+
+	status, err = node.TxStatus(ctx, make(cardano.Hash32, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Confirmed {
+		t.Errorf("unexpected confirmed status: %+v", status)
+	}
+}
+
+func TestAddressUsed(t *testing.T) {
+	used, err := cardano.NewAddress("addr_test1vpu5vlrf4xkxv2qpwngf6cjhtw542ayty80v8dyr49rf5eg57c2qv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unused, err := cardano.NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, map[string]any{
+		"GET /addresses/" + used.Bech32() + "/total": map[string]any{"address": used.Bech32(), "tx_count": 2},
+	})
+
+	for _, tc := range []struct {
+		addr cardano.Address
+		want bool
+	}{
+		{addr: used, want: true},
+		{addr: unused, want: false},
+	} {
+		got, err := node.AddressUsed(context.Background(), tc.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("invalid used %v: got %v want %v", tc.addr.Bech32(), got, tc.want)
+		}
+	}
+}
+
+func TestAccountInfo(t *testing.T) {
+	key := crypto.NewXPrvKeyFromEntropy([]byte("stake"), "")
+	stake, err := cardano.NewKeyCredential(key.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stakeAddr, err := cardano.NewStakeAddress(cardano.Preprod, stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const poolID = "pool1z5uqdk7dzdxaae5633fqfcu2eqzy3a3rgtuvy087fdld7yws0xt"
+	node := newTestNode(t, map[string]any{
+		"GET /accounts/" + stakeAddr.Bech32(): map[string]any{
+			"stake_address":       stakeAddr.Bech32(),
+			"active":              true,
+			"controlled_amount":   "5000000",
+			"rewards_sum":         "300000",
+			"withdrawals_sum":     "100000",
+			"withdrawable_amount": "200000",
+			"pool_id":             poolID,
+		},
+	})
+
+	info, err := node.AccountInfo(context.Background(), stakeAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &cardano.AccountInfo{
+		StakeAddress:     stakeAddr,
+		Registered:       true,
+		DelegatedPool:    poolID,
+		TotalBalance:     5000000,
+		UTxO:             4800000,
+		Rewards:          300000,
+		Withdrawals:      100000,
+		RewardsAvailable: 200000,
+	}
+	if diff := cmp.Diff(want, info); diff != "" {
+		t.Errorf("invalid account info (-want +got):\n%s", diff)
+	}
+
+	unknown, err := cardano.NewStakeAddress(cardano.Preprod, cardano.NewKeyCredentialWithHash(make([]byte, 28)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err = node.AccountInfo(context.Background(), unknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Registered {
+		t.Errorf("unexpected registered account: %+v", info)
+	}
+}
+
+func TestDatumAndScriptByHash(t *testing.T) {
+	datum, err := cardano.NewPlutusData("d8799f182aff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := crypto.NewXPrvKeyFromEntropy([]byte("policy"), "")
+	pubKeyScript, err := cardano.NewScriptPubKey(key.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nativeScript := cardano.NativeScript{
+		Type:    cardano.ScriptAll,
+		Scripts: []cardano.NativeScript{pubKeyScript, {Type: cardano.ScriptInvalidAfter, IntervalValue: 100}},
+	}
+	nativeHash, err := nativeScript.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plutusScript, err := cardano.NewPlutusScript("4e4d01000033222220051200120011")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plutusHash, err := cardano.NewPlutusScriptRef(cardano.PlutusV2ScriptNamespace, plutusScript).Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, map[string]any{
+		"GET /scripts/datum/" + datum.Hash().String() + "/cbor": map[string]any{"cbor": datum.String()},
+		"GET /scripts/" + nativeHash.String():                   map[string]any{"script_hash": nativeHash.String(), "type": "timelock"},
+		"GET /scripts/" + nativeHash.String() + "/json": map[string]any{"json": map[string]any{
+			"type": "all",
+			"scripts": []any{
+				map[string]any{"type": "sig", "keyHash": pubKeyScript.KeyHash.String()},
+				map[string]any{"type": "before", "slot": 100},
+			},
+		}},
+		"GET /scripts/" + plutusHash.String():           map[string]any{"script_hash": plutusHash.String(), "type": "plutusV2"},
+		"GET /scripts/" + plutusHash.String() + "/cbor": map[string]any{"cbor": "4e4d01000033222220051200120011"},
+	})
+	ctx := context.Background()
+
+	gotDatum, err := node.DatumByHash(ctx, datum.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotDatum.String(), datum.String(); got != want {
+		t.Errorf("invalid datum: got %v want %v", got, want)
+	}
+	if _, err := node.DatumByHash(ctx, make(cardano.Hash32, 32)); !errors.Is(err, cardano.ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, cardano.ErrNotFound)
+	}
+
+	for _, want := range []cardano.Hash28{nativeHash, plutusHash} {
+		script, err := node.ScriptByHash(ctx, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := script.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("invalid script hash: got %v want %v", got, want)
+		}
+	}
+	if _, err := node.ScriptByHash(ctx, make(cardano.Hash28, 28)); !errors.Is(err, cardano.ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, cardano.ErrNotFound)
+	}
+}
+
+func TestEvaluateTx(t *testing.T) {
+	node := newTestNode(t, map[string]any{
+		"POST /utils/txs/evaluate": map[string]any{
+			"type": "jsonwsp/response",
+			"result": map[string]any{"EvaluationResult": map[string]any{
+				"spend:1": map[string]any{"memory": 1700, "steps": 476468},
+				"mint:0":  map[string]any{"memory": 2000, "steps": 500000},
+			}},
+		},
+	})
+	redeemers, err := node.EvaluateTx(context.Background(), &cardano.Tx{IsValid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cardano.Redeemers{
+		{Tag: cardano.RedeemerTagSpend, Index: 1, ExUnits: cardano.ExUnits{Mem: 1700, Steps: 476468}},
+		{Tag: cardano.RedeemerTagMint, Index: 0, ExUnits: cardano.ExUnits{Mem: 2000, Steps: 500000}},
+	}
+	if diff := cmp.Diff(want, redeemers); diff != "" {
+		t.Errorf("invalid redeemers (-want +got):\n%s", diff)
+	}
+}
+
+func TestUTxOsDatums(t *testing.T) {
+	const addr = "addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8"
+	const txHash = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+	const policyID = "0b0d621b5c26d0a1fd0893a4b04c19d860296a69ede1fbcfc5179882"
+	datum, err := cardano.NewPlutusData("d8799f182aff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plutusScript, err := cardano.NewPlutusScript("4e4d01000033222220051200120011")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptHash, err := cardano.NewPlutusScriptRef(cardano.PlutusV2ScriptNamespace, plutusScript).Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scriptRequests int
+	node := newTestNode(t, map[string]any{
+		"GET /addresses/" + addr + "/utxos": []any{
+			map[string]any{"address": addr, "tx_hash": txHash, "output_index": 0, "amount": []any{
+				map[string]any{"unit": "lovelace", "quantity": "1000000"},
+				map[string]any{"unit": policyID + "74657374", "quantity": "18446744073709551615"},
+			}},
+			map[string]any{"address": addr, "tx_hash": txHash, "output_index": 1, "data_hash": datum.Hash().String(), "amount": []any{
+				map[string]any{"unit": "lovelace", "quantity": "2000000"},
+			}},
+			map[string]any{"address": addr, "tx_hash": txHash, "output_index": 2,
+				"data_hash":             datum.Hash().String(),
+				"inline_datum":          datum.String(),
+				"reference_script_hash": scriptHash.String(),
+				"amount":                []any{map[string]any{"unit": "lovelace", "quantity": "3000000"}},
+			},
+			map[string]any{"address": addr, "tx_hash": txHash, "output_index": 3,
+				"reference_script_hash": scriptHash.String(),
+				"amount":                []any{map[string]any{"unit": "lovelace", "quantity": "4000000"}},
+			},
+		},
+		"GET /scripts/" + scriptHash.String(): http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scriptRequests++
+			_ = json.NewEncoder(w).Encode(map[string]any{"script_hash": scriptHash.String(), "type": "plutusV2"})
+		}),
+		"GET /scripts/" + scriptHash.String() + "/cbor": map[string]any{"cbor": "4e4d01000033222220051200120011"},
+	})
+	spender, err := cardano.NewAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxos, err := node.UTxOs(context.Background(), spender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 4 {
+		t.Fatalf("invalid utxos: got %v want %v", len(utxos), 4)
+	}
+	policyHash, err := cardano.NewHash28(policyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := utxos[0].Amount.MultiAsset.Get(cardano.NewPolicyIDFromHash(policyHash))
+	if assets == nil || assets.Get(cardano.NewAssetName("test")) != math.MaxUint64 {
+		t.Errorf("invalid assets: got %v", utxos[0].Amount.MultiAsset)
+	}
+	if utxos[0].DatumHash != nil || utxos[0].Datum != nil || utxos[0].ScriptRef != nil {
+		t.Errorf("unexpected datum or script: %+v", utxos[0])
+	}
+	if got, want := utxos[1].DatumHash.String(), datum.Hash().String(); got != want || utxos[1].Datum != nil {
+		t.Errorf("invalid datum hash: got %v want %v", got, want)
+	}
+	if utxos[2].Datum == nil || utxos[2].Datum.String() != datum.String() || utxos[2].DatumHash != nil {
+		t.Errorf("invalid inline datum: got %v want %v", utxos[2].Datum, datum)
+	}
+	for _, utxo := range utxos[2:] {
+		if utxo.ScriptRef == nil {
+			t.Fatalf("missing reference script of output %v", utxo.Index)
+		}
+		got, err := utxo.ScriptRef.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != scriptHash.String() {
+			t.Errorf("invalid reference script hash: got %v want %v", got, scriptHash)
+		}
+	}
+	if scriptRequests != 1 {
+		t.Errorf("invalid script requests: got %v want %v", scriptRequests, 1)
+	}
+}
+
+func TestAddAmount(t *testing.T) {
+	const policyID = "0b0d621b5c26d0a1fd0893a4b04c19d860296a69ede1fbcfc5179882"
+	testcases := []struct {
+		name     string
+		unit     string
+		quantity string
+		wantErr  bool
+	}{
+		{name: "MaxCoin", unit: "lovelace", quantity: "18446744073709551615"},
+		{name: "MaxAsset", unit: policyID + "74657374", quantity: "18446744073709551615"},
+		{name: "Overflow", unit: policyID + "74657374", quantity: "18446744073709551616", wantErr: true},
+		{name: "Negative", unit: "lovelace", quantity: "-1", wantErr: true},
+		{name: "Invalid", unit: "lovelace", quantity: "1e6", wantErr: true},
+		{name: "InvalidUnit", unit: "74657374", quantity: "1", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := addAmount(cardano.NewValue(0), tc.unit, tc.quantity)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("invalid error: got %v want error %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	amount := cardano.NewValue(0)
+	if err := addAmount(amount, policyID+"74657374", "18446744073709551615"); err != nil {
+		t.Fatal(err)
+	}
+	if err := addAmount(amount, policyID+"74657374", "1"); err == nil {
+		t.Errorf("unexpected success of an overflowing sum")
+	}
+}
+
+func TestProtocolParamsFixture(t *testing.T) {
+	node := newTestNode(t, map[string]any{
+		"GET /epochs/latest/parameters": map[string]any{
+			"epoch":                            500,
+			"min_fee_a":                        44,
+			"min_fee_b":                        155381,
+			"max_block_size":                   90112,
+			"max_tx_size":                      16384,
+			"max_block_header_size":            1100,
+			"key_deposit":                      "2000000",
+			"pool_deposit":                     "500000000",
+			"e_max":                            18,
+			"n_opt":                            500,
+			"a0":                               0.3,
+			"rho":                              0.003,
+			"tau":                              0.2,
+			"protocol_major_ver":               10,
+			"protocol_minor_ver":               0,
+			"min_pool_cost":                    "170000000",
+			"cost_models_raw":                  map[string]any{"PlutusV3": []any{100788, 420, 1}},
+			"price_mem":                        0.0577,
+			"price_step":                       0.0000721,
+			"max_tx_ex_mem":                    "14000000",
+			"max_tx_ex_steps":                  "10000000000",
+			"max_block_ex_mem":                 "62000000",
+			"max_block_ex_steps":               "20000000000",
+			"max_val_size":                     "5000",
+			"collateral_percent":               150,
+			"max_collateral_inputs":            3,
+			"coins_per_utxo_size":              "4310",
+			"coins_per_utxo_word":              "4310",
+			"drep_deposit":                     "500000000",
+			"gov_action_deposit":               "100000000000",
+			"gov_action_lifetime":              "6",
+			"min_fee_ref_script_cost_per_byte": 15,
+		},
+	})
+
+	pparams, err := node.ProtocolParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"MinFeeA", pparams.MinFeeA, cardano.Coin(44)},
+		{"KeyDeposit", pparams.KeyDeposit, cardano.Coin(2000000)},
+		{"CoinsPerUTXOByte", pparams.CoinsPerUTXOByte, cardano.Coin(4310)},
+		{"ProtocolVersion", pparams.ProtocolVersion.Major, uint(10)},
+		{"CostModels", len(pparams.CostModels[cardano.PlutusV3]), 3},
+		{"MemPrice", pparams.ExecutionCosts.MemPrice, cardano.Rational{P: 577, Q: 10000}},
+		{"MaxTxExUnits", pparams.MaxTxExUnits, cardano.ExUnits{Mem: 14000000, Steps: 10000000000}},
+		{"MaxValueSize", pparams.MaxValueSize, uint(5000)},
+		{"CollateralPercentage", pparams.CollateralPercentage, uint(150)},
+		{"MaxCollateralInputs", pparams.MaxCollateralInputs, uint(3)},
+		{"DRepDeposit", pparams.DRepDeposit, cardano.Coin(500000000)},
+		{"MinFeeRefScriptCostPerByte", pparams.MinFeeRefScriptCostPerByte, cardano.Rational{P: 15, Q: 1}},
+	}
+	for _, c := range checks {
+		if !cmp.Equal(c.got, c.want) {
+			t.Errorf("invalid %v: got %v want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSubmitTxError(t *testing.T) {
+	node := newTestNode(t, map[string]any{
+		"POST /tx/submit": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status_code": 400, "error": "Bad Request", "message": "transaction submit error"})
+		}),
+	})
+
+	_, err := node.SubmitTx(context.Background(), &cardano.Tx{IsValid: true})
+	var httpErr *cardano.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("invalid error: got %v want %T", err, httpErr)
+	}
+	if httpErr.StatusCode != http.StatusBadRequest || httpErr.Message != "transaction submit error" {
+		t.Errorf("invalid error: got %+v", httpErr)
+	}
+}
+
+func TestEvaluateTxFailure(t *testing.T) {
+	node := newTestNode(t, map[string]any{
+		"POST /utils/txs/evaluate": map[string]any{
+			"type": "jsonwsp/response",
+			"result": map[string]any{"EvaluationFailure": map[string]any{
+				"ScriptFailures": map[string]any{"spend:0": []any{map[string]any{"validatorFailed": map[string]any{}}}},
+			}},
+		},
+	})
+	_, err := node.EvaluateTx(context.Background(), &cardano.Tx{IsValid: true})
+	if err == nil || !strings.Contains(err.Error(), "ScriptFailures") {
+		t.Errorf("invalid error: got %v want an evaluation failure", err)
+	}
+}
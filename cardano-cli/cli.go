@@ -5,19 +5,52 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/cryptogarageinc/cardano-go"
 )
 
+// Options are the options of a CardanoCli.
+type Options struct {
+	// Binary is the path of the cardano-cli binary, looked up in PATH if empty.
+	Binary string
+
+	// SocketPath is the path of the socket of the local node, the
+	// CARDANO_NODE_SOCKET_PATH environment variable if empty.
+	SocketPath string
+
+	// NetworkMagic is the magic of the testnet, e.g. of a local devnet, the
+	// protocol magic of the network if zero.
+	NetworkMagic uint32
+
+	// Era selects the era commands, e.g. cardano-cli conway query tip, and is
+	// the era of the transactions submitted. If zero, the legacy commands are
+	// run and the transactions are submitted in the era of the tip.
+	Era cardano.Era
+}
+
 // CardanoCli implements Node using cardano-cli and a local node.
+//
+// Besides the Node methods, it looks up UTxOs by reference and stake
+// addresses. The other capabilities are not provided, as the local node
+// has no index of the chain and cardano-cli has no command for them:
+//   - TxStatus: query tx-mempool tx-exists only finds pending transactions,
+//     a transaction missing from the mempool may be in a block or dropped.
+//   - DatumByHash and ScriptByHash: datums and scripts are only found in
+//     the UTxOs holding them, not by hash.
+//   - EvaluateTx: transaction build evaluates the scripts of a transaction
+//     it builds itself, not of a given transaction.
 type CardanoCli struct {
-	network cardano.Network
+	network      cardano.Network
+	binary       string
+	socketPath   string
+	networkMagic uint32
+	era          cardano.Era
 }
 
 type tip struct {
@@ -28,121 +61,301 @@ type tip struct {
 	Era   string
 }
 
-type cliTx struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
-	CborHex     string `json:"cborHex"`
-}
+// check interface
+var (
+	_ cardano.Node            = (*CardanoCli)(nil)
+	_ cardano.UTxOByRefNode   = (*CardanoCli)(nil)
+	_ cardano.AccountInfoNode = (*CardanoCli)(nil)
+)
+
+// ErrNotFound is returned when the ledger has no unspent output for a lookup.
+var ErrNotFound = fmt.Errorf("cardano-cli: %w", cardano.ErrNotFound)
 
 // NewNode returns a new instance of CardanoCli.
-func NewNode(network cardano.Network) cardano.Node {
-	return &CardanoCli{network: network}
+func NewNode(network cardano.Network, opts *Options) *CardanoCli {
+	c := &CardanoCli{
+		network:      network,
+		binary:       "cardano-cli",
+		networkMagic: network.ProtocolMagic(),
+	}
+	if opts != nil {
+		if opts.Binary != "" {
+			c.binary = opts.Binary
+		}
+		if opts.NetworkMagic != 0 {
+			c.networkMagic = opts.NetworkMagic
+		}
+		c.socketPath = opts.SocketPath
+		c.era = opts.Era
+	}
+	return c
 }
 
-func (c *CardanoCli) runCommand(args ...string) ([]byte, error) {
-	out := &bytes.Buffer{}
-
-	if c.network == cardano.Mainnet {
+// runCommand runs a query or transaction command of cardano-cli on the network
+// and returns its output. The errors hold the error output of the command.
+func (c *CardanoCli) runCommand(ctx context.Context, args ...string) ([]byte, error) {
+	if c.era != cardano.ByronEra {
+		args = append([]string{strings.ToLower(c.era.String())}, args...)
+	}
+	if c.network == cardano.Mainnet && c.networkMagic == cardano.Mainnet.ProtocolMagic() {
 		args = append(args, "--mainnet")
 	} else {
-		args = append(args, "--testnet-magic", strconv.Itoa(cardano.ProtocolMagic))
+		args = append(args, "--testnet-magic", strconv.FormatUint(uint64(c.networkMagic), 10))
 	}
 
-	cmd := exec.Command("cardano-cli", args...)
-	cmd.Stdout = out
-	cmd.Stderr = os.Stderr
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, c.binary, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if c.socketPath != "" {
+		cmd.Env = append(os.Environ(), "CARDANO_NODE_SOCKET_PATH="+c.socketPath)
+	}
 	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("cardano-cli: %w: %v", err, msg)
+		}
+		return nil, fmt.Errorf("cardano-cli: %w", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (c *CardanoCli) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	txOuts, err := c.queryUTxOs(ctx, "--address", addr.Bech32())
+	if err != nil {
 		return nil, err
 	}
 
-	return out.Bytes(), nil
+	utxos := make([]cardano.UTxO, 0, len(txOuts))
+	for txIn, txOut := range txOuts {
+		hash, index, ok := strings.Cut(txIn, "#")
+		if !ok {
+			return nil, fmt.Errorf("cardano-cli: invalid transaction input %v", txIn)
+		}
+		txHash, err := cardano.NewHash32(hash)
+		if err != nil {
+			return nil, err
+		}
+		i, err := strconv.ParseUint(index, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cardano-cli: invalid transaction input %v", txIn)
+		}
+		utxo, err := txOut.utxo(txHash, i)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, *utxo)
+	}
+	sort.Slice(utxos, func(i, j int) bool {
+		if cmp := bytes.Compare(utxos[i].TxHash, utxos[j].TxHash); cmp != 0 {
+			return cmp < 0
+		}
+		return utxos[i].Index < utxos[j].Index
+	})
+
+	return utxos, nil
+}
+
+// cliScript is a reference script in the JSON output of query utxo, in a text
+// envelope.
+type cliScript struct {
+	Script cardano.TextEnvelope `json:"script"`
+}
+
+// cliTxOut is a transaction output in the JSON output of query utxo.
+type cliTxOut struct {
+	Address         string                     `json:"address"`
+	Value           map[string]json.RawMessage `json:"value"`
+	DatumHash       *string                    `json:"datumhash"`
+	InlineDatum     json.RawMessage            `json:"inlineDatum"`
+	InlineDatumRaw  *string                    `json:"inlineDatumRaw"`
+	ReferenceScript *cliScript                 `json:"referenceScript"`
 }
 
-func (c *CardanoCli) UTxOs(_ context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
-	out, err := c.runCommand("query", "utxo", "--address", addr.Bech32())
+// queryUTxOs runs query utxo with the JSON output written to a temporary
+// file, and returns the outputs by transaction input, txhash#index.
+func (c *CardanoCli) queryUTxOs(ctx context.Context, args ...string) (map[string]cliTxOut, error) {
+	outFile, err := os.CreateTemp(os.TempDir(), "utxo_")
 	if err != nil {
 		return nil, err
 	}
+	_ = outFile.Close()
+	defer func() { _ = os.Remove(outFile.Name()) }()
 
-	utxos := []cardano.UTxO{}
-	lines := strings.Split(string(out), "\n")
+	args = append([]string{"query", "utxo", "--out-file", outFile.Name()}, args...)
+	if _, err := c.runCommand(ctx, args...); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	var txOuts map[string]cliTxOut
+	if err := json.Unmarshal(data, &txOuts); err != nil {
+		return nil, err
+	}
+	return txOuts, nil
+}
 
-	if len(lines) < 3 {
-		return utxos, nil
+// utxo returns the unspent output at index of a transaction. The inline
+// datums are read from their CBOR encoding, output by cardano-cli 8.20 and
+// later.
+func (o *cliTxOut) utxo(txHash cardano.Hash32, index uint64) (*cardano.UTxO, error) {
+	spender, err := cardano.NewAddress(o.Address)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := newValue(o.Value)
+	if err != nil {
+		return nil, err
+	}
+	utxo := &cardano.UTxO{
+		Spender: spender,
+		TxHash:  txHash,
+		Index:   index,
+		Amount:  amount,
 	}
 
-	for _, line := range lines[2 : len(lines)-1] {
-		amount := cardano.NewValue(0)
-		args := strings.Fields(line)
-		if len(args) < 4 {
-			return nil, fmt.Errorf("malformed cli response")
+	switch {
+	case o.InlineDatumRaw != nil:
+		if utxo.Datum, err = cardano.NewPlutusData(*o.InlineDatumRaw); err != nil {
+			return nil, err
 		}
-		txHash, err := cardano.NewHash32(args[0])
-		if err != nil {
+	case len(o.InlineDatum) != 0 && string(o.InlineDatum) != "null":
+		return nil, fmt.Errorf("cardano-cli: missing inlineDatumRaw of %v#%v, cardano-cli 8.20 or later is required", txHash, index)
+	case o.DatumHash != nil:
+		if utxo.DatumHash, err = cardano.NewHash32(*o.DatumHash); err != nil {
 			return nil, err
 		}
-		index, err := strconv.Atoi(args[1])
-		if err != nil {
+	}
+
+	if o.ReferenceScript != nil {
+		if utxo.ScriptRef, err = scriptRef(&o.ReferenceScript.Script); err != nil {
 			return nil, err
 		}
-		lovelace, err := strconv.Atoi(args[2])
+	}
+	return utxo, nil
+}
+
+var plutusLanguages = map[string]cardano.ScriptHashNamespace{
+	"PlutusScriptV1": cardano.PlutusScriptNamespace,
+	"PlutusScriptV2": cardano.PlutusV2ScriptNamespace,
+	"PlutusScriptV3": cardano.PlutusV3ScriptNamespace,
+}
+
+// scriptRef returns the reference script of a script text envelope.
+func scriptRef(te *cardano.TextEnvelope) (*cardano.ScriptRef, error) {
+	if te.Type == "SimpleScript" || te.Type == "SimpleScriptV1" || te.Type == "SimpleScriptV2" {
+		data, err := hex.DecodeString(te.CborHex)
 		if err != nil {
 			return nil, err
 		}
-		amount.Coin = cardano.Coin(lovelace)
+		var ns cardano.NativeScript
+		if err := ns.UnmarshalCBOR(data); err != nil {
+			return nil, err
+		}
+		return cardano.NewNativeScriptRef(ns), nil
+	}
 
-		assets := strings.Split(line, "+")
-		for _, asset := range assets[1 : len(assets)-1] {
-			args := strings.Fields(asset)
-			quantity := args[0]
-			unit := strings.ReplaceAll(args[1], ".", "")
-			unitBytes, err := hex.DecodeString(unit)
-			if err != nil {
+	version, ok := plutusLanguages[te.Type]
+	if !ok {
+		return nil, fmt.Errorf("cardano-cli: unknown script type %v", te.Type)
+	}
+	script, err := cardano.NewPlutusScript(te.CborHex)
+	if err != nil {
+		return nil, err
+	}
+	return cardano.NewPlutusScriptRef(version, script), nil
+}
+
+// newValue returns the value of a transaction output, lovelace and the
+// quantities of the native assets by policy id and hex encoded asset name.
+func newValue(value map[string]json.RawMessage) (*cardano.Value, error) {
+	amount := cardano.NewValue(0)
+	for unit, data := range value {
+		if unit == "lovelace" {
+			if err := json.Unmarshal(data, &amount.Coin); err != nil {
 				return nil, err
 			}
-			policyID := cardano.NewPolicyIDFromHash(unitBytes[:28])
-			assetName := string(unitBytes[28:])
-			assetValue, err := strconv.ParseUint(quantity, 10, 64)
+			continue
+		}
+		policyHash, err := hex.DecodeString(unit)
+		if err != nil {
+			return nil, err
+		}
+		var quantities map[string]uint64
+		if err := json.Unmarshal(data, &quantities); err != nil {
+			return nil, err
+		}
+		assets := cardano.NewAssets()
+		for name, quantity := range quantities {
+			assetName, err := hex.DecodeString(name)
 			if err != nil {
 				return nil, err
 			}
-			currentAssets := amount.MultiAsset.Get(policyID)
-			if currentAssets != nil {
-				currentAssets.Set(
-					cardano.NewAssetName(assetName),
-					cardano.BigNum(assetValue),
-				)
-			} else {
-				amount.MultiAsset.Set(
-					policyID,
-					cardano.NewAssets().
-						Set(
-							cardano.NewAssetName(assetName),
-							cardano.BigNum(assetValue),
-						),
-				)
-			}
+			assets.Set(cardano.NewAssetName(string(assetName)), cardano.BigNum(quantity))
 		}
+		amount.MultiAsset.Set(cardano.NewPolicyIDFromHash(policyHash), assets)
+	}
+	return amount, nil
+}
 
-		utxos = append(utxos, cardano.UTxO{
-			Spender: addr,
-			TxHash:  txHash,
-			Index:   uint64(index),
-			Amount:  amount,
-		})
+// UTxOByRef returns the unspent output at index of a transaction, or
+// ErrNotFound if it is unknown or spent.
+func (c *CardanoCli) UTxOByRef(ctx context.Context, txHash cardano.Hash32, index uint64) (*cardano.UTxO, error) {
+	txIn := fmt.Sprintf("%v#%v", txHash, index)
+	txOuts, err := c.queryUTxOs(ctx, "--tx-in", txIn)
+	if err != nil {
+		return nil, err
 	}
+	txOut, ok := txOuts[txIn]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return txOut.utxo(txHash, index)
+}
 
-	return utxos, nil
+// cliStakeAddressInfo is the state of a stake address in the output of query
+// stake-address-info, the delegation was renamed stakeDelegation in cardano-cli 9.
+type cliStakeAddressInfo struct {
+	Address              string       `json:"address"`
+	Delegation           *string      `json:"delegation"`
+	StakeDelegation      *string      `json:"stakeDelegation"`
+	DelegationDeposit    cardano.Coin `json:"delegationDeposit"`
+	RewardAccountBalance cardano.Coin `json:"rewardAccountBalance"`
 }
 
-func (c *CardanoCli) Tip(_ context.Context) (*cardano.NodeTip, error) {
-	out, err := c.runCommand("query", "tip")
+// AccountInfo returns the state of a stake address. The node only tracks the
+// registration, delegation, deposit and rewards available of stake addresses.
+func (c *CardanoCli) AccountInfo(ctx context.Context, stakeAddr cardano.Address) (*cardano.AccountInfo, error) {
+	out, err := c.runCommand(ctx, "query", "stake-address-info", "--address", stakeAddr.Bech32())
 	if err != nil {
 		return nil, err
 	}
+	var infos []cliStakeAddressInfo
+	if err := json.Unmarshal(out, &infos); err != nil {
+		return nil, err
+	}
 
-	cliTip := &tip{}
-	if err = json.Unmarshal(out, cliTip); err != nil {
+	info := &cardano.AccountInfo{StakeAddress: stakeAddr}
+	if len(infos) == 0 {
+		return info, nil
+	}
+	info.Registered = true
+	info.Deposit = infos[0].DelegationDeposit
+	info.RewardsAvailable = infos[0].RewardAccountBalance
+	switch {
+	case infos[0].StakeDelegation != nil:
+		info.DelegatedPool = *infos[0].StakeDelegation
+	case infos[0].Delegation != nil:
+		info.DelegatedPool = *infos[0].Delegation
+	}
+	return info, nil
+}
+
+func (c *CardanoCli) Tip(ctx context.Context) (*cardano.NodeTip, error) {
+	cliTip, err := c.tip(ctx)
+	if err != nil {
 		return nil, err
 	}
 
@@ -153,25 +366,46 @@ func (c *CardanoCli) Tip(_ context.Context) (*cardano.NodeTip, error) {
 	}, nil
 }
 
-func (c *CardanoCli) SubmitTx(_ context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
-	txOut := cliTx{
-		Type:    "Witnessed Tx AlonzoEra",
-		CborHex: tx.Hex(),
+func (c *CardanoCli) tip(ctx context.Context) (*tip, error) {
+	out, err := c.runCommand(ctx, "query", "tip")
+	if err != nil {
+		return nil, err
+	}
+
+	cliTip := &tip{}
+	if err = json.Unmarshal(out, cliTip); err != nil {
+		return nil, err
+	}
+	return cliTip, nil
+}
+
+// SubmitTx submits a transaction in the era of the options, or else in the
+// era of the tip.
+func (c *CardanoCli) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	era := c.era
+	if era == cardano.ByronEra {
+		cliTip, err := c.tip(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if era, err = parseEra(cliTip.Era); err != nil {
+			return nil, err
+		}
 	}
 
 	txFile, err := os.CreateTemp(os.TempDir(), "tx_")
 	if err != nil {
 		return nil, err
 	}
+	_ = txFile.Close()
 	defer func() { _ = os.Remove(txFile.Name()) }()
 
-	if err := json.NewEncoder(txFile).Encode(txOut); err != nil {
+	if err := cardano.NewTxTextEnvelope(tx, era).WriteFile(txFile.Name()); err != nil {
 		return nil, err
 	}
 
-	out, err := c.runCommand("transaction", "submit", "--tx-file", txFile.Name())
-	if err != nil {
-		return nil, errors.New(string(out))
+	if _, err := c.runCommand(ctx, "transaction", "submit", "--tx-file", txFile.Name()); err != nil {
+		return nil, err
 	}
 
 	txHash, err := tx.Hash()
@@ -182,30 +416,22 @@ func (c *CardanoCli) SubmitTx(_ context.Context, tx *cardano.Tx) (*cardano.Hash3
 	return &txHash, nil
 }
 
-type protocolParameters struct {
-	MinFeeA          cardano.Coin `json:"txFeePerByte"`
-	MinFeeB          cardano.Coin `json:"txFeeFixed"`
-	CoinsPerUTXOWord cardano.Coin `json:"utxoCostPerWord"`
+// parseEra returns the era named in the output of query tip.
+func parseEra(name string) (cardano.Era, error) {
+	for era := cardano.ShelleyEra; era <= cardano.ConwayEra; era++ {
+		if strings.EqualFold(name, era.String()) {
+			return era, nil
+		}
+	}
+	return 0, fmt.Errorf("cardano-cli: unknown era %q", name)
 }
 
-func (c *CardanoCli) ProtocolParams(_ context.Context) (*cardano.ProtocolParams, error) {
-	out, err := c.runCommand("query", "protocol-parameters")
+func (c *CardanoCli) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
+	out, err := c.runCommand(ctx, "query", "protocol-parameters")
 	if err != nil {
-		return nil, errors.New(string(out))
-	}
-
-	var cparams protocolParameters
-	if err := json.Unmarshal(out, &cparams); err != nil {
 		return nil, err
 	}
-
-	pparams := &cardano.ProtocolParams{
-		MinFeeA:          cparams.MinFeeA,
-		MinFeeB:          cparams.MinFeeB,
-		CoinsPerUTXOWord: cparams.CoinsPerUTXOWord,
-	}
-
-	return pparams, nil
+	return cardano.NewProtocolParamsFromCardanoCLIJSON(out)
 }
 
 func (c *CardanoCli) Network() cardano.Network {
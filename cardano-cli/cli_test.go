@@ -0,0 +1,306 @@
+package cardanocli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeCommand is the scripted output of a cardano-cli command, written to the
+// --out-file if any, or else to the standard output. A failed command writes
+// its output to the standard error.
+type fakeCommand struct {
+	out  string
+	fail bool
+}
+
+// fakeCLI is a fake cardano-cli script answering the commands, e.g. "query
+// tip", and recording their arguments and environment.
+type fakeCLI struct {
+	dir string
+}
+
+func newFakeCLI(t *testing.T, commands map[string]fakeCommand) *fakeCLI {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping shell script fake on windows")
+	}
+	f := &fakeCLI{dir: t.TempDir()}
+
+	script := &strings.Builder{}
+	fmt.Fprintf(script, "#!/bin/sh\ndir=%q\n", f.dir)
+	script.WriteString(`echo "$*" >> "$dir/args"
+echo "$CARDANO_NODE_SOCKET_PATH" > "$dir/socket"
+out=""
+prev=""
+for arg in "$@"; do
+	[ "$prev" = "--out-file" ] && out="$arg"
+	[ "$prev" = "--tx-file" ] && cp "$arg" "$dir/tx-file"
+	prev="$arg"
+done
+case "$*" in
+`)
+	i := 0
+	for command, c := range commands {
+		file := filepath.Join(f.dir, fmt.Sprintf("out%d", i))
+		if err := os.WriteFile(file, []byte(c.out), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if c.fail {
+			fmt.Fprintf(script, "*%q*) cat %q >&2; exit 1 ;;\n", command, file)
+		} else {
+			fmt.Fprintf(script, "*%q*) if [ -n \"$out\" ]; then cat %q > \"$out\"; else cat %q; fi ;;\n", command, file, file)
+		}
+		i++
+	}
+	script.WriteString("*) echo \"unknown command $*\" >&2; exit 1 ;;\nesac\n")
+
+	if err := os.WriteFile(f.binary(), []byte(script.String()), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *fakeCLI) binary() string {
+	return filepath.Join(f.dir, "cardano-cli")
+}
+
+// args returns the arguments of the commands run.
+func (f *fakeCLI) args(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(f.dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func (f *fakeCLI) file(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(f.dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestRunCommandOptions(t *testing.T) {
+	ctx := context.Background()
+	tipJSON := `{"block": 10, "epoch": 2, "era": "Conway", "slot": 300}`
+
+	testcases := []struct {
+		name       string
+		network    cardano.Network
+		opts       *Options
+		wantArgs   string
+		wantSocket string
+	}{
+		{
+			name:     "Mainnet",
+			network:  cardano.Mainnet,
+			wantArgs: "query tip --mainnet",
+		},
+		{
+			name:     "Preprod",
+			network:  cardano.Preprod,
+			wantArgs: "query tip --testnet-magic 1",
+		},
+		{
+			name:       "Devnet",
+			network:    cardano.Testnet,
+			opts:       &Options{NetworkMagic: 42, SocketPath: "/tmp/node.socket", Era: cardano.ConwayEra},
+			wantArgs:   "conway query tip --testnet-magic 42",
+			wantSocket: "/tmp/node.socket",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeCLI(t, map[string]fakeCommand{"query tip": {out: tipJSON}})
+			opts := &Options{}
+			if tc.opts != nil {
+				*opts = *tc.opts
+			}
+			opts.Binary = fake.binary()
+			t.Setenv("CARDANO_NODE_SOCKET_PATH", "")
+
+			tip, err := NewNode(tc.network, opts).Tip(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (&cardano.NodeTip{Block: 10, Epoch: 2, Slot: 300}); !cmp.Equal(tip, want) {
+				t.Errorf("invalid tip: got %+v want %+v", tip, want)
+			}
+			if got := fake.args(t); len(got) != 1 || got[0] != tc.wantArgs {
+				t.Errorf("invalid args: got %q want %q", got, tc.wantArgs)
+			}
+			if got := fake.file(t, "socket"); got != tc.wantSocket {
+				t.Errorf("invalid socket path: got %q want %q", got, tc.wantSocket)
+			}
+		})
+	}
+}
+
+func TestUTxOs(t *testing.T) {
+	const addr = "addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8"
+	const txHash = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+	const policyID = "0b0d621b5c26d0a1fd0893a4b04c19d860296a69ede1fbcfc5179882"
+	datum, err := cardano.NewPlutusData("d8799f182aff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txOuts := map[string]any{
+		txHash + "#1": map[string]any{
+			"address":   addr,
+			"datumhash": datum.Hash().String(),
+			"value":     map[string]any{"lovelace": 2000000},
+		},
+		txHash + "#0": map[string]any{
+			"address": addr,
+			"value": map[string]any{
+				"lovelace": 1000000,
+				policyID:   map[string]any{"74657374": 42},
+			},
+		},
+		txHash + "#2": map[string]any{
+			"address":         addr,
+			"inlineDatum":     map[string]any{"constructor": 0, "fields": []any{map[string]any{"int": 42}}},
+			"inlineDatumhash": datum.Hash().String(),
+			"inlineDatumRaw":  datum.String(),
+			"referenceScript": map[string]any{
+				"script": map[string]any{"type": "PlutusScriptV2", "description": "", "cborHex": "4e4d01000033222220051200120011"},
+			},
+			"value": map[string]any{"lovelace": 3000000},
+		},
+	}
+	data, err := json.Marshal(txOuts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeCLI(t, map[string]fakeCommand{"query utxo": {out: string(data)}})
+	node := NewNode(cardano.Preprod, &Options{Binary: fake.binary()})
+	spender, err := cardano.NewAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxos, err := node.UTxOs(context.Background(), spender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 3 {
+		t.Fatalf("invalid utxos: got %v want %v", len(utxos), 3)
+	}
+	for i, utxo := range utxos {
+		if utxo.Index != uint64(i) || utxo.TxHash.String() != txHash {
+			t.Errorf("invalid utxo %v: got %v#%v", i, utxo.TxHash, utxo.Index)
+		}
+	}
+	policyHash, err := cardano.NewHash28(policyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := utxos[0].Amount.MultiAsset.Get(cardano.NewPolicyIDFromHash(policyHash))
+	if utxos[0].Amount.Coin != 1000000 || assets == nil || assets.Get(cardano.NewAssetName("test")) != 42 {
+		t.Errorf("invalid amount: got %v", utxos[0].Amount)
+	}
+	if got, want := utxos[1].DatumHash.String(), datum.Hash().String(); got != want {
+		t.Errorf("invalid datum hash: got %v want %v", got, want)
+	}
+	if utxos[2].Datum.String() != datum.String() || utxos[2].DatumHash != nil {
+		t.Errorf("invalid inline datum: got %v want %v", utxos[2].Datum, datum)
+	}
+	if utxos[2].ScriptRef == nil || utxos[2].ScriptRef.Type != cardano.PlutusV2ScriptNamespace {
+		t.Errorf("invalid reference script: got %+v", utxos[2].ScriptRef)
+	}
+	if args := fake.args(t); !strings.HasPrefix(args[0], "query utxo --out-file ") || !strings.Contains(args[0], "--address "+addr) {
+		t.Errorf("invalid args: got %q", args)
+	}
+}
+
+func TestSubmitTx(t *testing.T) {
+	tx := &cardano.Tx{IsValid: true}
+
+	t.Run("TipEra", func(t *testing.T) {
+		fake := newFakeCLI(t, map[string]fakeCommand{
+			"query tip":          {out: `{"block": 10, "epoch": 2, "era": "Babbage", "slot": 300}`},
+			"transaction submit": {out: "Transaction successfully submitted."},
+		})
+		if _, err := NewNode(cardano.Preprod, &Options{Binary: fake.binary()}).SubmitTx(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+		var te cardano.TextEnvelope
+		if err := json.Unmarshal([]byte(fake.file(t, "tx-file")), &te); err != nil {
+			t.Fatal(err)
+		}
+		if want := "Unwitnessed Tx BabbageEra"; te.Type != want || te.CborHex != tx.Hex() {
+			t.Errorf("invalid tx file: got %+v want type %v", te, want)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		fake := newFakeCLI(t, map[string]fakeCommand{
+			"transaction submit": {out: "Command failed: transaction submit  Error: BadInputsUTxO", fail: true},
+		})
+		node := NewNode(cardano.Preprod, &Options{Binary: fake.binary(), Era: cardano.ConwayEra})
+		_, err := node.SubmitTx(context.Background(), tx)
+		if err == nil || !strings.Contains(err.Error(), "BadInputsUTxO") {
+			t.Errorf("invalid error: got %v want the error output", err)
+		}
+		if args := fake.args(t); len(args) != 1 || !strings.HasPrefix(args[0], "conway transaction submit") {
+			t.Errorf("invalid args: got %q", args)
+		}
+	})
+}
+
+func TestProtocolParams(t *testing.T) {
+	fake := newFakeCLI(t, map[string]fakeCommand{"query protocol-parameters": {out: `{
+		"txFeePerByte": 44,
+		"txFeeFixed": 155381,
+		"maxTxSize": 16384,
+		"stakeAddressDeposit": 2000000,
+		"utxoCostPerByte": 4310,
+		"protocolVersion": {"major": 10, "minor": 0},
+		"costModels": {"PlutusV3": [100788, 420, 1]},
+		"executionUnitPrices": {"priceMemory": 0.0577, "priceSteps": 0.0000721},
+		"maxTxExecutionUnits": {"memory": 14000000, "steps": 10000000000},
+		"collateralPercentage": 150,
+		"maxCollateralInputs": 3,
+		"dRepDeposit": 500000000,
+		"minFeeRefScriptCostPerByte": 15
+	}`}})
+	node := NewNode(cardano.Preprod, &Options{Binary: fake.binary()})
+
+	pparams, err := node.ProtocolParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"MinFeeA", pparams.MinFeeA, cardano.Coin(44)},
+		{"MinFeeB", pparams.MinFeeB, cardano.Coin(155381)},
+		{"KeyDeposit", pparams.KeyDeposit, cardano.Coin(2000000)},
+		{"CoinsPerUTXOByte", pparams.CoinsPerUTXOByte, cardano.Coin(4310)},
+		{"Era", pparams.Era(), cardano.ConwayEra},
+		{"CostModels", len(pparams.CostModels[cardano.PlutusV3]), 3},
+		{"MemPrice", pparams.ExecutionCosts.MemPrice, cardano.Rational{P: 577, Q: 10000}},
+		{"MaxTxExUnits", pparams.MaxTxExUnits, cardano.ExUnits{Mem: 14000000, Steps: 10000000000}},
+		{"CollateralPercentage", pparams.CollateralPercentage, uint(150)},
+		{"DRepDeposit", pparams.DRepDeposit, cardano.Coin(500000000)},
+	}
+	for _, c := range checks {
+		if !cmp.Equal(c.got, c.want) {
+			t.Errorf("invalid %v: got %v want %v", c.name, c.got, c.want)
+		}
+	}
+}
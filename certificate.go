@@ -89,6 +89,24 @@ type Certificate struct {
 	GenesisDelegateHash Hash28
 }
 
+// witnessCredentials returns the credentials that must witness the certificate.
+func (c *Certificate) witnessCredentials() []StakeCredential {
+	switch c.Type {
+	case StakeDeregistration, StakeDelegation:
+		return []StakeCredential{c.StakeCredential}
+	case PoolRegistration:
+		creds := []StakeCredential{NewKeyCredentialWithHash(c.Operator)}
+		for _, owner := range c.Owners {
+			creds = append(creds, NewKeyCredentialWithHash(owner))
+		}
+		return creds
+	case PoolRetirement:
+		return []StakeCredential{NewKeyCredentialWithHash(c.PoolKeyHash)}
+	default:
+		return nil
+	}
+}
+
 // MarshalCBOR implements cbor.Marshaler.
 func (c *Certificate) MarshalCBOR() ([]byte, error) {
 	var cert any
@@ -0,0 +1,171 @@
+// Package chainsync follows the chain of a node, delivering the blocks rolled
+// forward and the rollbacks to a Handler.
+//
+// The chain is read from a Source, such as the node-to-client ChainSync
+// mini-protocol of the ouroboros package or the Ogmios chain synchronization
+// of the ogmios package. The points of the recent blocks are persisted in a
+// Store so that a restarted Follower resumes where it left off.
+package chainsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// ErrNoIntersection is returned when none of the points to start from are on
+// the chain of the node.
+var ErrNoIntersection = errors.New("chainsync: no intersection found")
+
+// Tip is the tip of the chain of the node.
+type Tip struct {
+	Point cardano.Point
+	Block uint64
+}
+
+// Direction is the direction of a chain event.
+type Direction int
+
+const (
+	RollForward Direction = iota
+	RollBackward
+)
+
+// Event is a change of the chain followed: a block rolled forward or a
+// rollback to a point.
+type Event struct {
+	Direction Direction
+	Block     *cardano.Block // rolled forward
+	Point     cardano.Point  // rolled back to
+	Tip       Tip
+}
+
+// Source is a chain-sync client of a node.
+type Source interface {
+	// FindIntersect finds the most recent of the points on the chain of the
+	// node and follows the chain from there, the first event is a rollback to
+	// the intersection. It returns ErrNoIntersection if no point is on the chain.
+	FindIntersect(ctx context.Context, points []cardano.Point) (cardano.Point, Tip, error)
+
+	// Next returns the next event, waiting for a new block at the tip of the chain.
+	Next(ctx context.Context) (*Event, error)
+}
+
+// Handler handles the events of the chain followed.
+type Handler interface {
+	// RollForward handles a block added to the chain.
+	RollForward(ctx context.Context, block *cardano.Block, tip Tip) error
+
+	// RollBackward handles a rollback, the blocks after the point are no
+	// longer on the chain.
+	RollBackward(ctx context.Context, point cardano.Point, tip Tip) error
+}
+
+// Options are the options of a Follower.
+type Options struct {
+	// Start are the points to start from when the store is empty, most recent
+	// first. The chain is followed from the origin if nil.
+	Start []cardano.Point
+
+	// Points is the number of recent points persisted, 100 if zero. A rollback
+	// deeper than the points persisted prevents a restart.
+	Points int
+}
+
+const defaultPoints = 100
+
+// Follower follows the chain of a Source.
+type Follower struct {
+	source  Source
+	handler Handler
+	store   Store
+	start   []cardano.Point
+	max     int
+
+	// points are the points of the recent blocks handled, most recent first.
+	points []cardano.Point
+}
+
+// NewFollower returns a new Follower delivering the events of source to
+// handler, with the recent points persisted in store.
+func NewFollower(source Source, handler Handler, store Store, opts *Options) *Follower {
+	f := &Follower{
+		source:  source,
+		handler: handler,
+		store:   store,
+		start:   []cardano.Point{{}},
+		max:     defaultPoints,
+	}
+	if opts != nil {
+		if len(opts.Start) > 0 {
+			f.start = opts.Start
+		}
+		if opts.Points > 0 {
+			f.max = opts.Points
+		}
+	}
+	return f
+}
+
+// Run follows the chain until the context is done or an error occurs. The
+// chain is resumed from the points persisted, and from the start points if
+// there are none. The points are persisted once the handler returns.
+func (f *Follower) Run(ctx context.Context) error {
+	points, err := f.store.Load()
+	if err != nil {
+		return fmt.Errorf("chainsync: load points: %w", err)
+	}
+	if len(points) == 0 {
+		points = f.start
+	}
+
+	intersection, _, err := f.source.FindIntersect(ctx, points)
+	if err != nil {
+		return err
+	}
+	f.points = points
+	f.rollback(intersection)
+
+	for {
+		event, err := f.source.Next(ctx)
+		if err != nil {
+			return err
+		}
+		switch event.Direction {
+		case RollForward:
+			if err := f.handler.RollForward(ctx, event.Block, event.Tip); err != nil {
+				return err
+			}
+			f.points = append([]cardano.Point{event.Block.Point()}, f.points...)
+			if len(f.points) > f.max {
+				f.points = f.points[:f.max]
+			}
+		case RollBackward:
+			if err := f.handler.RollBackward(ctx, event.Point, event.Tip); err != nil {
+				return err
+			}
+			f.rollback(event.Point)
+		default:
+			return fmt.Errorf("chainsync: unknown event direction %v", event.Direction)
+		}
+		if err := f.store.Save(f.points); err != nil {
+			return fmt.Errorf("chainsync: save points: %w", err)
+		}
+	}
+}
+
+// rollback drops the points after the point, which becomes the most recent.
+func (f *Follower) rollback(point cardano.Point) {
+	points := []cardano.Point{point}
+	for _, p := range f.points {
+		if p.Slot < point.Slot && !p.IsOrigin() {
+			points = append(points, p)
+		}
+	}
+	if len(points) > f.max {
+		points = points[:f.max]
+	}
+	f.points = points
+}
@@ -0,0 +1,162 @@
+package chainsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+func testPoint(t *testing.T, slot uint64) cardano.Point {
+	t.Helper()
+	point, err := cardano.NewPoint(slot, fmt.Sprintf("%064x", slot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return point
+}
+
+func forward(t *testing.T, slot uint64) *Event {
+	point := testPoint(t, slot)
+	return &Event{Direction: RollForward, Block: &cardano.Block{Hash: point.Hash, Slot: slot}}
+}
+
+func backward(t *testing.T, slot uint64) *Event {
+	return &Event{Direction: RollBackward, Point: testPoint(t, slot)}
+}
+
+// fakeSource replays events, then returns io.EOF.
+type fakeSource struct {
+	chain  map[string]bool // points on the chain
+	events []*Event
+	found  []cardano.Point // points FindIntersect was called with
+}
+
+func (s *fakeSource) FindIntersect(ctx context.Context, points []cardano.Point) (cardano.Point, Tip, error) {
+	s.found = points
+	for _, point := range points {
+		if point.IsOrigin() || s.chain[point.String()] {
+			return point, Tip{}, nil
+		}
+	}
+	return cardano.Point{}, Tip{}, ErrNoIntersection
+}
+
+func (s *fakeSource) Next(ctx context.Context) (*Event, error) {
+	if len(s.events) == 0 {
+		return nil, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+type recordingHandler struct {
+	events []string
+}
+
+func (h *recordingHandler) RollForward(ctx context.Context, block *cardano.Block, tip Tip) error {
+	h.events = append(h.events, fmt.Sprintf("forward %v", block.Slot))
+	return nil
+}
+
+func (h *recordingHandler) RollBackward(ctx context.Context, point cardano.Point, tip Tip) error {
+	h.events = append(h.events, fmt.Sprintf("backward %v", point.Slot))
+	return nil
+}
+
+func slots(points []cardano.Point) []uint64 {
+	var slots []uint64
+	for _, point := range points {
+		slots = append(slots, point.Slot)
+	}
+	return slots
+}
+
+func TestFollower(t *testing.T) {
+	source := &fakeSource{events: []*Event{
+		backward(t, 0),
+		forward(t, 1),
+		forward(t, 2),
+		forward(t, 3),
+		backward(t, 2),
+		forward(t, 4),
+		forward(t, 5),
+	}}
+	handler := &recordingHandler{}
+	store := NewMemoryStore()
+	f := NewFollower(source, handler, store, &Options{Points: 3})
+	if err := f.Run(context.Background()); !errors.Is(err, io.EOF) {
+		t.Fatalf("invalid error: got %v want %v", err, io.EOF)
+	}
+
+	wantEvents := []string{"backward 0", "forward 1", "forward 2", "forward 3", "backward 2", "forward 4", "forward 5"}
+	if got, want := fmt.Sprint(handler.events), fmt.Sprint(wantEvents); got != want {
+		t.Errorf("invalid events: got %v want %v", got, want)
+	}
+	if got, want := len(source.found), 1; got != want || !source.found[0].IsOrigin() {
+		t.Errorf("invalid start points: got %v", source.found)
+	}
+	points, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(slots(points)), "[5 4 2]"; got != want {
+		t.Errorf("invalid points: got %v want %v", got, want)
+	}
+
+	// resume from the points persisted
+	source = &fakeSource{chain: map[string]bool{testPoint(t, 4).String(): true}, events: []*Event{
+		backward(t, 4),
+		forward(t, 6),
+	}}
+	f = NewFollower(source, handler, store, &Options{Points: 3})
+	if err := f.Run(context.Background()); !errors.Is(err, io.EOF) {
+		t.Fatalf("invalid error: got %v want %v", err, io.EOF)
+	}
+	if got, want := fmt.Sprint(slots(source.found)), "[5 4 2]"; got != want {
+		t.Errorf("invalid resume points: got %v want %v", got, want)
+	}
+	points, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(slots(points)), "[6 4 2]"; got != want {
+		t.Errorf("invalid points: got %v want %v", got, want)
+	}
+}
+
+func TestFollowerNoIntersection(t *testing.T) {
+	source := &fakeSource{}
+	f := NewFollower(source, &recordingHandler{}, NewMemoryStore(), &Options{Start: []cardano.Point{testPoint(t, 1)}})
+	if err := f.Run(context.Background()); !errors.Is(err, ErrNoIntersection) {
+		t.Fatalf("invalid error: got %v want %v", err, ErrNoIntersection)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "points.json"))
+	points, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 0 {
+		t.Fatalf("invalid points: got %v want none", points)
+	}
+
+	want := []cardano.Point{testPoint(t, 2), testPoint(t, 1), {}}
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("invalid points: got %v want %v", got, want)
+	}
+}
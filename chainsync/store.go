@@ -0,0 +1,120 @@
+package chainsync
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// Store persists the points of the recent blocks handled, most recent first.
+type Store interface {
+	// Load returns the points persisted, none if nothing was saved yet.
+	Load() ([]cardano.Point, error)
+
+	// Save replaces the points persisted.
+	Save(points []cardano.Point) error
+}
+
+// MemoryStore is a Store keeping the points in memory.
+type MemoryStore struct {
+	mu     sync.Mutex
+	points []cardano.Point
+}
+
+// check interface
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a new empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Load() ([]cardano.Point, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cardano.Point(nil), s.points...), nil
+}
+
+func (s *MemoryStore) Save(points []cardano.Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.points = append(s.points[:0], points...)
+	return nil
+}
+
+// FileStore is a Store keeping the points in a JSON file.
+type FileStore struct {
+	path string
+}
+
+// check interface
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore returns a new FileStore keeping the points in the file at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+type jsonPoint struct {
+	Slot uint64 `json:"slot"`
+	Hash string `json:"hash,omitempty"`
+}
+
+func (s *FileStore) Load() ([]cardano.Point, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jsonPoints []jsonPoint
+	if err := json.Unmarshal(data, &jsonPoints); err != nil {
+		return nil, err
+	}
+
+	points := make([]cardano.Point, len(jsonPoints))
+	for i, p := range jsonPoints {
+		if p.Hash == "" {
+			continue
+		}
+		if points[i], err = cardano.NewPoint(p.Slot, p.Hash); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+// Save writes the points to a temporary file renamed over the file, so the
+// file is never left partially written.
+func (s *FileStore) Save(points []cardano.Point) error {
+	jsonPoints := make([]jsonPoint, len(points))
+	for i, p := range points {
+		jsonPoints[i] = jsonPoint{Slot: p.Slot}
+		if !p.IsOrigin() {
+			jsonPoints[i].Hash = p.Hash.String()
+		}
+	}
+	data, err := json.Marshal(jsonPoints)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/signer"
+	"github.com/spf13/cobra"
+)
+
+// tokenEnv is the environment variable holding the bearer token required by the server.
+const tokenEnv = "CARDANO_SIGNER_TOKEN"
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "cardano-signer",
+		Short: "A signing daemon holding Cardano keys.",
+		Long: `Serve signatures of transaction body hashes over JSON-RPC, so the signing keys
+can be kept in an isolated process. If ` + tokenEnv + ` is set, requests must
+provide it as a bearer token.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keysFile, _ := cmd.Flags().GetString("keysFile")
+			if keysFile == "" {
+				return errors.New("invalid request. keysFile is empty")
+			}
+			listen, _ := cmd.Flags().GetString("listen")
+
+			signers, err := readSigners(keysFile)
+			if err != nil {
+				return err
+			}
+			return serve(cmd.Context(), listen, signer.NewServer(os.Getenv(tokenEnv), signers...))
+		},
+	}
+	rootCmd.Flags().StringP("keysFile", "k", "", "file with a signing key per line (bech32 or hex)")
+	rootCmd.Flags().StringP("listen", "l", "127.0.0.1:8090", "listen address")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cobra.CheckErr(rootCmd.ExecuteContext(ctx))
+}
+
+func serve(ctx context.Context, listen string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on %v", ln.Addr())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func readSigners(path string) ([]cardano.Signer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	signers := []cardano.Signer{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		keyStr := strings.TrimSpace(scanner.Text())
+		if keyStr == "" || strings.HasPrefix(keyStr, "#") {
+			continue
+		}
+		key, err := parseKey(keyStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		log.Printf("key %v", key.PubKey())
+		signers = append(signers, cardano.NewPrvKeySigner(key))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("no keys found in %s", path)
+	}
+	return signers, nil
+}
+
+func parseKey(keyStr string) (crypto.PrvKey, error) {
+	if _, isXpriv := crypto.GetKeyPrefixByXpriv(keyStr); isXpriv {
+		xpriv, err := crypto.NewXPrvKey(keyStr)
+		if err != nil {
+			return nil, err
+		}
+		return xpriv.PrvKey(), nil
+	}
+	if sk, err := crypto.NewPrvKey(keyStr); err == nil {
+		return sk, nil
+	}
+	sk, err := hex.DecodeString(keyStr)
+	if err != nil || len(sk) != 64 {
+		return nil, errors.New("invalid signing key")
+	}
+	return sk, nil
+}
@@ -25,6 +25,8 @@ func (h *txCmdHandler) Commands(ctx context.Context) []*cobra.Command {
 	return []*cobra.Command{
 		h.buildTxCmd(ctx),
 		h.dumpTxCmd(ctx),
+		h.witnessCmd(ctx),
+		h.assembleCmd(ctx),
 	}
 }
 
@@ -59,17 +61,27 @@ func (h *txCmdHandler) buildTxCmd(_ context.Context) *cobra.Command {
 				return err
 			}
 			coinsPerUTXOWordStr, _ := cmd.Flags().GetString("coinsPerUTXOWord")
-			if coinsPerUTXOWordStr == "" {
-				return errors.New("invalid request. coinsPerUTXOWord is empty")
+			coinsPerUTXOByteStr, _ := cmd.Flags().GetString("coinsPerUTXOByte")
+			if coinsPerUTXOWordStr == "" && coinsPerUTXOByteStr == "" {
+				return errors.New("invalid request. coinsPerUTXOWord and coinsPerUTXOByte are empty")
 			}
-			coinsPerUTXOWord, err := strconv.ParseUint(coinsPerUTXOWordStr, 10, 64)
-			if err != nil {
-				return err
+			var coinsPerUTXOWord, coinsPerUTXOByte uint64
+			if coinsPerUTXOWordStr != "" {
+				coinsPerUTXOWord, err = strconv.ParseUint(coinsPerUTXOWordStr, 10, 64)
+				if err != nil {
+					return err
+				}
+			}
+			if coinsPerUTXOByteStr != "" {
+				coinsPerUTXOByte, err = strconv.ParseUint(coinsPerUTXOByteStr, 10, 64)
+				if err != nil {
+					return err
+				}
 			}
 			changeAddress, _ := cmd.Flags().GetString("changeAddress")
 			keysStr, _ := cmd.Flags().GetString("keys")
 
-			tx, err := buildTx(inputs, outputs, minFeeA, minFeeB, coinsPerUTXOWord, changeAddress, keysStr)
+			tx, err := buildTx(inputs, outputs, minFeeA, minFeeB, coinsPerUTXOWord, coinsPerUTXOByte, changeAddress, keysStr)
 			if err != nil {
 				return err
 			}
@@ -78,19 +90,20 @@ func (h *txCmdHandler) buildTxCmd(_ context.Context) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("inputs", "i", "", `input list by json. ex: '[{"txHash":"7b240d1907090478f08e5b2cac2c5e0da5a76a511390fa5aa962d540fba8f8d4","index":0,"amount":{"coin":"10000000000"}},...]'`)
+	cmd.Flags().StringP("inputs", "i", "", `input list by json. ex: '[{"txHash":"7b240d1907090478f08e5b2cac2c5e0da5a76a511390fa5aa962d540fba8f8d4","index":0,"amount":{"coin":"10000000000"},"spender":"addr_test1vqeux7xwusdju9dvsj8h7mca9aup2k439kfmwy773xxc2hcu7zy99"},...]'`)
 	cmd.Flags().StringP("outputs", "o", "", `output list by json. ex: '[{"address":"addr_test1vqeux7xwusdju9dvsj8h7mca9aup2k439kfmwy773xxc2hcu7zy99","amount":{"coin":"9000000000"}},...]'`)
 	cmd.Flags().StringP("minFeeA", "a", "", "MinFeeA")
 	cmd.Flags().StringP("minFeeB", "b", "", "MinFeeB")
-	cmd.Flags().StringP("coinsPerUTXOWord", "u", "", "CoinsPerUTXOWord")
+	cmd.Flags().StringP("coinsPerUTXOWord", "u", "", "CoinsPerUTXOWord (alonzo)")
+	cmd.Flags().StringP("coinsPerUTXOByte", "", "", "CoinsPerUTXOByte (babbage or later)")
 	cmd.Flags().StringP("changeAddress", "c", "", "change address if needed")
-	cmd.Flags().StringP("keys", "k", "", "key list order by tx inputs")
+	cmd.Flags().StringP("keys", "k", "", "key list order by tx inputs. if empty, an unsigned transaction is built")
 	return cmd
 }
 
 func buildTx(
 	inputs, outputs string,
-	minFeeA, minFeeB, coinsPerUTXOWord uint64,
+	minFeeA, minFeeB, coinsPerUTXOWord, coinsPerUTXOByte uint64,
 	changeAddress, keysStr string,
 ) (*cardano.Tx, error) {
 	txInputs := []*cardano.TxInput{}
@@ -121,6 +134,7 @@ func buildTx(
 		MinFeeA:          cardano.Coin(minFeeA),
 		MinFeeB:          cardano.Coin(minFeeB),
 		CoinsPerUTXOWord: cardano.Coin(coinsPerUTXOWord),
+		CoinsPerUTXOByte: cardano.Coin(coinsPerUTXOByte),
 	})
 	builder.AddInputs(txInputs...)
 	builder.AddOutputs(txOutputs...)
@@ -157,6 +171,10 @@ func buildTx(
 
 func getKeys(keysStr string, txInputs []*cardano.TxInput) ([]crypto.PrvKey, error) {
 	keys := make([]crypto.PrvKey, 0, len(txInputs))
+	if keysStr == "" {
+		// Build an unsigned transaction, the fee is estimated from the input spenders.
+		return keys, nil
+	}
 	keyStrList := strings.Split(keysStr, ",")
 	for _, keyStr := range keyStrList {
 		if _, isXpriv := crypto.GetKeyPrefixByXpriv(keyStr); isXpriv {
@@ -181,17 +199,6 @@ func getKeys(keysStr string, txInputs []*cardano.TxInput) ([]crypto.PrvKey, erro
 			}
 		}
 	}
-	if len(keyStrList) == 0 {
-		// Set a dummy private key to calculate the fee.
-		xprv, err := crypto.NewXPrvKey(dummyRootXsk)
-		if err != nil {
-			return nil, err
-		}
-		for i := range txInputs {
-			childKey := xprv.Derive(uint32(i))
-			keys = append(keys, childKey.PrvKey())
-		}
-	}
 	return keys, nil
 }
 
@@ -223,6 +230,156 @@ func (h *txCmdHandler) dumpTxCmd(_ context.Context) *cobra.Command {
 	return cmd
 }
 
+func (h *txCmdHandler) witnessCmd(_ context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "witness",
+		Short: "create transaction witness",
+		Long:  `Create a detached key witness of a transaction, without modifying it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tx, era, err := readTx(cmd)
+			if err != nil {
+				return err
+			}
+			keysStr, _ := cmd.Flags().GetString("keys")
+			if keysStr == "" {
+				return errors.New("invalid request. keys is empty")
+			}
+			keys, err := getKeys(keysStr, tx.Body.Inputs)
+			if err != nil {
+				return err
+			}
+			outFile, _ := cmd.Flags().GetString("outFile")
+
+			for i, key := range keys {
+				witness, err := tx.Witness(key)
+				if err != nil {
+					return err
+				}
+				envelope, err := cardano.NewWitnessTextEnvelope(witness, era)
+				if err != nil {
+					return err
+				}
+				if outFile == "" {
+					fmt.Printf("witness[%d]: %v\n", i, envelope.CborHex)
+					continue
+				}
+				path := outFile
+				if len(keys) > 1 {
+					path = fmt.Sprintf("%s.%d", outFile, i)
+				}
+				if err := envelope.WriteFile(path); err != nil {
+					return err
+				}
+				fmt.Printf("witness[%d]: %v\n", i, path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("tx", "t", "", "transaction hex")
+	cmd.Flags().StringP("txFile", "f", "", "cardano-cli transaction file")
+	cmd.Flags().StringP("era", "e", "conway", "era of the transaction hex. the era of a transaction file is read from its type")
+	cmd.Flags().StringP("keys", "k", "", "signing key list")
+	cmd.Flags().StringP("outFile", "o", "", "cardano-cli witness file. if several keys are given, the key index is appended")
+	return cmd
+}
+
+func (h *txCmdHandler) assembleCmd(_ context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assemble",
+		Short: "assemble transaction witnesses",
+		Long:  `Add the witnesses of cardano-cli witness files to a transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tx, era, err := readTx(cmd)
+			if err != nil {
+				return err
+			}
+			witnessFiles, _ := cmd.Flags().GetString("witnessFiles")
+			if witnessFiles == "" {
+				return errors.New("invalid request. witnessFiles is empty")
+			}
+			for _, path := range strings.Split(witnessFiles, ",") {
+				envelope, err := cardano.ReadTextEnvelopeFile(path)
+				if err != nil {
+					return err
+				}
+				ws, err := envelope.WitnessSet()
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				if err := tx.MergeWitnessSet(ws); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+
+			if outFile, _ := cmd.Flags().GetString("outFile"); outFile != "" {
+				if err := cardano.NewTxTextEnvelope(tx, era).WriteFile(outFile); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("tx  : %v\n", tx.Hex())
+			return dumpTx(tx)
+		},
+	}
+
+	cmd.Flags().StringP("tx", "t", "", "transaction hex")
+	cmd.Flags().StringP("txFile", "f", "", "cardano-cli transaction file")
+	cmd.Flags().StringP("era", "e", "conway", "era of the transaction hex. the era of a transaction file is read from its type")
+	cmd.Flags().StringP("witnessFiles", "w", "", "comma separated cardano-cli witness files")
+	cmd.Flags().StringP("outFile", "o", "", "cardano-cli transaction file to write")
+	return cmd
+}
+
+// readTx reads the transaction from the tx or txFile flags, along with its era.
+// The era of a transaction file is the era of its envelope, the era of a
+// transaction hex is given by the era flag.
+func readTx(cmd *cobra.Command) (*cardano.Tx, cardano.Era, error) {
+	txStr, _ := cmd.Flags().GetString("tx")
+	txFile, _ := cmd.Flags().GetString("txFile")
+	if txFile != "" {
+		envelope, err := cardano.ReadTextEnvelopeFile(txFile)
+		if err != nil {
+			return nil, 0, err
+		}
+		era, err := envelope.Era()
+		if err != nil {
+			return nil, 0, err
+		}
+		tx, err := envelope.Tx()
+		if err != nil {
+			return nil, 0, err
+		}
+		return tx, era, nil
+	}
+	if txStr == "" {
+		return nil, 0, errors.New("invalid request. tx and txFile are empty")
+	}
+	eraStr, _ := cmd.Flags().GetString("era")
+	era, err := parseEra(eraStr)
+	if err != nil {
+		return nil, 0, err
+	}
+	txBytes, err := hex.DecodeString(txStr)
+	if err != nil {
+		return nil, 0, err
+	}
+	tx := &cardano.Tx{}
+	if err := tx.UnmarshalCBOR(txBytes); err != nil {
+		return nil, 0, err
+	}
+	return tx, era, nil
+}
+
+// parseEra returns the era of a name such as babbage or conway.
+func parseEra(name string) (cardano.Era, error) {
+	for era := cardano.ShelleyEra; era <= cardano.ConwayEra; era++ {
+		if strings.EqualFold(name, era.String()) {
+			return era, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid request. unknown era %q", name)
+}
+
 func dumpTx(tx *cardano.Tx) error {
 	txHash, err := tx.Hash()
 	if err != nil {
@@ -256,5 +413,3 @@ func dumpTx(tx *cardano.Tx) error {
 	fmt.Printf("AuxiliaryData: %v\n", tx.AuxiliaryData)
 	return nil
 }
-
-var dummyRootXsk = "root_xsk1cpj6l55r9nvtpp7ymx4hqy05s8hpupepu782thtqnuat8u2k6fzaza4a3l2wcc95wvwrjx9z5u4qyfkqe5geas6mgljd2kyyvel4223r7l7u6jsscmxjcuun43sasau88cjg7stkxj4rmqf27vnll6wkyqya0lre"
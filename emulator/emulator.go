@@ -0,0 +1,422 @@
+// Package emulator implements an in-memory ledger satisfying cardano.Node, so
+// transactions can be built, validated and submitted offline.
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"golang.org/x/crypto/blake2b"
+)
+
+// DefaultEpochLength is the epoch length of the emulator, in slots.
+const DefaultEpochLength = 432000
+
+// DELEG and POOL rules predicate failures, checked when applying certificates
+// and withdrawals against the emulator state.
+var (
+	ErrStakeKeyAlreadyRegisteredDELEG     = &cardano.PredicateFailure{Rule: "DELEG", Name: "StakeKeyAlreadyRegisteredDELEG"}
+	ErrStakeKeyNotRegisteredDELEG         = &cardano.PredicateFailure{Rule: "DELEG", Name: "StakeKeyNotRegisteredDELEG"}
+	ErrStakeKeyNonZeroAccountBalanceDELEG = &cardano.PredicateFailure{Rule: "DELEG", Name: "StakeKeyNonZeroAccountBalanceDELEG"}
+	ErrStakeDelegationImpossibleDELEG     = &cardano.PredicateFailure{Rule: "DELEG", Name: "StakeDelegationImpossibleDELEG"}
+	ErrDelegateeNotRegisteredDELEG        = &cardano.PredicateFailure{Rule: "DELEG", Name: "DelegateeNotRegisteredDELEG"}
+	ErrStakePoolNotRegisteredOnKeyPOOL    = &cardano.PredicateFailure{Rule: "POOL", Name: "StakePoolNotRegisteredOnKeyPOOL"}
+	ErrWithdrawalsNotInRewardsCERTS       = &cardano.PredicateFailure{Rule: "CERTS", Name: "WithdrawalsNotInRewardsCERTS"}
+)
+
+// RewardAccount is the state of a registered stake credential.
+type RewardAccount struct {
+	Balance    cardano.Coin
+	Delegation cardano.PoolKeyHash // or nil
+}
+
+// Emulator is an in-memory ledger implementing cardano.Node.
+// Submitted transactions are validated with the phase-1 ledger rules and applied
+// at once, each in its own block.
+type Emulator struct {
+	mu sync.Mutex
+
+	network     cardano.Network
+	protocol    *cardano.ProtocolParams
+	epochLength uint64
+
+	slot  uint64
+	block uint64
+
+	utxos          map[string]cardano.UTxO
+	rewardAccounts map[string]*RewardAccount
+	pools          map[string]cardano.Certificate
+	txs            map[string]*cardano.Tx
+	txBlocks       map[string]txBlock
+	usedAddrs      map[string]bool
+	seeded         uint64
+}
+
+// txBlock is the block including a transaction.
+type txBlock struct {
+	block uint64
+	slot  uint64
+}
+
+// check interface
+var (
+	_ cardano.Node               = (*Emulator)(nil)
+	_ cardano.TxStatusNode       = (*Emulator)(nil)
+	_ cardano.AddressHistoryNode = (*Emulator)(nil)
+)
+
+// NewNode returns a new Emulator with an empty ledger.
+func NewNode(network cardano.Network, protocol *cardano.ProtocolParams) *Emulator {
+	return &Emulator{
+		network:        network,
+		protocol:       protocol,
+		epochLength:    DefaultEpochLength,
+		utxos:          map[string]cardano.UTxO{},
+		rewardAccounts: map[string]*RewardAccount{},
+		pools:          map[string]cardano.Certificate{},
+		txs:            map[string]*cardano.Tx{},
+		txBlocks:       map[string]txBlock{},
+		usedAddrs:      map[string]bool{},
+	}
+}
+
+// UTxOs returns the unspent outputs of the address, sorted by output reference.
+func (e *Emulator) UTxOs(_ context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	addrBytes := addr.Bytes()
+	utxos := []cardano.UTxO{}
+	for _, utxo := range e.utxos {
+		if bytes.Equal(utxo.Spender.Bytes(), addrBytes) {
+			utxos = append(utxos, utxo)
+		}
+	}
+	sortUTxOs(utxos)
+	return utxos, nil
+}
+
+// Tip returns the current slot, the number of blocks and the current epoch.
+func (e *Emulator) Tip(_ context.Context) (*cardano.NodeTip, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return &cardano.NodeTip{Block: e.block, Epoch: e.slot / e.epochLength, Slot: e.slot}, nil
+}
+
+// SubmitTx validates the transaction against the ledger rules and applies it.
+// Failures are returned as *cardano.PredicateFailure joined.
+func (e *Emulator) SubmitTx(_ context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state := &cardano.LedgerState{
+		Protocol: e.protocol,
+		Network:  e.network,
+		Slot:     e.slot,
+		UTxOs:    e.utxoList(),
+		Pools:    e.poolList(),
+	}
+	if err := state.ValidateTx(tx); err != nil {
+		return nil, err
+	}
+	if err := e.validateDelegation(tx); err != nil {
+		return nil, err
+	}
+
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	e.apply(txHash, tx)
+	e.txs[txHash.String()] = tx
+	e.block++
+	e.txBlocks[txHash.String()] = txBlock{block: e.block, slot: e.slot}
+	return &txHash, nil
+}
+
+// TxStatus returns the confirmation status of a transaction, confirmed by the
+// blocks of the transactions submitted after it.
+func (e *Emulator) TxStatus(_ context.Context, txHash cardano.Hash32) (*cardano.TxStatus, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := &cardano.TxStatus{TxHash: txHash}
+	if b, ok := e.txBlocks[txHash.String()]; ok {
+		status.Confirmed = true
+		status.Confirmations = e.block - b.block + 1
+		status.Block, status.Slot = b.block, b.slot
+	}
+	return status, nil
+}
+
+// AddressUsed reports whether an address received an output, added or of a
+// transaction applied.
+func (e *Emulator) AddressUsed(_ context.Context, addr cardano.Address) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.usedAddrs[string(addr.Bytes())], nil
+}
+
+// ProtocolParams returns the protocol parameters of the emulator.
+func (e *Emulator) ProtocolParams(_ context.Context) (*cardano.ProtocolParams, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	protocol := *e.protocol
+	return &protocol, nil
+}
+
+// Network returns the network of the emulator.
+func (e *Emulator) Network() cardano.Network {
+	return e.network
+}
+
+// SetProtocolParams replaces the protocol parameters.
+func (e *Emulator) SetProtocolParams(protocol *cardano.ProtocolParams) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.protocol = protocol
+}
+
+// SetEpochLength sets the epoch length, in slots.
+func (e *Emulator) SetEpochLength(epochLength uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.epochLength = epochLength
+}
+
+// AddUTxO seeds a genesis output paying the amount to the address and returns it.
+func (e *Emulator) AddUTxO(addr cardano.Address, amount *cardano.Value) cardano.UTxO {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seeded++
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint64(seed, e.seeded)
+	hash := blake2b.Sum256(append([]byte("genesis"), seed...))
+
+	utxo := cardano.UTxO{TxHash: hash[:], Index: 0, Spender: addr, Amount: amount}
+	e.utxos[outRef(utxo.TxHash, utxo.Index)] = utxo
+	e.usedAddrs[string(addr.Bytes())] = true
+	return utxo
+}
+
+// UTxO returns the unspent output of a transaction, if any.
+func (e *Emulator) UTxO(txHash cardano.Hash32, index uint64) (cardano.UTxO, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	utxo, ok := e.utxos[outRef(txHash, index)]
+	return utxo, ok
+}
+
+// Tx returns a transaction applied to the ledger, if any.
+func (e *Emulator) Tx(txHash cardano.Hash32) (*cardano.Tx, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tx, ok := e.txs[txHash.String()]
+	return tx, ok
+}
+
+// AdvanceSlots moves the current slot forward.
+func (e *Emulator) AdvanceSlots(slots uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.slot += slots
+}
+
+// AdvanceEpochs moves the current slot to the start of a following epoch.
+func (e *Emulator) AdvanceEpochs(epochs uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.slot = (e.slot/e.epochLength + epochs) * e.epochLength
+}
+
+// RewardAccount returns the state of the stake credential of a reward address,
+// or nil if it is not registered.
+func (e *Emulator) RewardAccount(rewardAccount cardano.Address) *RewardAccount {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.rewardAccounts[credentialKey(rewardAccount.Stake)]
+	if !ok {
+		return nil
+	}
+	copied := *account
+	return &copied
+}
+
+// AddRewards distributes rewards to a registered reward account.
+func (e *Emulator) AddRewards(rewardAccount cardano.Address, amount cardano.Coin) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	account, ok := e.rewardAccounts[credentialKey(rewardAccount.Stake)]
+	if !ok {
+		return fmt.Errorf("reward account %v not registered", rewardAccount)
+	}
+	account.Balance += amount
+	return nil
+}
+
+// validateDelegation checks the certificates and withdrawals against the
+// registered stake credentials and pools.
+func (e *Emulator) validateDelegation(tx *cardano.Tx) error {
+	registered := map[string]bool{}
+	for key := range e.rewardAccounts {
+		registered[key] = true
+	}
+	pools := map[string]bool{}
+	for key := range e.pools {
+		pools[key] = true
+	}
+
+	if tx.Body.Withdrawals != nil {
+		for _, rewardAccount := range tx.Body.Withdrawals.Keys() {
+			account, ok := e.rewardAccounts[credentialKey(rewardAccount.Stake)]
+			amount := tx.Body.Withdrawals.Get(rewardAccount)
+			if !ok || account.Balance != amount {
+				return withMessage(ErrWithdrawalsNotInRewardsCERTS, "withdrawal of %v from %v", amount, rewardAccount)
+			}
+		}
+	}
+
+	for _, cert := range tx.Body.Certificates {
+		key := credentialKey(cert.StakeCredential)
+		switch cert.Type {
+		case cardano.StakeRegistration:
+			if registered[key] {
+				return withMessage(ErrStakeKeyAlreadyRegisteredDELEG, "%v", cert.StakeCredential.Hash())
+			}
+			registered[key] = true
+		case cardano.StakeDeregistration:
+			if !registered[key] {
+				return withMessage(ErrStakeKeyNotRegisteredDELEG, "%v", cert.StakeCredential.Hash())
+			}
+			if account, ok := e.rewardAccounts[key]; ok && account.Balance != 0 && !withdraws(tx, cert.StakeCredential) {
+				return withMessage(ErrStakeKeyNonZeroAccountBalanceDELEG, "%v", account.Balance)
+			}
+			delete(registered, key)
+		case cardano.StakeDelegation:
+			if !registered[key] {
+				return withMessage(ErrStakeDelegationImpossibleDELEG, "%v", cert.StakeCredential.Hash())
+			}
+			if !pools[cert.PoolKeyHash.String()] {
+				return withMessage(ErrDelegateeNotRegisteredDELEG, "%v", cert.PoolKeyHash)
+			}
+		case cardano.PoolRegistration:
+			pools[cert.Operator.String()] = true
+		case cardano.PoolRetirement:
+			if !pools[cert.PoolKeyHash.String()] {
+				return withMessage(ErrStakePoolNotRegisteredOnKeyPOOL, "%v", cert.PoolKeyHash)
+			}
+			delete(pools, cert.PoolKeyHash.String())
+		}
+	}
+	return nil
+}
+
+// withdraws reports whether the transaction withdraws the rewards of the credential,
+// withdrawals being checked to empty the reward accounts.
+func withdraws(tx *cardano.Tx, cred cardano.StakeCredential) bool {
+	if tx.Body.Withdrawals == nil {
+		return false
+	}
+	for _, rewardAccount := range tx.Body.Withdrawals.Keys() {
+		if credentialKey(rewardAccount.Stake) == credentialKey(cred) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply consumes the inputs and produces the outputs of a valid transaction,
+// then applies its withdrawals and certificates.
+func (e *Emulator) apply(txHash cardano.Hash32, tx *cardano.Tx) {
+	for _, in := range tx.Body.Inputs {
+		delete(e.utxos, outRef(in.TxHash, in.Index))
+	}
+	for i, out := range tx.Body.Outputs {
+		utxo := cardano.UTxO{
+			TxHash:    txHash,
+			Index:     uint64(i),
+			Spender:   out.Address,
+			Amount:    out.Amount,
+			ScriptRef: out.ScriptRef,
+			DatumHash: out.DatumHash,
+			Datum:     out.Datum,
+		}
+		e.utxos[outRef(utxo.TxHash, utxo.Index)] = utxo
+		e.usedAddrs[string(out.Address.Bytes())] = true
+	}
+
+	if tx.Body.Withdrawals != nil {
+		for _, rewardAccount := range tx.Body.Withdrawals.Keys() {
+			e.rewardAccounts[credentialKey(rewardAccount.Stake)].Balance = 0
+		}
+	}
+	for _, cert := range tx.Body.Certificates {
+		key := credentialKey(cert.StakeCredential)
+		switch cert.Type {
+		case cardano.StakeRegistration:
+			e.rewardAccounts[key] = &RewardAccount{}
+		case cardano.StakeDeregistration:
+			delete(e.rewardAccounts, key)
+		case cardano.StakeDelegation:
+			e.rewardAccounts[key].Delegation = cert.PoolKeyHash
+		case cardano.PoolRegistration:
+			e.pools[cert.Operator.String()] = cert
+		case cardano.PoolRetirement:
+			delete(e.pools, cert.PoolKeyHash.String())
+		}
+	}
+}
+
+func (e *Emulator) utxoList() []cardano.UTxO {
+	utxos := make([]cardano.UTxO, 0, len(e.utxos))
+	for _, utxo := range e.utxos {
+		utxos = append(utxos, utxo)
+	}
+	return utxos
+}
+
+func (e *Emulator) poolList() []cardano.PoolKeyHash {
+	pools := make([]cardano.PoolKeyHash, 0, len(e.pools))
+	for _, cert := range e.pools {
+		pools = append(pools, cert.Operator)
+	}
+	return pools
+}
+
+func sortUTxOs(utxos []cardano.UTxO) {
+	sort.Slice(utxos, func(i, j int) bool {
+		if c := bytes.Compare(utxos[i].TxHash, utxos[j].TxHash); c != 0 {
+			return c < 0
+		}
+		return utxos[i].Index < utxos[j].Index
+	})
+}
+
+func withMessage(f *cardano.PredicateFailure, format string, args ...interface{}) *cardano.PredicateFailure {
+	return &cardano.PredicateFailure{Rule: f.Rule, Name: f.Name, Message: fmt.Sprintf(format, args...)}
+}
+
+func outRef(txHash cardano.Hash32, index uint64) string {
+	return fmt.Sprintf("%v#%v", txHash, index)
+}
+
+func credentialKey(cred cardano.StakeCredential) string {
+	return fmt.Sprintf("%v:%v", cred.Type, cred.Hash())
+}
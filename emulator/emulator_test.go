@@ -0,0 +1,411 @@
+package emulator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+var testProtocol = &cardano.ProtocolParams{
+	MinFeeA:              44,
+	MinFeeB:              155381,
+	CoinsPerUTXOByte:     4310,
+	KeyDeposit:           2e6,
+	PoolDeposit:          500e6,
+	MaxTxSize:            16384,
+	MaxValueSize:         5000,
+	CollateralPercentage: 150,
+	MaxCollateralInputs:  3,
+	ProtocolVersion:      cardano.ProtocolVersion{Major: 8},
+}
+
+type account struct {
+	paymentKey    crypto.XPrvKey
+	stakeKey      crypto.XPrvKey
+	addr          cardano.Address
+	rewardAccount cardano.Address
+	stake         cardano.StakeCredential
+}
+
+func newAccount(t *testing.T, name string) *account {
+	t.Helper()
+	a := &account{
+		paymentKey: crypto.NewXPrvKeyFromEntropy([]byte(name+" payment"), ""),
+		stakeKey:   crypto.NewXPrvKeyFromEntropy([]byte(name+" stake"), ""),
+	}
+	payment, err := cardano.NewKeyCredential(a.paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.stake, err = cardano.NewKeyCredential(a.stakeKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.addr, err = cardano.NewEnterpriseAddress(cardano.Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.rewardAccount, err = cardano.NewStakeAddress(cardano.Testnet, a.stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// newTxBuilder returns a builder spending all the UTxOs of the account, with
+// the change back to it.
+func newTxBuilder(t *testing.T, node *Emulator, a *account) *cardano.TxBuilder {
+	t.Helper()
+	ctx := context.Background()
+	protocol, err := node.ProtocolParams(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos, err := node.UTxOs(ctx, a.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip, err := node.Tip(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBuilder := cardano.NewTxBuilder(protocol)
+	for _, utxo := range utxos {
+		txBuilder.AddInputs(&cardano.TxInput{TxHash: utxo.TxHash, Index: utxo.Index, Amount: utxo.Amount, Spender: &a.addr})
+	}
+	txBuilder.AddChangeIfNeeded(a.addr)
+	txBuilder.SetTTL(tip.Slot + 100)
+	txBuilder.Sign(a.paymentKey.PrvKey())
+	return txBuilder
+}
+
+func balance(t *testing.T, node *Emulator, addr cardano.Address) *cardano.Value {
+	t.Helper()
+	utxos, err := node.UTxOs(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := cardano.NewValue(0)
+	for _, utxo := range utxos {
+		total = total.Add(utxo.Amount)
+	}
+	return total
+}
+
+func TestTransfer(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(cardano.Testnet, testProtocol)
+	alice, bob := newAccount(t, "alice"), newAccount(t, "bob")
+	genesis := node.AddUTxO(alice.addr, cardano.NewValue(100e6))
+
+	txBuilder := newTxBuilder(t, node, alice)
+	txBuilder.AddOutputs(cardano.NewTxOutput(bob.addr, cardano.NewValue(10e6)))
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := node.SubmitTx(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := balance(t, node, bob.addr).Coin, cardano.Coin(10e6); got != want {
+		t.Errorf("invalid bob balance: got %v want %v", got, want)
+	}
+	if got, want := balance(t, node, alice.addr).Coin, 90e6-tx.Body.Fee; got != want {
+		t.Errorf("invalid alice balance: got %v want %v", got, want)
+	}
+	if _, ok := node.UTxO(genesis.TxHash, genesis.Index); ok {
+		t.Error("genesis utxo not spent")
+	}
+	if _, ok := node.Tx(*txHash); !ok {
+		t.Error("transaction not found")
+	}
+	tip, err := node.Tip(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tip.Block, uint64(1); got != want {
+		t.Errorf("invalid tip block: got %v want %v", got, want)
+	}
+
+	// The addresses spent from stay used.
+	for _, addr := range []cardano.Address{alice.addr, bob.addr} {
+		if used, err := node.AddressUsed(ctx, addr); err != nil || !used {
+			t.Errorf("invalid used %v: got %v, %v want true", addr.Bech32(), used, err)
+		}
+	}
+	if used, err := node.AddressUsed(ctx, newAccount(t, "carol").addr); err != nil || used {
+		t.Errorf("invalid unused address: got %v, %v want false", used, err)
+	}
+
+	// Submitting again spends the same inputs.
+	if _, err := node.SubmitTx(ctx, tx); !errors.Is(err, cardano.ErrBadInputsUTxO) {
+		t.Errorf("double spend: got %v want %v", err, cardano.ErrBadInputsUTxO)
+	}
+}
+
+func TestExpiredTx(t *testing.T) {
+	node := NewNode(cardano.Testnet, testProtocol)
+	alice, bob := newAccount(t, "alice"), newAccount(t, "bob")
+	node.AddUTxO(alice.addr, cardano.NewValue(100e6))
+
+	txBuilder := newTxBuilder(t, node, alice)
+	txBuilder.AddOutputs(cardano.NewTxOutput(bob.addr, cardano.NewValue(10e6)))
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node.AdvanceSlots(100)
+	if _, err := node.SubmitTx(context.Background(), tx); !errors.Is(err, cardano.ErrOutsideValidityIntervalUTxO) {
+		t.Errorf("invalid error: got %v want %v", err, cardano.ErrOutsideValidityIntervalUTxO)
+	}
+}
+
+func TestMinting(t *testing.T) {
+	node := NewNode(cardano.Testnet, testProtocol)
+	alice := newAccount(t, "alice")
+	node.AddUTxO(alice.addr, cardano.NewValue(100e6))
+
+	policyKey := crypto.NewXPrvKeyFromEntropy([]byte("policy"), "")
+	policyScript, err := cardano.NewScriptPubKey(policyKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyID, err := cardano.NewPolicyID(policyScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assetName := cardano.NewAssetName("emulated")
+	mint := cardano.NewMint().Set(policyID, cardano.NewMintAssets().Set(assetName, big.NewInt(1000)))
+
+	txBuilder := newTxBuilder(t, node, alice)
+	txBuilder.AddOutputs(cardano.NewTxOutput(alice.addr, cardano.NewValueWithAssets(2e6, mint.MultiAsset())))
+	txBuilder.Mint(mint)
+	txBuilder.AddNativeScript(policyScript)
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The policy key did not sign yet.
+	if _, err := node.SubmitTx(context.Background(), tx); !errors.Is(err, cardano.ErrScriptWitnessNotValidatingUTXOW) {
+		t.Fatalf("invalid error: got %v want %v", err, cardano.ErrScriptWitnessNotValidatingUTXOW)
+	}
+	witness, err := tx.Witness(policyKey.PrvKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.AddVKeyWitnesses(witness); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := balance(t, node, alice.addr).MultiAsset.Get(policyID).Get(assetName)
+	if want := cardano.BigNum(1000); got != want {
+		t.Errorf("invalid minted amount: got %v want %v", got, want)
+	}
+}
+
+func TestStakeRegistrationAndWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(cardano.Testnet, testProtocol)
+	alice := newAccount(t, "alice")
+	node.AddUTxO(alice.addr, cardano.NewValue(100e6))
+
+	txBuilder := newTxBuilder(t, node, alice)
+	txBuilder.AddCertificate(cardano.Certificate{Type: cardano.StakeRegistration, StakeCredential: alice.stake})
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := balance(t, node, alice.addr).Coin, 100e6-testProtocol.KeyDeposit-tx.Body.Fee; got != want {
+		t.Errorf("invalid balance after deposit: got %v want %v", got, want)
+	}
+
+	txBuilder = newTxBuilder(t, node, alice)
+	txBuilder.AddCertificate(cardano.Certificate{Type: cardano.StakeRegistration, StakeCredential: alice.stake})
+	tx, err = txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); !errors.Is(err, ErrStakeKeyAlreadyRegisteredDELEG) {
+		t.Errorf("invalid error: got %v want %v", err, ErrStakeKeyAlreadyRegisteredDELEG)
+	}
+
+	poolKeyHash, err := cardano.NewHash28("20df8645abddf09403ba2656cda7da2cd163973a5e439c6e43dcbea9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBuilder = newTxBuilder(t, node, alice)
+	txBuilder.AddCertificate(cardano.Certificate{Type: cardano.StakeDelegation, StakeCredential: alice.stake, PoolKeyHash: poolKeyHash})
+	txBuilder.Sign(alice.stakeKey.PrvKey())
+	tx, err = txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); !errors.Is(err, ErrDelegateeNotRegisteredDELEG) {
+		t.Errorf("invalid error: got %v want %v", err, ErrDelegateeNotRegisteredDELEG)
+	}
+
+	if err := node.AddRewards(alice.rewardAccount, 5e6); err != nil {
+		t.Fatal(err)
+	}
+	before := balance(t, node, alice.addr).Coin
+
+	txBuilder = newTxBuilder(t, node, alice)
+	txBuilder.AddWithdrawal(alice.rewardAccount, 1e6)
+	txBuilder.Sign(alice.stakeKey.PrvKey())
+	tx, err = txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); !errors.Is(err, ErrWithdrawalsNotInRewardsCERTS) {
+		t.Errorf("partial withdrawal: got %v want %v", err, ErrWithdrawalsNotInRewardsCERTS)
+	}
+
+	txBuilder = newTxBuilder(t, node, alice)
+	txBuilder.AddWithdrawal(alice.rewardAccount, 5e6)
+	tx, err = txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); !errors.Is(err, cardano.ErrMissingVKeyWitnessesUTXOW) {
+		t.Errorf("unsigned withdrawal: got %v want %v", err, cardano.ErrMissingVKeyWitnessesUTXOW)
+	}
+	witness, err := tx.Witness(alice.stakeKey.PrvKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.AddVKeyWitnesses(witness); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := balance(t, node, alice.addr).Coin, before+5e6-tx.Body.Fee; got != want {
+		t.Errorf("invalid balance after withdrawal: got %v want %v", got, want)
+	}
+	if got := node.RewardAccount(alice.rewardAccount).Balance; got != 0 {
+		t.Errorf("invalid reward balance: got %v want 0", got)
+	}
+}
+
+func TestPoolReRegistration(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(cardano.Testnet, testProtocol)
+	alice := newAccount(t, "alice")
+	node.AddUTxO(alice.addr, cardano.NewValue(1000e6))
+	payment, err := cardano.NewKeyCredential(alice.paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	vrfKeyHash, err := cardano.NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := cardano.Certificate{
+		Type:          cardano.PoolRegistration,
+		Operator:      payment.KeyHash,
+		VrfKeyHash:    vrfKeyHash,
+		Margin:        cardano.UnitInterval{P: 1, Q: 100},
+		RewardAccount: alice.rewardAccount,
+	}
+
+	// The builder does not pay the pool deposit, taken from the change.
+	txBuilder := newTxBuilder(t, node, alice)
+	txBuilder.AddCertificate(cert)
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Body.Outputs[len(tx.Body.Outputs)-1].Amount.Coin -= testProtocol.PoolDeposit
+	witness, err := tx.Witness(alice.paymentKey.PrvKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.WitnessSet.VKeyWitnessSet = []cardano.VKeyWitness{witness}
+	if _, err := node.SubmitTx(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updating the parameters of the registered pool pays no deposit.
+	cert.Pledge = 10e6
+	txBuilder = newTxBuilder(t, node, alice)
+	txBuilder.AddCertificate(cert)
+	tx, err = txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAwaitTx(t *testing.T) {
+	ctx := context.Background()
+	node := NewNode(cardano.Testnet, testProtocol)
+	alice, bob := newAccount(t, "alice"), newAccount(t, "bob")
+	node.AddUTxO(alice.addr, cardano.NewValue(100e6))
+	node.AdvanceSlots(10)
+
+	txBuilder := newTxBuilder(t, node, alice)
+	txBuilder.AddOutputs(cardano.NewTxOutput(bob.addr, cardano.NewValue(10e6)))
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmation, err := cardano.SubmitAndAwait(ctx, node, tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := confirmation.Outcome, cardano.TxConfirmed; got != want {
+		t.Fatalf("invalid outcome: got %v want %v", got, want)
+	}
+	if confirmation.Block != 1 || confirmation.Slot != 10 || confirmation.Confirmations != 1 {
+		t.Errorf("invalid confirmation: got %+v", confirmation)
+	}
+
+	// The next transaction confirms the first one.
+	txBuilder = newTxBuilder(t, node, bob)
+	next, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.SubmitTx(ctx, next); err != nil {
+		t.Fatal(err)
+	}
+	confirmation, err = cardano.AwaitTx(ctx, node, tx, &cardano.AwaitOptions{Depth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := confirmation.Confirmations, uint64(2); got != want {
+		t.Errorf("invalid confirmations: got %v want %v", got, want)
+	}
+
+	// A transaction never submitted expires with its validity interval.
+	txBuilder = newTxBuilder(t, node, alice)
+	expired, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.AdvanceSlots(100)
+	confirmation, err = cardano.AwaitTx(ctx, node, expired, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := confirmation.Outcome, cardano.TxExpired; got != want {
+		t.Errorf("invalid outcome: got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package cardano
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ByronGenesis is the subset of the Byron genesis configuration used by the library.
+type ByronGenesis struct {
+	StartTime      int64 `json:"startTime"`
+	ProtocolConsts struct {
+		K             uint64 `json:"k"`
+		ProtocolMagic uint32 `json:"protocolMagic"`
+	} `json:"protocolConsts"`
+	BlockVersionData struct {
+		SlotDuration string `json:"slotDuration"`
+	} `json:"blockVersionData"`
+}
+
+// ParseByronGenesis parses a Byron genesis file.
+func ParseByronGenesis(data []byte) (*ByronGenesis, error) {
+	genesis := &ByronGenesis{}
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return nil, fmt.Errorf("invalid byron genesis: %w", err)
+	}
+	return genesis, nil
+}
+
+// SystemStart returns the start time of the chain.
+func (g *ByronGenesis) SystemStart() time.Time {
+	return time.Unix(g.StartTime, 0).UTC()
+}
+
+// SlotLength returns the duration of a Byron slot.
+func (g *ByronGenesis) SlotLength() (time.Duration, error) {
+	ms, err := strconv.ParseUint(g.BlockVersionData.SlotDuration, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byron slot duration %q: %w", g.BlockVersionData.SlotDuration, err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// EpochLength returns the number of slots of a Byron epoch, that is 10k.
+func (g *ByronGenesis) EpochLength() uint64 {
+	return 10 * g.ProtocolConsts.K
+}
+
+// ShelleyGenesis is the subset of the Shelley genesis configuration used by the library.
+type ShelleyGenesis struct {
+	SystemStart       time.Time `json:"systemStart"`
+	NetworkMagic      uint32    `json:"networkMagic"`
+	NetworkID         string    `json:"networkId"`
+	EpochLength       uint64    `json:"epochLength"`
+	SlotLength        float64   `json:"slotLength"`
+	SecurityParam     uint64    `json:"securityParam"`
+	ActiveSlotsCoeff  float64   `json:"activeSlotsCoeff"`
+	SlotsPerKESPeriod uint64    `json:"slotsPerKESPeriod"`
+	MaxKESEvolutions  uint64    `json:"maxKESEvolutions"`
+	UpdateQuorum      uint64    `json:"updateQuorum"`
+	MaxLovelaceSupply uint64    `json:"maxLovelaceSupply"`
+	ProtocolParams    struct {
+		MinFeeA            Coin     `json:"minFeeA"`
+		MinFeeB            Coin     `json:"minFeeB"`
+		MaxBlockBodySize   uint     `json:"maxBlockBodySize"`
+		MaxTxSize          uint     `json:"maxTxSize"`
+		MaxBlockHeaderSize uint     `json:"maxBlockHeaderSize"`
+		KeyDeposit         Coin     `json:"keyDeposit"`
+		PoolDeposit        Coin     `json:"poolDeposit"`
+		EMax               uint     `json:"eMax"`
+		NOpt               uint     `json:"nOpt"`
+		A0                 Rational `json:"a0"`
+		Rho                Rational `json:"rho"`
+		Tau                Rational `json:"tau"`
+		D                  Rational `json:"decentralisationParam"`
+		MinPoolCost        Coin     `json:"minPoolCost"`
+		ProtocolVersion    struct {
+			Major uint `json:"major"`
+			Minor uint `json:"minor"`
+		} `json:"protocolVersion"`
+	} `json:"protocolParams"`
+}
+
+// ParseShelleyGenesis parses a Shelley genesis file.
+func ParseShelleyGenesis(data []byte) (*ShelleyGenesis, error) {
+	genesis := &ShelleyGenesis{}
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return nil, fmt.Errorf("invalid shelley genesis: %w", err)
+	}
+	return genesis, nil
+}
+
+// SlotLengthDuration returns the duration of a Shelley slot.
+func (g *ShelleyGenesis) SlotLengthDuration() time.Duration {
+	return time.Duration(g.SlotLength * float64(time.Second))
+}
+
+// Network returns the network of the addresses described by the genesis.
+func (g *ShelleyGenesis) Network() Network {
+	if g.NetworkID == "Mainnet" {
+		return Mainnet
+	}
+	return Testnet
+}
+
+// AlonzoGenesis is the subset of the Alonzo genesis configuration used by the library.
+type AlonzoGenesis struct {
+	LovelacePerUTxOWord Coin `json:"lovelacePerUTxOWord"`
+	ExecutionPrices     struct {
+		PrSteps     *Rational `json:"prSteps"`
+		PrMem       *Rational `json:"prMem"`
+		PriceSteps  *Rational `json:"priceSteps"`
+		PriceMemory *Rational `json:"priceMemory"`
+	} `json:"executionPrices"`
+	MaxTxExUnits         genesisExUnits `json:"maxTxExUnits"`
+	MaxBlockExUnits      genesisExUnits `json:"maxBlockExUnits"`
+	MaxValueSize         uint           `json:"maxValueSize"`
+	CollateralPercentage uint           `json:"collateralPercentage"`
+	MaxCollateralInputs  uint           `json:"maxCollateralInputs"`
+	CostModels           CostModels     `json:"costModels"`
+}
+
+// genesisExUnits are execution units, encoded either with the genesis or the
+// ledger field names.
+type genesisExUnits struct {
+	ExUnitsMem   uint64 `json:"exUnitsMem"`
+	ExUnitsSteps uint64 `json:"exUnitsSteps"`
+	Memory       uint64 `json:"memory"`
+	Steps        uint64 `json:"steps"`
+}
+
+func (eu genesisExUnits) exUnits() ExUnits {
+	return ExUnits{Mem: eu.ExUnitsMem + eu.Memory, Steps: eu.ExUnitsSteps + eu.Steps}
+}
+
+// ParseAlonzoGenesis parses an Alonzo genesis file.
+func ParseAlonzoGenesis(data []byte) (*AlonzoGenesis, error) {
+	genesis := &AlonzoGenesis{}
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return nil, fmt.Errorf("invalid alonzo genesis: %w", err)
+	}
+	return genesis, nil
+}
+
+// ExUnitPrices returns the execution unit prices of the genesis.
+func (g *AlonzoGenesis) ExUnitPrices() ExUnitPrices {
+	var prices ExUnitPrices
+	for _, p := range []*Rational{g.ExecutionPrices.PrMem, g.ExecutionPrices.PriceMemory} {
+		if p != nil {
+			prices.MemPrice = *p
+		}
+	}
+	for _, p := range []*Rational{g.ExecutionPrices.PrSteps, g.ExecutionPrices.PriceSteps} {
+		if p != nil {
+			prices.StepPrice = *p
+		}
+	}
+	return prices
+}
+
+// ConwayGenesis is the subset of the Conway genesis configuration used by the library.
+type ConwayGenesis struct {
+	PoolVotingThresholds       PoolVotingThresholds `json:"poolVotingThresholds"`
+	DRepVotingThresholds       DRepVotingThresholds `json:"dRepVotingThresholds"`
+	CommitteeMinSize           uint64               `json:"committeeMinSize"`
+	CommitteeMaxTermLength     uint64               `json:"committeeMaxTermLength"`
+	GovActionLifetime          uint64               `json:"govActionLifetime"`
+	GovActionDeposit           Coin                 `json:"govActionDeposit"`
+	DRepDeposit                Coin                 `json:"dRepDeposit"`
+	DRepActivity               uint64               `json:"dRepActivity"`
+	MinFeeRefScriptCostPerByte Rational             `json:"minFeeRefScriptCostPerByte"`
+	PlutusV3CostModel          []int64              `json:"plutusV3CostModel"`
+}
+
+// ParseConwayGenesis parses a Conway genesis file.
+func ParseConwayGenesis(data []byte) (*ConwayGenesis, error) {
+	genesis := &ConwayGenesis{}
+	if err := json.Unmarshal(data, genesis); err != nil {
+		return nil, fmt.Errorf("invalid conway genesis: %w", err)
+	}
+	return genesis, nil
+}
+
+// NodeConfig is the subset of the cardano-node configuration used by the library.
+type NodeConfig struct {
+	ByronGenesisFile           string  `json:"ByronGenesisFile"`
+	ShelleyGenesisFile         string  `json:"ShelleyGenesisFile"`
+	AlonzoGenesisFile          string  `json:"AlonzoGenesisFile"`
+	ConwayGenesisFile          string  `json:"ConwayGenesisFile"`
+	RequiresNetworkMagic       string  `json:"RequiresNetworkMagic"`
+	TestShelleyHardForkAtEpoch *uint64 `json:"TestShelleyHardForkAtEpoch"`
+}
+
+// ParseNodeConfig parses a cardano-node configuration file in JSON format.
+func ParseNodeConfig(data []byte) (*NodeConfig, error) {
+	config := &NodeConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid node config: %w", err)
+	}
+	return config, nil
+}
@@ -0,0 +1,498 @@
+// Package koios implements cardano.Node on top of the Koios REST API.
+package koios
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+const (
+	MainnetServer = "https://api.koios.rest/api/v1"
+	PreprodServer = "https://preprod.koios.rest/api/v1"
+	PreviewServer = "https://preview.koios.rest/api/v1"
+)
+
+// pageSize is the maximum number of rows returned by Koios in a response.
+const pageSize = 1000
+
+// maxBulkSize is the maximum number of addresses, stake addresses or
+// transactions sent in a single bulk request.
+var maxBulkSize = 50
+
+// Options are the options of a KoiosNode.
+type Options struct {
+	// Server is the Koios API URL, the public instance of the network if empty.
+	Server string
+
+	// Token is the bearer token of a Koios API tier, if any.
+	Token string
+
+	// HTTPClient is the client used for the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// KoiosNode implements Node using the Koios API.
+type KoiosNode struct {
+	network    cardano.Network
+	server     string
+	token      string
+	httpClient *http.Client
+}
+
+// check interface
+var (
+	_ cardano.Node               = (*KoiosNode)(nil)
+	_ cardano.TxStatusNode       = (*KoiosNode)(nil)
+	_ cardano.AccountInfoNode    = (*KoiosNode)(nil)
+	_ cardano.AddressHistoryNode = (*KoiosNode)(nil)
+)
+
+// NewNode returns a new instance of KoiosNode.
+func NewNode(network cardano.Network, opts *Options) *KoiosNode {
+	server := MainnetServer
+	switch network {
+	case cardano.Preview:
+		server = PreviewServer
+	case cardano.Preprod:
+		server = PreprodServer
+	}
+
+	k := &KoiosNode{network: network, server: server, httpClient: http.DefaultClient}
+	if opts != nil {
+		if opts.Server != "" {
+			k.server = strings.TrimSuffix(opts.Server, "/")
+		}
+		if opts.HTTPClient != nil {
+			k.httpClient = opts.HTTPClient
+		}
+		k.token = opts.Token
+	}
+	return k
+}
+
+type koiosError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Hint    string `json:"hint"`
+}
+
+// do sends a request and decodes the JSON response into result.
+func (k *KoiosNode) do(ctx context.Context, method, path, contentType string, body []byte, result any) error {
+	req, err := http.NewRequestWithContext(ctx, method, k.server+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		httpErr := &cardano.HTTPError{StatusCode: resp.StatusCode, Message: string(respBody)}
+		var kerr koiosError
+		if err := json.Unmarshal(respBody, &kerr); err == nil && kerr.Message != "" {
+			httpErr.Message = kerr.Message
+		}
+		return fmt.Errorf("koios: %w", httpErr)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+func (k *KoiosNode) get(ctx context.Context, path string, result any) error {
+	return k.do(ctx, "GET", path, "", nil, result)
+}
+
+func (k *KoiosNode) post(ctx context.Context, path string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return k.do(ctx, "POST", path, "application/json", body, result)
+}
+
+// postAll sends a paginated POST request until all the rows are fetched.
+func postAll[T any](ctx context.Context, k *KoiosNode, path string, params any) ([]T, error) {
+	var rows []T
+	for offset := 0; ; offset += pageSize {
+		var page []T
+		pagePath := fmt.Sprintf("%v?offset=%v&limit=%v", path, offset, pageSize)
+		if err := k.post(ctx, pagePath, params, &page); err != nil {
+			return nil, err
+		}
+		rows = append(rows, page...)
+		if len(page) < pageSize {
+			return rows, nil
+		}
+	}
+}
+
+// chunks splits the items in chunks of maxBulkSize.
+func chunks[T any](items []T) [][]T {
+	var result [][]T
+	for len(items) > maxBulkSize {
+		result = append(result, items[:maxBulkSize])
+		items = items[maxBulkSize:]
+	}
+	if len(items) > 0 {
+		result = append(result, items)
+	}
+	return result
+}
+
+type koiosAsset struct {
+	PolicyID  string       `json:"policy_id"`
+	AssetName string       `json:"asset_name"`
+	Quantity  cardano.Coin `json:"quantity"`
+}
+
+type koiosInlineDatum struct {
+	Bytes string `json:"bytes"`
+}
+
+type koiosScript struct {
+	Hash  string `json:"hash"`
+	Type  string `json:"type"`
+	Bytes string `json:"bytes"`
+}
+
+type koiosUTxO struct {
+	TxHash          string            `json:"tx_hash"`
+	TxIndex         uint64            `json:"tx_index"`
+	Address         string            `json:"address"`
+	Value           cardano.Coin      `json:"value"`
+	DatumHash       string            `json:"datum_hash"`
+	InlineDatum     *koiosInlineDatum `json:"inline_datum"`
+	ReferenceScript *koiosScript      `json:"reference_script"`
+	AssetList       []koiosAsset      `json:"asset_list"`
+}
+
+func (k *KoiosNode) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	return k.UTxOsBulk(ctx, addr)
+}
+
+// UTxOsBulk returns the unspent transaction outputs of many addresses, fetched
+// in as few requests as possible.
+func (k *KoiosNode) UTxOsBulk(ctx context.Context, addrs ...cardano.Address) ([]cardano.UTxO, error) {
+	utxos := []cardano.UTxO{}
+	for _, chunk := range chunks(addrs) {
+		params := struct {
+			Addresses []string `json:"_addresses"`
+			Extended  bool     `json:"_extended"`
+		}{Extended: true}
+		for _, addr := range chunk {
+			params.Addresses = append(params.Addresses, addr.Bech32())
+		}
+
+		kutxos, err := postAll[koiosUTxO](ctx, k, "/address_utxos", params)
+		if err != nil {
+			return nil, err
+		}
+		for _, kutxo := range kutxos {
+			utxo, err := kutxo.utxo()
+			if err != nil {
+				return nil, err
+			}
+			utxos = append(utxos, *utxo)
+		}
+	}
+	return utxos, nil
+}
+
+func (u *koiosUTxO) utxo() (*cardano.UTxO, error) {
+	txHash, err := cardano.NewHash32(u.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	spender, err := cardano.NewAddress(u.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := cardano.NewValue(u.Value)
+	for _, asset := range u.AssetList {
+		policyHash, err := cardano.NewHash28(asset.PolicyID)
+		if err != nil {
+			return nil, err
+		}
+		nameBytes, err := hex.DecodeString(asset.AssetName)
+		if err != nil {
+			return nil, err
+		}
+		policyID := cardano.NewPolicyIDFromHash(policyHash)
+		assetName := cardano.NewAssetName(string(nameBytes))
+		if assets := amount.MultiAsset.Get(policyID); assets != nil {
+			assets.Set(assetName, cardano.BigNum(asset.Quantity))
+		} else {
+			amount.MultiAsset.Set(policyID, cardano.NewAssets().Set(assetName, cardano.BigNum(asset.Quantity)))
+		}
+	}
+
+	utxo := &cardano.UTxO{
+		TxHash:  txHash,
+		Spender: spender,
+		Amount:  amount,
+		Index:   u.TxIndex,
+	}
+	if u.InlineDatum != nil {
+		if utxo.Datum, err = cardano.NewPlutusData(u.InlineDatum.Bytes); err != nil {
+			return nil, err
+		}
+	} else if u.DatumHash != "" {
+		if utxo.DatumHash, err = cardano.NewHash32(u.DatumHash); err != nil {
+			return nil, err
+		}
+	}
+	if u.ReferenceScript != nil {
+		if utxo.ScriptRef, err = u.ReferenceScript.scriptRef(); err != nil {
+			return nil, err
+		}
+	}
+	return utxo, nil
+}
+
+var plutusLanguages = map[string]cardano.ScriptHashNamespace{
+	"plutusV1": cardano.PlutusScriptNamespace,
+	"plutusV2": cardano.PlutusV2ScriptNamespace,
+	"plutusV3": cardano.PlutusV3ScriptNamespace,
+}
+
+// scriptRef parses a reference script. The plutus scripts bytes are accepted
+// with or without their CBOR bytes wrapping, the one matching the script hash
+// is kept.
+func (s *koiosScript) scriptRef() (*cardano.ScriptRef, error) {
+	scriptBytes, err := hex.DecodeString(s.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Type == "timelock" || s.Type == "multisig" {
+		var ns cardano.NativeScript
+		if err := ns.UnmarshalCBOR(scriptBytes); err != nil {
+			return nil, err
+		}
+		return cardano.NewNativeScriptRef(ns), nil
+	}
+
+	version, ok := plutusLanguages[s.Type]
+	if !ok {
+		return nil, fmt.Errorf("koios: unknown script type %v", s.Type)
+	}
+	scriptRef := cardano.NewPlutusScriptRef(version, scriptBytes)
+	if s.Hash == "" {
+		return scriptRef, nil
+	}
+	if hash, err := scriptRef.Hash(); err == nil && hash.String() == s.Hash {
+		return scriptRef, nil
+	}
+	unwrapped, err := cardano.NewPlutusScript(s.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("koios: script hash mismatch for %v", s.Hash)
+	}
+	scriptRef = cardano.NewPlutusScriptRef(version, unwrapped)
+	if hash, err := scriptRef.Hash(); err != nil || hash.String() != s.Hash {
+		return nil, fmt.Errorf("koios: script hash mismatch for %v", s.Hash)
+	}
+	return scriptRef, nil
+}
+
+type koiosTip struct {
+	EpochNo     uint64 `json:"epoch_no"`
+	AbsSlot     uint64 `json:"abs_slot"`
+	BlockHeight uint64 `json:"block_height"`
+}
+
+func (k *KoiosNode) Tip(ctx context.Context) (*cardano.NodeTip, error) {
+	var tips []koiosTip
+	if err := k.get(ctx, "/tip", &tips); err != nil {
+		return nil, err
+	}
+	if len(tips) == 0 {
+		return nil, errors.New("koios: empty tip")
+	}
+	return &cardano.NodeTip{
+		Block: tips[0].BlockHeight,
+		Epoch: tips[0].EpochNo,
+		Slot:  tips[0].AbsSlot,
+	}, nil
+}
+
+func (k *KoiosNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	if err := k.do(ctx, "POST", "/submittx", "application/cbor", tx.Bytes(), nil); err != nil {
+		return nil, err
+	}
+
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &txHash, nil
+}
+
+func (k *KoiosNode) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
+	var eparams []json.RawMessage
+	if err := k.get(ctx, "/epoch_params?order=epoch_no.desc&limit=1", &eparams); err != nil {
+		return nil, err
+	}
+	if len(eparams) == 0 {
+		return nil, errors.New("koios: empty epoch params")
+	}
+	return cardano.NewProtocolParamsFromKoiosJSON(eparams[0])
+}
+
+func (k *KoiosNode) Network() cardano.Network {
+	return k.network
+}
+
+type koiosAccountInfo struct {
+	StakeAddress     string       `json:"stake_address"`
+	Status           string       `json:"status"`
+	DelegatedPool    *string      `json:"delegated_pool"`
+	TotalBalance     cardano.Coin `json:"total_balance"`
+	UTxO             cardano.Coin `json:"utxo"`
+	Rewards          cardano.Coin `json:"rewards"`
+	Withdrawals      cardano.Coin `json:"withdrawals"`
+	RewardsAvailable cardano.Coin `json:"rewards_available"`
+	Deposit          cardano.Coin `json:"deposit"`
+}
+
+// AccountInfo returns the state of a stake address.
+func (k *KoiosNode) AccountInfo(ctx context.Context, stakeAddr cardano.Address) (*cardano.AccountInfo, error) {
+	infos, err := k.AccountInfos(ctx, stakeAddr)
+	if err != nil {
+		return nil, err
+	}
+	if len(infos) == 0 {
+		return &cardano.AccountInfo{StakeAddress: stakeAddr}, nil
+	}
+	return &infos[0], nil
+}
+
+// AccountInfos returns the state of many stake addresses. Unknown stake
+// addresses are omitted.
+func (k *KoiosNode) AccountInfos(ctx context.Context, stakeAddrs ...cardano.Address) ([]cardano.AccountInfo, error) {
+	infos := []cardano.AccountInfo{}
+	for _, chunk := range chunks(stakeAddrs) {
+		params := struct {
+			StakeAddresses []string `json:"_stake_addresses"`
+		}{}
+		for _, addr := range chunk {
+			params.StakeAddresses = append(params.StakeAddresses, addr.Bech32())
+		}
+
+		var kinfos []koiosAccountInfo
+		if err := k.post(ctx, "/account_info", params, &kinfos); err != nil {
+			return nil, err
+		}
+		for _, kinfo := range kinfos {
+			stakeAddr, err := cardano.NewAddress(kinfo.StakeAddress)
+			if err != nil {
+				return nil, err
+			}
+			info := cardano.AccountInfo{
+				StakeAddress:     stakeAddr,
+				Registered:       kinfo.Status == "registered",
+				TotalBalance:     kinfo.TotalBalance,
+				UTxO:             kinfo.UTxO,
+				Rewards:          kinfo.Rewards,
+				Withdrawals:      kinfo.Withdrawals,
+				RewardsAvailable: kinfo.RewardsAvailable,
+				Deposit:          kinfo.Deposit,
+			}
+			if kinfo.DelegatedPool != nil {
+				info.DelegatedPool = *kinfo.DelegatedPool
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+type koiosAddressTx struct {
+	TxHash string `json:"tx_hash"`
+}
+
+// AddressUsed reports whether an address is in a transaction.
+func (k *KoiosNode) AddressUsed(ctx context.Context, addr cardano.Address) (bool, error) {
+	params := struct {
+		Addresses []string `json:"_addresses"`
+	}{Addresses: []string{addr.Bech32()}}
+
+	var txs []koiosAddressTx
+	if err := k.post(ctx, "/address_txs?limit=1", params, &txs); err != nil {
+		return false, err
+	}
+	return len(txs) > 0, nil
+}
+
+type koiosTxStatus struct {
+	TxHash           string  `json:"tx_hash"`
+	NumConfirmations *uint64 `json:"num_confirmations"`
+}
+
+// TxStatus returns the confirmation status of a transaction.
+func (k *KoiosNode) TxStatus(ctx context.Context, txHash cardano.Hash32) (*cardano.TxStatus, error) {
+	statuses, err := k.TxStatuses(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return &cardano.TxStatus{TxHash: txHash}, nil
+	}
+	return &statuses[0], nil
+}
+
+// TxStatuses returns the confirmation status of many transactions.
+func (k *KoiosNode) TxStatuses(ctx context.Context, txHashes ...cardano.Hash32) ([]cardano.TxStatus, error) {
+	statuses := []cardano.TxStatus{}
+	for _, chunk := range chunks(txHashes) {
+		params := struct {
+			TxHashes []string `json:"_tx_hashes"`
+		}{}
+		for _, txHash := range chunk {
+			params.TxHashes = append(params.TxHashes, txHash.String())
+		}
+
+		var kstatuses []koiosTxStatus
+		if err := k.post(ctx, "/tx_status", params, &kstatuses); err != nil {
+			return nil, err
+		}
+		for _, kstatus := range kstatuses {
+			txHash, err := cardano.NewHash32(kstatus.TxHash)
+			if err != nil {
+				return nil, err
+			}
+			status := cardano.TxStatus{TxHash: txHash}
+			if kstatus.NumConfirmations != nil {
+				status.Confirmed = true
+				status.Confirmations = *kstatus.NumConfirmations
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,342 @@
+package koios
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+const testTxID = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+
+func newTestAddress(t *testing.T, name string) cardano.Address {
+	t.Helper()
+	key := crypto.NewXPrvKeyFromEntropy([]byte(name), "")
+	payment, err := cardano.NewKeyCredential(key.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := cardano.NewEnterpriseAddress(cardano.Preprod, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// newTestNode returns a KoiosNode querying a stand-in serving the handlers.
+func newTestNode(t *testing.T, handlers map[string]http.HandlerFunc) *KoiosNode {
+	t.Helper()
+	mux := http.NewServeMux()
+	for path, handler := range handlers {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.Header.Get("Authorization"), "Bearer secret"; got != want {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"message":"invalid token"}`))
+				return
+			}
+			handler(w, r)
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewNode(cardano.Preprod, &Options{Server: server.URL, Token: "secret"})
+}
+
+func TestUTxOsBulk(t *testing.T) {
+	defer func(size int) { maxBulkSize = size }(maxBulkSize)
+	maxBulkSize = 2
+
+	addrs := []cardano.Address{newTestAddress(t, "alice"), newTestAddress(t, "bob"), newTestAddress(t, "carol")}
+	policyID := "b8012f4f1ab76a7b1a15e9bf5b24b6f5f7c3e6a9ad05e32e0f9cbb6a"
+	requests := 0
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"POST /address_utxos": func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			var params struct {
+				Addresses []string `json:"_addresses"`
+				Extended  bool     `json:"_extended"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil || !params.Extended {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.URL.Query().Get("offset") != "0" {
+				_, _ = w.Write([]byte("[]"))
+				return
+			}
+			var utxos []map[string]any
+			for i, addr := range params.Addresses {
+				utxos = append(utxos, map[string]any{
+					"tx_hash":  testTxID,
+					"tx_index": i,
+					"address":  addr,
+					"value":    "2000000",
+					"asset_list": []map[string]any{
+						{"policy_id": policyID, "asset_name": "746f6b656e", "quantity": "18446744073709551615"},
+					},
+					"datum_hash":       "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec",
+					"inline_datum":     map[string]any{"bytes": "d87980", "value": map[string]any{}},
+					"reference_script": nil,
+				})
+			}
+			_ = json.NewEncoder(w).Encode(utxos)
+		},
+	})
+
+	utxos, err := node.UTxOsBulk(context.Background(), addrs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("invalid number of requests: got %v want %v", got, want)
+	}
+	if got, want := len(utxos), 3; got != want {
+		t.Fatalf("invalid number of utxos: got %v want %v", got, want)
+	}
+	for i, utxo := range utxos {
+		if got, want := utxo.Spender.Bech32(), addrs[i].Bech32(); got != want {
+			t.Errorf("invalid spender: got %v want %v", got, want)
+		}
+		if got, want := utxo.Amount.Coin, cardano.Coin(2e6); got != want {
+			t.Errorf("invalid coin: got %v want %v", got, want)
+		}
+		policyHash, err := cardano.NewHash28(policyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		quantity := utxo.Amount.MultiAsset.Get(cardano.NewPolicyIDFromHash(policyHash)).Get(cardano.NewAssetName("token"))
+		if got, want := quantity, cardano.BigNum(18446744073709551615); got != want {
+			t.Errorf("invalid asset quantity: got %v want %v", got, want)
+		}
+		if got, want := utxo.Datum.String(), "d87980"; got != want {
+			t.Errorf("invalid inline datum: got %v want %v", got, want)
+		}
+	}
+}
+
+func TestTip(t *testing.T) {
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"GET /tip": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"hash":"b15b0a4f","epoch_no":120,"abs_slot":51000000,"epoch_slot":1000,"block_height":2100000,"block_time":1700000000}]`))
+		},
+	})
+
+	tip, err := node.Tip(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cardano.NodeTip{Block: 2100000, Epoch: 120, Slot: 51000000}
+	if *tip != want {
+		t.Errorf("invalid tip: got %+v want %+v", *tip, want)
+	}
+}
+
+func TestProtocolParams(t *testing.T) {
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"GET /epoch_params": func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.URL.Query().Get("order"), "epoch_no.desc"; got != want {
+				t.Errorf("invalid order: got %v want %v", got, want)
+			}
+			_, _ = w.Write([]byte(`[{"epoch_no":120,"min_fee_a":44,"min_fee_b":155381,"key_deposit":"2000000","coins_per_utxo_size":"4310","protocol_major":9,"protocol_minor":0}]`))
+		},
+	})
+
+	protocol, err := node.ProtocolParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol.MinFeeA != 44 || protocol.MinFeeB != 155381 || protocol.CoinsPerUTXOByte != 4310 {
+		t.Errorf("invalid fee parameters: got %+v", protocol)
+	}
+	if got, want := protocol.KeyDeposit, cardano.Coin(2e6); got != want {
+		t.Errorf("invalid key deposit: got %v want %v", got, want)
+	}
+	if got, want := protocol.ProtocolVersion.Major, uint(9); got != want {
+		t.Errorf("invalid protocol version: got %v want %v", got, want)
+	}
+}
+
+func TestSubmitTx(t *testing.T) {
+	key := crypto.NewXPrvKeyFromEntropy([]byte("alice"), "")
+	addr := newTestAddress(t, "alice")
+	txHash, err := cardano.NewHash32(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{MinFeeA: 44, MinFeeB: 155381, CoinsPerUTXOByte: 4310})
+	txBuilder.AddInputs(&cardano.TxInput{TxHash: txHash, Index: 0, Amount: cardano.NewValue(10e6), Spender: &addr})
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.Sign(key.PrvKey())
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reject := false
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"POST /submittx": func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("Content-Type") != "application/cbor" || string(body) != string(tx.Bytes()) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if reject {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte("transaction submit error ShelleyTxValidationError ValueNotConservedUTxO"))
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(wantHash.String())
+		},
+	})
+
+	got, err := node.SubmitTx(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != wantHash.String() {
+		t.Errorf("invalid tx hash: got %v want %v", got, wantHash)
+	}
+
+	reject = true
+	if _, err := node.SubmitTx(context.Background(), tx); err == nil || !strings.Contains(err.Error(), "ValueNotConservedUTxO") {
+		t.Errorf("invalid error: got %v", err)
+	}
+}
+
+func TestAccountInfo(t *testing.T) {
+	key := crypto.NewXPrvKeyFromEntropy([]byte("stake"), "")
+	stake, err := cardano.NewKeyCredential(key.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stakeAddr, err := cardano.NewStakeAddress(cardano.Preprod, stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"POST /account_info": func(w http.ResponseWriter, r *http.Request) {
+			var params struct {
+				StakeAddresses []string `json:"_stake_addresses"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil || len(params.StakeAddresses) != 1 || params.StakeAddresses[0] != stakeAddr.Bech32() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]any{{
+				"stake_address":     stakeAddr.Bech32(),
+				"status":            "registered",
+				"delegated_pool":    "pool1z5uqdk7dzdxaae5633fqfcu2eqzy3a3rgtuvy087fdld7yws0xt",
+				"total_balance":     "12000000",
+				"utxo":              "10000000",
+				"rewards":           "3000000",
+				"withdrawals":       "1000000",
+				"rewards_available": "2000000",
+				"deposit":           "2000000",
+			}})
+		},
+	})
+
+	info, err := node.AccountInfo(context.Background(), stakeAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Registered {
+		t.Error("account not registered")
+	}
+	if got, want := info.RewardsAvailable, cardano.Coin(2e6); got != want {
+		t.Errorf("invalid rewards available: got %v want %v", got, want)
+	}
+	if got, want := info.DelegatedPool, "pool1z5uqdk7dzdxaae5633fqfcu2eqzy3a3rgtuvy087fdld7yws0xt"; got != want {
+		t.Errorf("invalid delegated pool: got %v want %v", got, want)
+	}
+}
+
+func TestTxStatus(t *testing.T) {
+	confirmed, err := cardano.NewHash32(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := cardano.NewHash32("8f9b7a1e3d1c0b2a4f6e5d7c9b8a0f1e2d3c4b5a69788796a5b4c3d2e1f0a9b8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"POST /tx_status": func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"tx_hash": confirmed.String(), "num_confirmations": 12},
+				{"tx_hash": pending.String(), "num_confirmations": nil},
+			})
+		},
+	})
+
+	statuses, err := node.TxStatuses(context.Background(), confirmed, pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(statuses), 2; got != want {
+		t.Fatalf("invalid number of statuses: got %v want %v", got, want)
+	}
+	if !statuses[0].Confirmed || statuses[0].Confirmations != 12 {
+		t.Errorf("invalid confirmed status: got %+v", statuses[0])
+	}
+	if statuses[1].Confirmed {
+		t.Errorf("invalid pending status: got %+v", statuses[1])
+	}
+}
+
+func TestAddressUsed(t *testing.T) {
+	used, unused := newTestAddress(t, "used"), newTestAddress(t, "unused")
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"POST /address_txs": func(w http.ResponseWriter, r *http.Request) {
+			var params struct {
+				Addresses []string `json:"_addresses"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil || len(params.Addresses) != 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if params.Addresses[0] != used.Bech32() {
+				_, _ = w.Write([]byte("[]"))
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"tx_hash": testTxID, "epoch_no": 100, "block_height": 1000}})
+		},
+	})
+
+	for _, tc := range []struct {
+		addr cardano.Address
+		want bool
+	}{
+		{addr: used, want: true},
+		{addr: unused, want: false},
+	} {
+		got, err := node.AddressUsed(context.Background(), tc.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("invalid used %v: got %v want %v", tc.addr.Bech32(), got, tc.want)
+		}
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	node := newTestNode(t, map[string]http.HandlerFunc{
+		"GET /tip": func(w http.ResponseWriter, r *http.Request) {},
+	})
+	node.token = ""
+	if _, err := node.Tip(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("invalid error: got %v", err)
+	}
+}
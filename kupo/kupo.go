@@ -0,0 +1,301 @@
+// Package kupo queries the UTxOs, datums and scripts indexed by a Kupo server.
+//
+// A KupoNode serves the UTxOs from Kupo and delegates the submission, tip and
+// protocol parameters to another backend.
+package kupo
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// ErrNotFound is returned when Kupo has no match for a lookup.
+var ErrNotFound = fmt.Errorf("kupo: %w", cardano.ErrNotFound)
+
+// ClientOptions are the options of a Client.
+type ClientOptions struct {
+	// HTTPClient is the client used for the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// Client is a Kupo HTTP client.
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient returns a new Client for the Kupo server at url, e.g. http://localhost:1442.
+func NewClient(url string, opts *ClientOptions) *Client {
+	c := &Client{url: strings.TrimSuffix(url, "/"), httpClient: http.DefaultClient}
+	if opts != nil && opts.HTTPClient != nil {
+		c.httpClient = opts.HTTPClient
+	}
+	return c
+}
+
+type kupoValue struct {
+	Coins  cardano.Coin      `json:"coins"`
+	Assets map[string]uint64 `json:"assets"`
+}
+
+type kupoMatch struct {
+	TransactionID string    `json:"transaction_id"`
+	OutputIndex   uint64    `json:"output_index"`
+	Address       string    `json:"address"`
+	Value         kupoValue `json:"value"`
+	DatumHash     string    `json:"datum_hash"`
+	DatumType     string    `json:"datum_type"`
+	ScriptHash    string    `json:"script_hash"`
+}
+
+type kupoDatum struct {
+	Datum string `json:"datum"`
+}
+
+type kupoScript struct {
+	Language string `json:"language"`
+	Script   string `json:"script"`
+}
+
+type kupoError struct {
+	Hint string `json:"hint"`
+}
+
+// get decodes the response of a GET request into result. A null response
+// returns ErrNotFound.
+func (c *Client) get(ctx context.Context, path string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		httpErr := &cardano.HTTPError{StatusCode: resp.StatusCode, Message: string(body)}
+		var kerr kupoError
+		if err := json.Unmarshal(body, &kerr); err == nil && kerr.Hint != "" {
+			httpErr.Message = kerr.Hint
+		}
+		return fmt.Errorf("kupo: %w", httpErr)
+	}
+	if string(body) == "null" {
+		return ErrNotFound
+	}
+	return json.Unmarshal(body, result)
+}
+
+// Matches returns the unspent outputs matching the pattern. The inline datums
+// and reference scripts of the outputs are fetched with additional requests.
+func (c *Client) Matches(ctx context.Context, pattern Pattern) ([]cardano.UTxO, error) {
+	var matches []kupoMatch
+	if err := c.get(ctx, "/matches/"+string(pattern)+"?unspent", &matches); err != nil {
+		return nil, err
+	}
+
+	utxos := make([]cardano.UTxO, len(matches))
+	for i, match := range matches {
+		utxo, err := c.utxo(ctx, &match)
+		if err != nil {
+			return nil, err
+		}
+		utxos[i] = *utxo
+	}
+	return utxos, nil
+}
+
+// UTxOs returns the unspent outputs locked by an address.
+func (c *Client) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	return c.Matches(ctx, AddressPattern(addr))
+}
+
+// AddressUsed reports whether an address received an output, unspent or spent
+// since the start of the Kupo index.
+func (c *Client) AddressUsed(ctx context.Context, addr cardano.Address) (bool, error) {
+	var matches []kupoMatch
+	if err := c.get(ctx, "/matches/"+string(AddressPattern(addr)), &matches); err != nil {
+		return false, err
+	}
+	return len(matches) > 0, nil
+}
+
+// UTxOByRef returns the unspent output at index of a transaction, or ErrNotFound
+// if it is unknown or spent.
+func (c *Client) UTxOByRef(ctx context.Context, txHash cardano.Hash32, index uint64) (*cardano.UTxO, error) {
+	utxos, err := c.Matches(ctx, OutputReferencePattern(txHash, index))
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, ErrNotFound
+	}
+	return &utxos[0], nil
+}
+
+// DatumByHash returns the datum with the given hash, or ErrNotFound.
+func (c *Client) DatumByHash(ctx context.Context, datumHash cardano.Hash32) (cardano.PlutusData, error) {
+	var datum kupoDatum
+	if err := c.get(ctx, "/datums/"+datumHash.String(), &datum); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(datum.Datum)
+	if err != nil {
+		return nil, err
+	}
+	return cardano.PlutusData(data), nil
+}
+
+var plutusLanguages = map[string]cardano.ScriptHashNamespace{
+	"plutus:v1": cardano.PlutusScriptNamespace,
+	"plutus:v2": cardano.PlutusV2ScriptNamespace,
+	"plutus:v3": cardano.PlutusV3ScriptNamespace,
+}
+
+// ScriptByHash returns the script with the given hash, or ErrNotFound.
+func (c *Client) ScriptByHash(ctx context.Context, scriptHash cardano.Hash28) (*cardano.ScriptRef, error) {
+	var script kupoScript
+	if err := c.get(ctx, "/scripts/"+scriptHash.String(), &script); err != nil {
+		return nil, err
+	}
+	scriptBytes, err := hex.DecodeString(script.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	var scriptRef *cardano.ScriptRef
+	if script.Language == "native" {
+		var ns cardano.NativeScript
+		if err := ns.UnmarshalCBOR(scriptBytes); err != nil {
+			return nil, err
+		}
+		scriptRef = cardano.NewNativeScriptRef(ns)
+	} else {
+		version, ok := plutusLanguages[script.Language]
+		if !ok {
+			return nil, fmt.Errorf("kupo: unknown script language %v", script.Language)
+		}
+		scriptRef = cardano.NewPlutusScriptRef(version, scriptBytes)
+	}
+
+	hash, err := scriptRef.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if hash.String() != scriptHash.String() {
+		return nil, fmt.Errorf("kupo: script hash mismatch: got %v want %v", hash, scriptHash)
+	}
+	return scriptRef, nil
+}
+
+func (c *Client) utxo(ctx context.Context, match *kupoMatch) (*cardano.UTxO, error) {
+	txHash, err := cardano.NewHash32(match.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	spender, err := cardano.NewAddress(match.Address)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := match.Value.value()
+	if err != nil {
+		return nil, err
+	}
+	utxo := &cardano.UTxO{
+		TxHash:  txHash,
+		Spender: spender,
+		Amount:  amount,
+		Index:   match.OutputIndex,
+	}
+
+	if match.DatumHash != "" {
+		datumHash, err := cardano.NewHash32(match.DatumHash)
+		if err != nil {
+			return nil, err
+		}
+		if match.DatumType == "inline" {
+			if utxo.Datum, err = c.DatumByHash(ctx, datumHash); err != nil {
+				return nil, err
+			}
+		} else {
+			utxo.DatumHash = datumHash
+		}
+	}
+	if match.ScriptHash != "" {
+		scriptHash, err := cardano.NewHash28(match.ScriptHash)
+		if err != nil {
+			return nil, err
+		}
+		if utxo.ScriptRef, err = c.ScriptByHash(ctx, scriptHash); err != nil {
+			return nil, err
+		}
+	}
+	return utxo, nil
+}
+
+// value parses a Kupo value, with the assets keyed by policy id and hex
+// encoded asset name separated by a dot, or by policy id alone for an
+// empty asset name.
+func (v *kupoValue) value() (*cardano.Value, error) {
+	amount := cardano.NewValue(v.Coins)
+	for unit, quantity := range v.Assets {
+		policy, name, _ := strings.Cut(unit, ".")
+		policyHash, err := cardano.NewHash28(policy)
+		if err != nil {
+			return nil, err
+		}
+		nameBytes, err := hex.DecodeString(name)
+		if err != nil {
+			return nil, err
+		}
+		policyID := cardano.NewPolicyIDFromHash(policyHash)
+		assetName := cardano.NewAssetName(string(nameBytes))
+		if assets := amount.MultiAsset.Get(policyID); assets != nil {
+			assets.Set(assetName, cardano.BigNum(quantity))
+		} else {
+			amount.MultiAsset.Set(policyID, cardano.NewAssets().Set(assetName, cardano.BigNum(quantity)))
+		}
+	}
+	return amount, nil
+}
+
+// KupoNode implements Node using Kupo for the UTxOs and another backend for
+// the submission, tip and protocol parameters.
+type KupoNode struct {
+	cardano.Node
+	*Client
+}
+
+// check interface
+var (
+	_ cardano.Node               = (*KupoNode)(nil)
+	_ cardano.UTxOByRefNode      = (*KupoNode)(nil)
+	_ cardano.DatumByHashNode    = (*KupoNode)(nil)
+	_ cardano.ScriptByHashNode   = (*KupoNode)(nil)
+	_ cardano.AddressHistoryNode = (*KupoNode)(nil)
+)
+
+// NewNode returns a new instance of KupoNode querying the Kupo server at url
+// and delegating the rest to backend.
+func NewNode(url string, backend cardano.Node) *KupoNode {
+	return &KupoNode{Node: backend, Client: NewClient(url, nil)}
+}
+
+func (k *KupoNode) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	return k.Client.UTxOs(ctx, addr)
+}
@@ -0,0 +1,322 @@
+package kupo
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/emulator"
+)
+
+const testTxID = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+
+type fixture struct {
+	addr       cardano.Address
+	stake      cardano.StakeCredential
+	policyID   cardano.PolicyID
+	script     cardano.NativeScript
+	datum      cardano.PlutusData
+	datumHash  cardano.Hash32
+	scriptHash cardano.Hash28
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	stakeKey := crypto.NewXPrvKeyFromEntropy([]byte("stake"), "")
+	payment, err := cardano.NewKeyCredential(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stake, err := cardano.NewKeyCredential(stakeKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := cardano.NewBaseAddress(cardano.Testnet, payment, stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := cardano.NewScriptPubKey(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyID, err := cardano.NewPolicyID(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptHash, err := script.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	datum, err := cardano.NewPlutusData("d8799f182aff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fixture{
+		addr:       addr,
+		stake:      stake,
+		policyID:   policyID,
+		script:     script,
+		datum:      datum,
+		datumHash:  datum.Hash(),
+		scriptHash: scriptHash,
+	}
+}
+
+// newFakeServer returns a Kupo stand-in serving the fixture.
+func newFakeServer(t *testing.T, f *fixture) *httptest.Server {
+	t.Helper()
+	scriptBytes, err := f.script.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := []map[string]any{
+		{
+			"transaction_id": testTxID,
+			"output_index":   0,
+			"address":        f.addr.Bech32(),
+			"value":          map[string]any{"coins": 5000000},
+			"datum_hash":     nil,
+			"script_hash":    nil,
+		},
+		{
+			"transaction_id": testTxID,
+			"output_index":   1,
+			"address":        f.addr.Bech32(),
+			"value": map[string]any{
+				"coins": 2000000,
+				"assets": map[string]any{
+					f.policyID.String() + "." + hex.EncodeToString([]byte("token")): 42,
+					f.policyID.String(): 7,
+				},
+			},
+			"datum_hash":  f.datumHash.String(),
+			"datum_type":  "inline",
+			"script_hash": f.scriptHash.String(),
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/matches/", func(w http.ResponseWriter, r *http.Request) {
+		// the address history is queried without filter
+		if _, ok := r.URL.Query()["unspent"]; !ok && r.URL.RawQuery != "" {
+			t.Errorf("missing unspent query in %v", r.URL)
+		}
+		pattern := strings.TrimPrefix(r.URL.Path, "/matches/")
+		switch pattern {
+		case f.addr.Bech32(), string(StakeCredentialPattern(f.stake)):
+			_ = json.NewEncoder(w).Encode(matches)
+		case "1@" + testTxID:
+			_ = json.NewEncoder(w).Encode(matches[1:])
+		case "invalid":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"hint":"Invalid or incomplete filter query parameters!"}`))
+		default:
+			_, _ = w.Write([]byte("[]"))
+		}
+	})
+	mux.HandleFunc("/datums/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/datums/") != f.datumHash.String() {
+			_, _ = w.Write([]byte("null"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"datum": hex.EncodeToString(f.datum)})
+	})
+	mux.HandleFunc("/scripts/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/scripts/") != f.scriptHash.String() {
+			_, _ = w.Write([]byte("null"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"language": "native", "script": hex.EncodeToString(scriptBytes)})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMatches(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	client := NewClient(newFakeServer(t, f).URL, nil)
+
+	for _, pattern := range []Pattern{AddressPattern(f.addr), StakeCredentialPattern(f.stake)} {
+		utxos, err := client.Matches(ctx, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(utxos), 2; got != want {
+			t.Fatalf("invalid number of utxos: got %v want %v", got, want)
+		}
+		if got, want := utxos[0].Amount.Coin, cardano.Coin(5e6); got != want {
+			t.Errorf("invalid coin: got %v want %v", got, want)
+		}
+		if utxos[0].ScriptRef != nil || utxos[0].Datum != nil {
+			t.Errorf("unexpected datum or script: %+v", utxos[0])
+		}
+
+		assets := utxos[1].Amount.MultiAsset.Get(f.policyID)
+		if got, want := assets.Get(cardano.NewAssetName("token")), cardano.BigNum(42); got != want {
+			t.Errorf("invalid asset quantity: got %v want %v", got, want)
+		}
+		if got, want := assets.Get(cardano.NewAssetName("")), cardano.BigNum(7); got != want {
+			t.Errorf("invalid empty name asset quantity: got %v want %v", got, want)
+		}
+		if got, want := utxos[1].Datum.String(), f.datum.String(); got != want {
+			t.Errorf("invalid inline datum: got %v want %v", got, want)
+		}
+		if utxos[1].ScriptRef == nil {
+			t.Fatal("missing script ref")
+		}
+		if got, want := utxos[1].ScriptRef.NativeScript.Type, cardano.ScriptPubKey; got != want {
+			t.Errorf("invalid script type: got %v want %v", got, want)
+		}
+	}
+
+	if _, err := client.Matches(ctx, "invalid"); err == nil || !strings.Contains(err.Error(), "Invalid or incomplete") {
+		t.Errorf("invalid error: got %v", err)
+	}
+}
+
+func TestUTxOByRef(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	client := NewClient(newFakeServer(t, f).URL, nil)
+	txHash, err := cardano.NewHash32(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxo, err := client.UTxOByRef(ctx, txHash, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := utxo.Index, uint64(1); got != want {
+		t.Errorf("invalid index: got %v want %v", got, want)
+	}
+	if _, err := client.UTxOByRef(ctx, txHash, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestDatumAndScriptByHash(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	client := NewClient(newFakeServer(t, f).URL, nil)
+
+	datum, err := client.DatumByHash(ctx, f.datumHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := datum.Hash().String(), f.datumHash.String(); got != want {
+		t.Errorf("invalid datum hash: got %v want %v", got, want)
+	}
+	script, err := client.ScriptByHash(ctx, f.scriptHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptHash, err := script.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := scriptHash.String(), f.scriptHash.String(); got != want {
+		t.Errorf("invalid script hash: got %v want %v", got, want)
+	}
+
+	unknown := make(cardano.Hash32, 32)
+	if _, err := client.DatumByHash(ctx, unknown); !errors.Is(err, ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, ErrNotFound)
+	}
+	if _, err := client.ScriptByHash(ctx, make(cardano.Hash28, 28)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	f := newFixture(t)
+	txHash, err := cardano.NewHash32(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := f.policyID.String()
+	stake := f.stake.Hash().String()
+
+	testcases := []struct {
+		name    string
+		pattern Pattern
+		want    string
+	}{
+		{name: "Address", pattern: AddressPattern(f.addr), want: f.addr.Bech32()},
+		{name: "PaymentCredential", pattern: PaymentCredentialPattern(f.stake), want: stake + "/*"},
+		{name: "StakeCredential", pattern: StakeCredentialPattern(f.stake), want: "*/" + stake},
+		{name: "PolicyID", pattern: PolicyIDPattern(f.policyID), want: policy + ".*"},
+		{name: "Asset", pattern: AssetPattern(f.policyID, cardano.NewAssetName("token")), want: policy + ".746f6b656e"},
+		{name: "OutputReference", pattern: OutputReferencePattern(txHash, 3), want: "3@" + testTxID},
+		{name: "Transaction", pattern: TransactionPattern(txHash), want: "*@" + testTxID},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(tc.pattern); got != tc.want {
+				t.Errorf("invalid pattern: got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddressUsed(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	client := NewClient(newFakeServer(t, f).URL, nil)
+
+	used, err := client.AddressUsed(ctx, f.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !used {
+		t.Error("invalid used address: got unused")
+	}
+	unused, err := cardano.NewAddress("addr_test1vpu5vlrf4xkxv2qpwngf6cjhtw542ayty80v8dyr49rf5eg57c2qv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used, err = client.AddressUsed(ctx, unused); err != nil {
+		t.Fatal(err)
+	}
+	if used {
+		t.Error("invalid unused address: got used")
+	}
+}
+
+func TestKupoNode(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	backend := emulator.NewNode(cardano.Testnet, &cardano.ProtocolParams{MinFeeA: 44, MinFeeB: 155381})
+	backend.AdvanceSlots(42)
+	node := NewNode(newFakeServer(t, f).URL, backend)
+
+	utxos, err := node.UTxOs(ctx, f.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(utxos), 2; got != want {
+		t.Errorf("invalid number of utxos: got %v want %v", got, want)
+	}
+	tip, err := node.Tip(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tip.Slot, uint64(42); got != want {
+		t.Errorf("invalid tip slot: got %v want %v", got, want)
+	}
+	if got, want := node.Network(), cardano.Testnet; got != want {
+		t.Errorf("invalid network: got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package kupo
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// Pattern is a Kupo match pattern, selecting the outputs by address,
+// credential, asset or output reference.
+type Pattern string
+
+// MatchAll matches all the outputs.
+const MatchAll Pattern = "*"
+
+// AddressPattern matches the outputs locked by an address.
+func AddressPattern(addr cardano.Address) Pattern {
+	return Pattern(addr.Bech32())
+}
+
+// PaymentCredentialPattern matches the outputs whose address has the given
+// payment credential, whatever their stake credential.
+func PaymentCredentialPattern(cred cardano.StakeCredential) Pattern {
+	return Pattern(fmt.Sprintf("%v/*", cred.Hash()))
+}
+
+// StakeCredentialPattern matches the outputs whose address delegates to the
+// given stake credential, whatever their payment credential.
+func StakeCredentialPattern(cred cardano.StakeCredential) Pattern {
+	return Pattern(fmt.Sprintf("*/%v", cred.Hash()))
+}
+
+// PolicyIDPattern matches the outputs holding assets of the given policy.
+func PolicyIDPattern(policyID cardano.PolicyID) Pattern {
+	return Pattern(fmt.Sprintf("%v.*", policyID.String()))
+}
+
+// AssetPattern matches the outputs holding the given asset.
+func AssetPattern(policyID cardano.PolicyID, assetName cardano.AssetName) Pattern {
+	return Pattern(fmt.Sprintf("%v.%v", policyID.String(), hex.EncodeToString(assetName.Bytes())))
+}
+
+// OutputReferencePattern matches the output at index of a transaction.
+func OutputReferencePattern(txHash cardano.Hash32, index uint64) Pattern {
+	return Pattern(fmt.Sprintf("%v@%v", index, txHash))
+}
+
+// TransactionPattern matches all the outputs of a transaction.
+func TransactionPattern(txHash cardano.Hash32) Pattern {
+	return Pattern(fmt.Sprintf("*@%v", txHash))
+}
@@ -0,0 +1,352 @@
+package cardano
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// PredicateFailure is a phase-1 validation failure, named like the predicate
+// failure of the ledger rules.
+type PredicateFailure struct {
+	// Rule is the ledger rule, UTXO or UTXOW.
+	Rule string
+
+	// Name is the name of the predicate failure, e.g. ValueNotConservedUTxO.
+	Name string
+
+	// Message describes the failure.
+	Message string
+}
+
+func (f *PredicateFailure) Error() string {
+	if f.Message == "" {
+		return fmt.Sprintf("%v: %v", f.Rule, f.Name)
+	}
+	return fmt.Sprintf("%v: %v: %v", f.Rule, f.Name, f.Message)
+}
+
+// Is reports whether the target is a PredicateFailure with the same name,
+// so failures can be matched with errors.Is(err, ErrValueNotConservedUTxO).
+func (f *PredicateFailure) Is(target error) bool {
+	t, ok := target.(*PredicateFailure)
+	return ok && t.Name == f.Name
+}
+
+func (f *PredicateFailure) with(format string, args ...interface{}) *PredicateFailure {
+	return &PredicateFailure{Rule: f.Rule, Name: f.Name, Message: fmt.Sprintf(format, args...)}
+}
+
+// UTXO rule predicate failures.
+var (
+	ErrInputSetEmptyUTxO           = &PredicateFailure{Rule: "UTXO", Name: "InputSetEmptyUTxO"}
+	ErrBadInputsUTxO               = &PredicateFailure{Rule: "UTXO", Name: "BadInputsUTxO"}
+	ErrOutsideValidityIntervalUTxO = &PredicateFailure{Rule: "UTXO", Name: "OutsideValidityIntervalUTxO"}
+	ErrMaxTxSizeUTxO               = &PredicateFailure{Rule: "UTXO", Name: "MaxTxSizeUTxO"}
+	ErrFeeTooSmallUTxO             = &PredicateFailure{Rule: "UTXO", Name: "FeeTooSmallUTxO"}
+	ErrValueNotConservedUTxO       = &PredicateFailure{Rule: "UTXO", Name: "ValueNotConservedUTxO"}
+	ErrWrongNetwork                = &PredicateFailure{Rule: "UTXO", Name: "WrongNetwork"}
+	ErrWrongNetworkWithdrawal      = &PredicateFailure{Rule: "UTXO", Name: "WrongNetworkWithdrawal"}
+	ErrWrongNetworkInTxBody        = &PredicateFailure{Rule: "UTXO", Name: "WrongNetworkInTxBody"}
+	ErrOutputTooSmallUTxO          = &PredicateFailure{Rule: "UTXO", Name: "OutputTooSmallUTxO"}
+	ErrOutputTooBigUTxO            = &PredicateFailure{Rule: "UTXO", Name: "OutputTooBigUTxO"}
+	ErrExUnitsTooBigUTxO           = &PredicateFailure{Rule: "UTXO", Name: "ExUnitsTooBigUTxO"}
+	ErrNoCollateralInputs          = &PredicateFailure{Rule: "UTXO", Name: "NoCollateralInputs"}
+	ErrTooManyCollateralInputs     = &PredicateFailure{Rule: "UTXO", Name: "TooManyCollateralInputs"}
+	ErrInsufficientCollateral      = &PredicateFailure{Rule: "UTXO", Name: "InsufficientCollateral"}
+	ErrCollateralContainsNonADA    = &PredicateFailure{Rule: "UTXO", Name: "CollateralContainsNonADA"}
+)
+
+// UTXOW rule predicate failures.
+var (
+	ErrInvalidWitnessesUTXOW           = &PredicateFailure{Rule: "UTXOW", Name: "InvalidWitnessesUTXOW"}
+	ErrMissingVKeyWitnessesUTXOW       = &PredicateFailure{Rule: "UTXOW", Name: "MissingVKeyWitnessesUTXOW"}
+	ErrMissingScriptWitnessesUTXOW     = &PredicateFailure{Rule: "UTXOW", Name: "MissingScriptWitnessesUTXOW"}
+	ErrScriptWitnessNotValidatingUTXOW = &PredicateFailure{Rule: "UTXOW", Name: "ScriptWitnessNotValidatingUTXOW"}
+	ErrMissingTxBodyMetadataHash       = &PredicateFailure{Rule: "UTXOW", Name: "MissingTxBodyMetadataHash"}
+	ErrMissingTxMetadata               = &PredicateFailure{Rule: "UTXOW", Name: "MissingTxMetadata"}
+	ErrConflictingMetadataHash         = &PredicateFailure{Rule: "UTXOW", Name: "ConflictingMetadataHash"}
+)
+
+// LedgerState is the view of the ledger a transaction is validated against.
+type LedgerState struct {
+	Protocol *ProtocolParams
+	Network  Network
+
+	// Slot is the current slot.
+	Slot uint64
+
+	// UTxOs resolve the spent, collateral and reference inputs.
+	UTxOs []UTxO
+
+	// Pools are the registered stake pools, whose registration certificates
+	// update the pool parameters without a new deposit.
+	Pools []PoolKeyHash
+}
+
+// ValidateTx runs the phase-1 checks of the ledger UTXO and UTXOW rules:
+// inputs, validity interval, size, fee, value conservation including deposits,
+// refunds and withdrawals, outputs min coins and value size, network ids,
+// collateral, witnesses, native scripts and metadata hash.
+//
+// Only the registrations of pools not in Pools pay the pool deposit, and
+// deregistration refunds are assumed to be the current key deposit.
+// All the failures are returned joined, each of them is a *PredicateFailure
+// that can be matched using errors.Is.
+func (s *LedgerState) ValidateTx(tx *Tx) error {
+	var errs []error
+	body := &tx.Body
+	protocol := s.Protocol
+
+	resolved := make(map[string]UTxO, len(s.UTxOs))
+	for _, utxo := range s.UTxOs {
+		resolved[outRef(utxo.TxHash, utxo.Index)] = utxo
+	}
+	resolve := func(inputs []*TxInput) ([]UTxO, []string) {
+		utxos, bad := []UTxO{}, []string{}
+		for _, in := range inputs {
+			utxo, ok := resolved[in.outRef()]
+			if !ok {
+				bad = append(bad, in.outRef())
+				continue
+			}
+			utxos = append(utxos, utxo)
+		}
+		return utxos, bad
+	}
+
+	if len(body.Inputs) == 0 {
+		errs = append(errs, ErrInputSetEmptyUTxO.with("no inputs"))
+	}
+	collateral := make([]*TxInput, len(body.Collateral))
+	for i := range body.Collateral {
+		collateral[i] = &body.Collateral[i]
+	}
+	spent, badInputs := resolve(body.Inputs)
+	collateralUTxOs, badCollateral := resolve(collateral)
+	referenced, badReferences := resolve(body.ReferenceInputs)
+	if bad := concat(badInputs, badCollateral, badReferences); len(bad) != 0 {
+		errs = append(errs, ErrBadInputsUTxO.with("%v", bad))
+	}
+
+	if (body.ValidityIntervalStart != nil && s.Slot < *body.ValidityIntervalStart) ||
+		(body.TTL != nil && s.Slot >= *body.TTL) {
+		errs = append(errs, ErrOutsideValidityIntervalUTxO.with(
+			"slot %v outside of [%v, %v)", s.Slot, uint64OrNone(body.ValidityIntervalStart), uint64OrNone(body.TTL),
+		))
+	}
+
+	if size := uint(len(tx.Bytes())); protocol.MaxTxSize != 0 && size > protocol.MaxTxSize {
+		errs = append(errs, ErrMaxTxSizeUTxO.with("size %v, max %v", size, protocol.MaxTxSize))
+	}
+
+	minFee := MinTxFee(protocol, tx, refScriptsSizeOf(concat(spent, referenced)))
+	if body.Fee < minFee {
+		errs = append(errs, ErrFeeTooSmallUTxO.with("fee %v, min fee %v", body.Fee, minFee))
+	}
+
+	if len(badInputs) == 0 {
+		consumed, produced := s.balance(tx, spent)
+		if consumed.Cmp(produced) != 0 {
+			errs = append(errs, ErrValueNotConservedUTxO.with("consumed %v, produced %v", consumed, produced))
+		}
+	}
+
+	networkID := uint64(s.Network.ID())
+	for i, out := range body.Outputs {
+		if uint64(out.Address.Network.ID()) != networkID {
+			errs = append(errs, ErrWrongNetwork.with("output %v address %v", i, out.Address))
+		}
+		if minCoins := MinCoinsForTxOut(protocol, out); out.Amount.Coin < minCoins {
+			errs = append(errs, ErrOutputTooSmallUTxO.with("output %v coins %v, min coins %v", i, out.Amount.Coin, minCoins))
+		}
+		if protocol.MaxValueSize != 0 {
+			valueBytes, err := cborEnc.Marshal(out.Amount)
+			if err != nil {
+				return err
+			}
+			if size := uint(len(valueBytes)); size > protocol.MaxValueSize {
+				errs = append(errs, ErrOutputTooBigUTxO.with("output %v value size %v, max %v", i, size, protocol.MaxValueSize))
+			}
+		}
+	}
+	if body.Withdrawals != nil {
+		for _, rewardAccount := range body.Withdrawals.Keys() {
+			if uint64(rewardAccount.Network.ID()) != networkID {
+				errs = append(errs, ErrWrongNetworkWithdrawal.with("reward account %v", rewardAccount))
+			}
+		}
+	}
+	if body.NetworkID != nil && *body.NetworkID != networkID {
+		errs = append(errs, ErrWrongNetworkInTxBody.with("network id %v, want %v", *body.NetworkID, networkID))
+	}
+
+	if len(tx.WitnessSet.Redeemers) != 0 {
+		exUnits, maxExUnits := tx.WitnessSet.Redeemers.ExUnits(), protocol.MaxTxExUnits
+		if maxExUnits != (ExUnits{}) && (exUnits.Mem > maxExUnits.Mem || exUnits.Steps > maxExUnits.Steps) {
+			errs = append(errs, ErrExUnitsTooBigUTxO.with("%+v, max %+v", exUnits, maxExUnits))
+		}
+		errs = append(errs, s.validateCollateral(tx, collateralUTxOs)...)
+	}
+
+	errs = append(errs, validateWitnesses(tx)...)
+	if len(badInputs) == 0 && len(badCollateral) == 0 && len(badReferences) == 0 {
+		missing, err := tx.MissingWitnesses(s.UTxOs)
+		if err != nil {
+			return err
+		}
+		if keyHashes := concat(missing.PaymentKeyHashes, missing.StakeKeyHashes, missing.RequiredSigners); len(keyHashes) != 0 {
+			errs = append(errs, ErrMissingVKeyWitnessesUTXOW.with("%v", keyHashes))
+		}
+		if len(missing.Scripts) != 0 {
+			errs = append(errs, ErrMissingScriptWitnessesUTXOW.with("%v", missing.Scripts))
+		}
+		if len(missing.NativeScripts) != 0 {
+			errs = append(errs, ErrScriptWitnessNotValidatingUTXOW.with("%v", missing.NativeScripts))
+		}
+	}
+
+	if err := validateMetadataHash(tx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// balance returns the consumed and produced values of the transaction.
+func (s *LedgerState) balance(tx *Tx, spent []UTxO) (*Value, *Value) {
+	body := &tx.Body
+	consumed, produced := NewValue(0), NewValue(body.Fee)
+	for _, utxo := range spent {
+		consumed = consumed.Add(utxo.Amount)
+	}
+	for _, out := range body.Outputs {
+		produced = produced.Add(out.Amount)
+	}
+	if body.Withdrawals != nil {
+		consumed = consumed.Add(NewValue(body.Withdrawals.Total()))
+	}
+	if body.Mint != nil {
+		minted, burned := body.Mint.split()
+		consumed = consumed.Add(NewValueWithAssets(0, minted))
+		produced = produced.Add(NewValueWithAssets(0, burned))
+	}
+	pools := make(map[string]struct{}, len(s.Pools))
+	for _, pool := range s.Pools {
+		pools[pool.String()] = struct{}{}
+	}
+	for _, cert := range body.Certificates {
+		switch cert.Type {
+		case StakeRegistration:
+			produced.Coin += s.Protocol.KeyDeposit
+		case PoolRegistration:
+			if _, ok := pools[cert.Operator.String()]; !ok {
+				pools[cert.Operator.String()] = struct{}{}
+				produced.Coin += s.Protocol.PoolDeposit
+			}
+		case StakeDeregistration:
+			consumed.Coin += s.Protocol.KeyDeposit
+		}
+	}
+	return consumed, produced
+}
+
+func (s *LedgerState) validateCollateral(tx *Tx, collateral []UTxO) []error {
+	var errs []error
+	protocol := s.Protocol
+	count := uint(len(tx.Body.Collateral))
+	if count == 0 {
+		return []error{ErrNoCollateralInputs.with("redeemers without collateral")}
+	}
+	if protocol.MaxCollateralInputs != 0 && count > protocol.MaxCollateralInputs {
+		errs = append(errs, ErrTooManyCollateralInputs.with("%v, max %v", count, protocol.MaxCollateralInputs))
+	}
+
+	total := NewValue(0)
+	for _, utxo := range collateral {
+		total = total.Add(utxo.Amount)
+	}
+	if !total.OnlyCoin() && !NewValueWithAssets(0, total.MultiAsset).IsZero() {
+		errs = append(errs, ErrCollateralContainsNonADA.with("%v", total))
+	}
+	if uint64(total.Coin)*100 < uint64(tx.Body.Fee)*uint64(protocol.CollateralPercentage) {
+		errs = append(errs, ErrInsufficientCollateral.with(
+			"collateral %v, want %v%% of fee %v", total.Coin, protocol.CollateralPercentage, tx.Body.Fee,
+		))
+	}
+	return errs
+}
+
+// validateWitnesses returns an InvalidWitnessesUTXOW failure for each invalid signature.
+func validateWitnesses(tx *Tx) []error {
+	err := tx.VerifyWitnesses()
+	if err == nil {
+		return nil
+	}
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, ErrInvalidWitnessesUTXOW.with("%v", err))
+		}
+		return errs
+	}
+	return []error{ErrInvalidWitnessesUTXOW.with("%v", err)}
+}
+
+func validateMetadataHash(tx *Tx) error {
+	auxDataHash := tx.Body.AuxiliaryDataHash
+	switch {
+	case tx.AuxiliaryData == nil && auxDataHash == nil:
+		return nil
+	case tx.AuxiliaryData == nil:
+		return ErrMissingTxMetadata.with("body metadata hash %v", auxDataHash)
+	case auxDataHash == nil:
+		return ErrMissingTxBodyMetadataHash.with("metadata without body hash")
+	}
+	hash, err := tx.AuxiliaryData.Hash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, *auxDataHash) {
+		return ErrConflictingMetadataHash.with("body hash %v, metadata hash %v", auxDataHash, hash)
+	}
+	return nil
+}
+
+// refScriptsSizeOf returns the total size of the reference scripts held by
+// the UTxOs. A UTxO found more than once is counted once.
+func refScriptsSizeOf(utxos []UTxO) uint {
+	var size uint
+	seen := map[string]struct{}{}
+	for _, utxo := range utxos {
+		if utxo.ScriptRef == nil {
+			continue
+		}
+		key := outRef(utxo.TxHash, utxo.Index)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		script, err := utxo.ScriptRef.Bytes()
+		if err != nil {
+			continue
+		}
+		size += uint(len(script))
+	}
+	return size
+}
+
+func concat[T any](lists ...[]T) []T {
+	var result []T
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	return result
+}
+
+func uint64OrNone(u Uint64) string {
+	if u == nil {
+		return "-"
+	}
+	return fmt.Sprint(*u)
+}
@@ -0,0 +1,298 @@
+package cardano
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestLedgerValidateTx(t *testing.T) {
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	stakeKey := crypto.NewXPrvKeyFromEntropy([]byte("stake"), "")
+	payment, err := NewKeyCredential(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stake, err := NewKeyCredential(stakeKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := NewEnterpriseAddress(Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainnetRewardAccount, err := NewStakeAddress(Mainnet, stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Shelley metadata, a plain map, hashed as encoded.
+	shelleyMetadata, err := hex.DecodeString("a11902a2626869")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewardAccount, err := NewStakeAddress(Testnet, stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	poolRegistration := Certificate{
+		Type:          PoolRegistration,
+		Operator:      payment.KeyHash,
+		VrfKeyHash:    txHash,
+		Margin:        UnitInterval{P: 1, Q: 100},
+		RewardAccount: rewardAccount,
+	}
+	sum := blake2b.Sum256(shelleyMetadata)
+	metadataHash := Hash32(sum[:])
+
+	testcases := []struct {
+		name    string
+		build   func(txBuilder *TxBuilder)
+		setup   func(tx *Tx, state *LedgerState)
+		unsign  bool
+		wantErr error
+	}{
+		{
+			name: "Valid",
+		},
+		{
+			name: "ValidStakeRegistration",
+			build: func(txBuilder *TxBuilder) {
+				txBuilder.AddCertificate(Certificate{Type: StakeRegistration, StakeCredential: stake})
+			},
+		},
+		{
+			name: "PoolRegistrationDeposit",
+			build: func(txBuilder *TxBuilder) {
+				txBuilder.AddCertificate(poolRegistration)
+			},
+			wantErr: ErrValueNotConservedUTxO,
+		},
+		{
+			name: "ValidPoolReRegistration",
+			build: func(txBuilder *TxBuilder) {
+				txBuilder.AddCertificate(poolRegistration)
+			},
+			setup: func(tx *Tx, state *LedgerState) {
+				state.Pools = []PoolKeyHash{poolRegistration.Operator}
+			},
+		},
+		{
+			name: "InputSetEmpty",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Inputs = nil
+			},
+			wantErr: ErrInputSetEmptyUTxO,
+		},
+		{
+			name: "BadInputs",
+			setup: func(tx *Tx, state *LedgerState) {
+				state.UTxOs = nil
+			},
+			wantErr: ErrBadInputsUTxO,
+		},
+		{
+			name: "Expired",
+			setup: func(tx *Tx, state *LedgerState) {
+				state.Slot = 1000
+			},
+			wantErr: ErrOutsideValidityIntervalUTxO,
+		},
+		{
+			name: "MaxTxSize",
+			setup: func(tx *Tx, state *LedgerState) {
+				state.Protocol.MaxTxSize = 100
+			},
+			wantErr: ErrMaxTxSizeUTxO,
+		},
+		{
+			name: "FeeTooSmall",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Fee--
+				tx.Body.Outputs[1].Amount.Coin++
+			},
+			wantErr: ErrFeeTooSmallUTxO,
+		},
+		{
+			name: "ValueNotConserved",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Outputs[1].Amount.Coin++
+			},
+			wantErr: ErrValueNotConservedUTxO,
+		},
+		{
+			name: "UnpaidDeposit",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Certificates = []Certificate{{Type: StakeRegistration, StakeCredential: stake}}
+			},
+			wantErr: ErrValueNotConservedUTxO,
+		},
+		{
+			name: "WrongNetwork",
+			setup: func(tx *Tx, state *LedgerState) {
+				state.Network = Mainnet
+			},
+			wantErr: ErrWrongNetwork,
+		},
+		{
+			name: "WrongNetworkWithdrawal",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Withdrawals = NewWithdrawals().Set(mainnetRewardAccount, 1)
+			},
+			wantErr: ErrWrongNetworkWithdrawal,
+		},
+		{
+			name: "WrongNetworkInTxBody",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.NetworkID = NewUint64(1)
+			},
+			wantErr: ErrWrongNetworkInTxBody,
+		},
+		{
+			name: "OutputTooSmall",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Outputs[1].Amount.Coin += tx.Body.Outputs[0].Amount.Coin - 1000
+				tx.Body.Outputs[0].Amount.Coin = 1000
+			},
+			wantErr: ErrOutputTooSmallUTxO,
+		},
+		{
+			name: "OutputTooBig",
+			setup: func(tx *Tx, state *LedgerState) {
+				state.Protocol.MaxValueSize = 4
+			},
+			wantErr: ErrOutputTooBigUTxO,
+		},
+		{
+			name: "NoCollateral",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.WitnessSet.Redeemers = Redeemers{{Tag: RedeemerTagSpend, Data: PlutusData{0x00}}}
+			},
+			wantErr: ErrNoCollateralInputs,
+		},
+		{
+			name: "InsufficientCollateral",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.WitnessSet.Redeemers = Redeemers{{Tag: RedeemerTagSpend, Data: PlutusData{0x00}}}
+				tx.Body.Collateral = []TxInput{{TxHash: txHash, Index: 1}}
+				state.UTxOs = append(state.UTxOs, UTxO{TxHash: txHash, Index: 1, Spender: addr, Amount: NewValue(1000)})
+			},
+			wantErr: ErrInsufficientCollateral,
+		},
+		{
+			name:    "MissingVKeyWitnesses",
+			unsign:  true,
+			wantErr: ErrMissingVKeyWitnessesUTXOW,
+		},
+		{
+			name: "InvalidWitnesses",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.Fee++
+				tx.Body.Outputs[1].Amount.Coin--
+			},
+			unsign:  true,
+			wantErr: ErrInvalidWitnessesUTXOW,
+		},
+		{
+			name: "ScriptWitnessNotValidating",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.WitnessSet.Scripts = []NativeScript{{Type: ScriptInvalidBefore, IntervalValue: 600}}
+			},
+			wantErr: ErrScriptWitnessNotValidatingUTXOW,
+		},
+		{
+			name: "MissingTxMetadata",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.Body.AuxiliaryDataHash = &txHash
+			},
+			wantErr: ErrMissingTxMetadata,
+		},
+		{
+			name: "ValidShelleyMetadata",
+			build: func(txBuilder *TxBuilder) {
+				aux := &AuxiliaryData{}
+				if err := aux.UnmarshalCBOR(shelleyMetadata); err != nil {
+					t.Fatal(err)
+				}
+				txBuilder.AddAuxiliaryData(aux)
+			},
+			setup: func(tx *Tx, state *LedgerState) {
+				if got := *tx.Body.AuxiliaryDataHash; !bytes.Equal(got, metadataHash) {
+					t.Errorf("invalid metadata hash: got %v want %v", got, metadataHash)
+				}
+			},
+		},
+		{
+			name: "ConflictingMetadataHash",
+			setup: func(tx *Tx, state *LedgerState) {
+				tx.AuxiliaryData = &AuxiliaryData{Metadata: Metadata{674: "hi"}}
+				tx.Body.AuxiliaryDataHash = &metadataHash
+			},
+			wantErr: ErrConflictingMetadataHash,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			protocol := *babbageProtocol
+			protocol.KeyDeposit = 2e6
+			protocol.PoolDeposit = 5e6
+			protocol.MaxTxSize = 16384
+			protocol.MaxValueSize = 5000
+			protocol.CollateralPercentage = 150
+			protocol.MaxCollateralInputs = 3
+
+			txBuilder := NewTxBuilder(&protocol)
+			txBuilder.AddInputs(&TxInput{TxHash: txHash, Index: 0, Amount: NewValue(20e6), Spender: &addr})
+			txBuilder.AddOutputs(NewTxOutput(addr, NewValue(10e6)))
+			txBuilder.AddChangeIfNeeded(addr)
+			txBuilder.SetTTL(1000)
+			txBuilder.SetValidityIntervalStart(100)
+			txBuilder.Sign(paymentKey.PrvKey())
+			if tc.build != nil {
+				tc.build(txBuilder)
+			}
+			tx, err := txBuilder.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := &LedgerState{
+				Protocol: &protocol,
+				Network:  Testnet,
+				Slot:     500,
+				UTxOs:    []UTxO{{TxHash: txHash, Index: 0, Spender: addr, Amount: NewValue(20e6)}},
+			}
+			if tc.setup != nil {
+				tc.setup(tx, state)
+				if !tc.unsign {
+					witness, err := tx.Witness(paymentKey.PrvKey())
+					if err != nil {
+						t.Fatal(err)
+					}
+					tx.WitnessSet.VKeyWitnessSet = []VKeyWitness{witness}
+				}
+			} else if tc.unsign {
+				tx.WitnessSet.VKeyWitnessSet = nil
+			}
+
+			err = state.ValidateTx(tx)
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("invalid error: got %v want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
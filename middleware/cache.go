@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// CacheOptions are the options of a CacheNode.
+type CacheOptions struct {
+	// ProtocolParamsTTL is how long the protocol parameters are cached, 10
+	// minutes if zero. They are not cached if negative.
+	ProtocolParamsTTL time.Duration
+
+	// TipTTL is how long the tip is cached, 5 seconds if zero. It is not
+	// cached if negative.
+	TipTTL time.Duration
+}
+
+const (
+	defaultProtocolParamsTTL = 10 * time.Minute
+	defaultTipTTL            = 5 * time.Second
+)
+
+// CacheNode is a Node caching the protocol parameters and the tip. The values
+// cached are shared by the callers and must not be modified.
+type CacheNode struct {
+	*wrapper
+	node cardano.Node
+
+	protocolParams cached[*cardano.ProtocolParams]
+	tip            cached[*cardano.NodeTip]
+}
+
+// NewCacheNode returns a new CacheNode caching the responses of node.
+func NewCacheNode(node cardano.Node, opts *CacheOptions) *CacheNode {
+	c := &CacheNode{
+		node:           node,
+		protocolParams: cached[*cardano.ProtocolParams]{ttl: defaultProtocolParamsTTL},
+		tip:            cached[*cardano.NodeTip]{ttl: defaultTipTTL},
+	}
+	if opts != nil {
+		if opts.ProtocolParamsTTL != 0 {
+			c.protocolParams.ttl = opts.ProtocolParamsTTL
+		}
+		if opts.TipTTL != 0 {
+			c.tip.ttl = opts.TipTTL
+		}
+	}
+	c.wrapper = newWrapper(node, c.do)
+	return c
+}
+
+func (c *CacheNode) do(ctx context.Context, _ bool, request func(node cardano.Node) error) error {
+	return request(c.node)
+}
+
+func (c *CacheNode) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
+	return c.protocolParams.get(func() (*cardano.ProtocolParams, error) {
+		return c.node.ProtocolParams(ctx)
+	})
+}
+
+func (c *CacheNode) Tip(ctx context.Context) (*cardano.NodeTip, error) {
+	return c.tip.get(func() (*cardano.NodeTip, error) {
+		return c.node.Tip(ctx)
+	})
+}
+
+// Invalidate drops the values cached.
+func (c *CacheNode) Invalidate() {
+	c.protocolParams.invalidate()
+	c.tip.invalidate()
+}
+
+// cached is a value cached until it expires. The concurrent callers of an
+// expired value wait for a single fetch.
+type cached[T any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	value   T
+	expires time.Time
+}
+
+func (c *cached[T]) get(fetch func() (T, error)) (T, error) {
+	if c.ttl < 0 {
+		return fetch()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.expires) {
+		return c.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	c.value, c.expires = value, time.Now().Add(c.ttl)
+	return value, nil
+}
+
+func (c *cached[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires = time.Time{}
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// FailoverOptions are the options of a FailoverNode.
+type FailoverOptions struct {
+	// MaxFailures is the number of consecutive failures after which a backend
+	// is unhealthy, 3 if zero.
+	MaxFailures int
+
+	// Cooldown is how long an unhealthy backend is tried after the healthy
+	// ones, 30s if zero.
+	Cooldown time.Duration
+
+	// Retryable reports whether a failed request is sent to the next backend,
+	// IsRetryable if nil. The other errors are returned at once, as are the
+	// failed transaction submissions that may have reached the backend, see
+	// IsNotSent.
+	Retryable func(err error) bool
+}
+
+const (
+	defaultMaxFailures = 3
+	defaultCooldown    = 30 * time.Second
+)
+
+// BackendHealth is the health of a backend of a FailoverNode.
+type BackendHealth struct {
+	Node      cardano.Node
+	Healthy   bool
+	Failures  int   // consecutive failures
+	LastError error // last failure, nil after a success
+}
+
+// FailoverNode is a Node sending the requests to the first healthy backend of
+// an ordered list, and to the next ones when a request fails.
+//
+// A backend is unhealthy after consecutive failures, and is only tried after
+// the healthy backends until its cooldown is over. The node supports the
+// capabilities of any backend, and the backends not supporting a capability
+// are skipped for its requests.
+type FailoverNode struct {
+	*wrapper
+
+	maxFailures int
+	cooldown    time.Duration
+	retryable   func(err error) bool
+
+	mu       sync.Mutex
+	backends []*backend
+}
+
+type backend struct {
+	node      cardano.Node
+	failures  int
+	lastErr   error
+	downUntil time.Time
+}
+
+// NewFailoverNode returns a new FailoverNode over the backends, most preferred
+// first. It panics if there are no backends.
+func NewFailoverNode(backends []cardano.Node, opts *FailoverOptions) *FailoverNode {
+	if len(backends) == 0 {
+		panic("middleware: no backend to fail over")
+	}
+	f := &FailoverNode{
+		maxFailures: defaultMaxFailures,
+		cooldown:    defaultCooldown,
+		retryable:   IsRetryable,
+	}
+	if opts != nil {
+		if opts.MaxFailures > 0 {
+			f.maxFailures = opts.MaxFailures
+		}
+		if opts.Cooldown > 0 {
+			f.cooldown = opts.Cooldown
+		}
+		if opts.Retryable != nil {
+			f.retryable = opts.Retryable
+		}
+	}
+	for _, node := range backends {
+		f.backends = append(f.backends, &backend{node: node})
+	}
+	f.wrapper = &wrapper{network: backends[0].Network(), supports: f.supports, do: f.do}
+	return f
+}
+
+// Health returns the health of the backends, in order.
+func (f *FailoverNode) Health() []BackendHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	health := make([]BackendHealth, len(f.backends))
+	for i, b := range f.backends {
+		health[i] = BackendHealth{
+			Node:      b.node,
+			Healthy:   !now.Before(b.downUntil),
+			Failures:  b.failures,
+			LastError: b.lastErr,
+		}
+	}
+	return health
+}
+
+// supports reports whether a backend supports the capability.
+func (f *FailoverNode) supports(capability cardano.Capability) bool {
+	for _, b := range f.backends {
+		if cardano.Supports(b.node, capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// order returns the healthy backends followed by the unhealthy ones.
+func (f *FailoverNode) order() []*backend {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	var healthy, unhealthy []*backend
+	for _, b := range f.backends {
+		if now.Before(b.downUntil) {
+			unhealthy = append(unhealthy, b)
+		} else {
+			healthy = append(healthy, b)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (f *FailoverNode) do(ctx context.Context, idempotent bool, request func(node cardano.Node) error) error {
+	var errs []error
+	for _, b := range f.order() {
+		err := request(b.node)
+		switch {
+		case err == nil:
+			f.succeeded(b)
+			return nil
+		case ctx.Err() != nil:
+			return err
+		case errors.Is(err, errors.ErrUnsupported):
+		case f.retryable(err):
+			f.failed(b, err)
+			if !idempotent && !IsNotSent(err) {
+				return err
+			}
+		default:
+			// the backend answered
+			f.succeeded(b)
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("middleware: all backends failed: %w", errors.Join(errs...))
+}
+
+func (f *FailoverNode) succeeded(b *backend) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b.failures, b.lastErr, b.downUntil = 0, nil, time.Time{}
+}
+
+func (f *FailoverNode) failed(b *backend, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b.failures++
+	b.lastErr = err
+	if b.failures >= f.maxFailures {
+		b.downUntil = time.Now().Add(f.cooldown)
+	}
+}
@@ -0,0 +1,209 @@
+// Package middleware wraps a cardano.Node to retry the failed requests with
+// an exponential backoff, cache the protocol parameters and tip, limit the
+// rate of the requests, or fail over across several backends.
+//
+// The wrappers compose, e.g. to retry the requests to a rate limited backend:
+//
+//	node := middleware.NewRetryNode(middleware.NewRateLimitNode(backend, nil), nil)
+//
+// The wrappers implement the capability interfaces of the cardano package and
+// report the capabilities of the nodes wrapped with Supports, to be discovered
+// with cardano.Supports.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// IsRetryable reports whether err is a transient failure of a backend: a
+// network error, a 429 or 5xx HTTP response, or a closed connection.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var httpErr *cardano.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode >= 500 && httpErr.StatusCode != http.StatusNotImplemented
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// IsNotSent reports whether err is a failure of a request that did not reach
+// the backend: a refused connection or a 429 HTTP response. A transaction
+// submission failing with another retryable error may have been accepted, and
+// is neither retried nor failed over.
+func IsNotSent(err error) bool {
+	var httpErr *cardano.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// unsupported returns the error of a capability the node does not support.
+func unsupported(node cardano.Node, method string) error {
+	return fmt.Errorf("middleware: %T does not support %v: %w", node, method, errors.ErrUnsupported)
+}
+
+// wrapper implements cardano.Node and the capability interfaces, sending every
+// request through do. The capability requests to a node not supporting them
+// fail with an error matching errors.ErrUnsupported.
+type wrapper struct {
+	network cardano.Network
+
+	// supports reports whether the nodes wrapped support a capability.
+	supports func(capability cardano.Capability) bool
+
+	// do sends a request to a node. The requests that are not idempotent, the
+	// transaction submissions, are only sent again if IsNotSent.
+	do func(ctx context.Context, idempotent bool, request func(node cardano.Node) error) error
+}
+
+// newWrapper returns a wrapper of node sending the requests through do.
+func newWrapper(node cardano.Node, do func(ctx context.Context, idempotent bool, request func(node cardano.Node) error) error) *wrapper {
+	return &wrapper{
+		network: node.Network(),
+		supports: func(capability cardano.Capability) bool {
+			return cardano.Supports(node, capability)
+		},
+		do: do,
+	}
+}
+
+// check interface
+var (
+	_ cardano.Node               = (*wrapper)(nil)
+	_ cardano.CapabilityNode     = (*wrapper)(nil)
+	_ cardano.UTxOByRefNode      = (*wrapper)(nil)
+	_ cardano.TxStatusNode       = (*wrapper)(nil)
+	_ cardano.AccountInfoNode    = (*wrapper)(nil)
+	_ cardano.DatumByHashNode    = (*wrapper)(nil)
+	_ cardano.ScriptByHashNode   = (*wrapper)(nil)
+	_ cardano.EvaluateTxNode     = (*wrapper)(nil)
+	_ cardano.AddressHistoryNode = (*wrapper)(nil)
+)
+
+// call sends a request to a node through do and returns its result.
+func call[T any](ctx context.Context, n *wrapper, request func(node cardano.Node) (T, error)) (T, error) {
+	var result T
+	err := n.do(ctx, true, func(node cardano.Node) (err error) {
+		result, err = request(node)
+		return err
+	})
+	return result, err
+}
+
+func (n *wrapper) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	return call(ctx, n, func(node cardano.Node) ([]cardano.UTxO, error) {
+		return node.UTxOs(ctx, addr)
+	})
+}
+
+func (n *wrapper) Tip(ctx context.Context) (*cardano.NodeTip, error) {
+	return call(ctx, n, func(node cardano.Node) (*cardano.NodeTip, error) {
+		return node.Tip(ctx)
+	})
+}
+
+func (n *wrapper) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	var txHash *cardano.Hash32
+	err := n.do(ctx, false, func(node cardano.Node) (err error) {
+		txHash, err = node.SubmitTx(ctx, tx)
+		return err
+	})
+	return txHash, err
+}
+
+func (n *wrapper) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
+	return call(ctx, n, func(node cardano.Node) (*cardano.ProtocolParams, error) {
+		return node.ProtocolParams(ctx)
+	})
+}
+
+func (n *wrapper) Network() cardano.Network {
+	return n.network
+}
+
+// Supports reports whether the node wrapped supports the capability.
+func (n *wrapper) Supports(capability cardano.Capability) bool {
+	return n.supports(capability)
+}
+
+func (n *wrapper) UTxOByRef(ctx context.Context, txHash cardano.Hash32, index uint64) (*cardano.UTxO, error) {
+	return call(ctx, n, func(node cardano.Node) (*cardano.UTxO, error) {
+		if node, ok := node.(cardano.UTxOByRefNode); ok && cardano.Supports(node, cardano.CapabilityUTxOByRef) {
+			return node.UTxOByRef(ctx, txHash, index)
+		}
+		return nil, unsupported(node, "UTxOByRef")
+	})
+}
+
+func (n *wrapper) TxStatus(ctx context.Context, txHash cardano.Hash32) (*cardano.TxStatus, error) {
+	return call(ctx, n, func(node cardano.Node) (*cardano.TxStatus, error) {
+		if node, ok := node.(cardano.TxStatusNode); ok && cardano.Supports(node, cardano.CapabilityTxStatus) {
+			return node.TxStatus(ctx, txHash)
+		}
+		return nil, unsupported(node, "TxStatus")
+	})
+}
+
+func (n *wrapper) AccountInfo(ctx context.Context, stakeAddr cardano.Address) (*cardano.AccountInfo, error) {
+	return call(ctx, n, func(node cardano.Node) (*cardano.AccountInfo, error) {
+		if node, ok := node.(cardano.AccountInfoNode); ok && cardano.Supports(node, cardano.CapabilityAccountInfo) {
+			return node.AccountInfo(ctx, stakeAddr)
+		}
+		return nil, unsupported(node, "AccountInfo")
+	})
+}
+
+func (n *wrapper) DatumByHash(ctx context.Context, datumHash cardano.Hash32) (cardano.PlutusData, error) {
+	return call(ctx, n, func(node cardano.Node) (cardano.PlutusData, error) {
+		if node, ok := node.(cardano.DatumByHashNode); ok && cardano.Supports(node, cardano.CapabilityDatumByHash) {
+			return node.DatumByHash(ctx, datumHash)
+		}
+		return nil, unsupported(node, "DatumByHash")
+	})
+}
+
+func (n *wrapper) ScriptByHash(ctx context.Context, scriptHash cardano.Hash28) (*cardano.ScriptRef, error) {
+	return call(ctx, n, func(node cardano.Node) (*cardano.ScriptRef, error) {
+		if node, ok := node.(cardano.ScriptByHashNode); ok && cardano.Supports(node, cardano.CapabilityScriptByHash) {
+			return node.ScriptByHash(ctx, scriptHash)
+		}
+		return nil, unsupported(node, "ScriptByHash")
+	})
+}
+
+func (n *wrapper) EvaluateTx(ctx context.Context, tx *cardano.Tx) (cardano.Redeemers, error) {
+	return call(ctx, n, func(node cardano.Node) (cardano.Redeemers, error) {
+		if node, ok := node.(cardano.EvaluateTxNode); ok && cardano.Supports(node, cardano.CapabilityEvaluateTx) {
+			return node.EvaluateTx(ctx, tx)
+		}
+		return nil, unsupported(node, "EvaluateTx")
+	})
+}
+
+func (n *wrapper) AddressUsed(ctx context.Context, addr cardano.Address) (bool, error) {
+	return call(ctx, n, func(node cardano.Node) (bool, error) {
+		if node, ok := node.(cardano.AddressHistoryNode); ok && cardano.Supports(node, cardano.CapabilityAddressHistory) {
+			return node.AddressUsed(ctx, addr)
+		}
+		return false, unsupported(node, "AddressUsed")
+	})
+}
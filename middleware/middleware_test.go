@@ -0,0 +1,462 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// fakeNode is a Node failing its requests with scripted errors, the last
+// error being repeated.
+type fakeNode struct {
+	errs []error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (n *fakeNode) request() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls++
+	if len(n.errs) == 0 {
+		return nil
+	}
+	return n.errs[min(n.calls, len(n.errs))-1]
+}
+
+func (n *fakeNode) Calls() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls
+}
+
+func (n *fakeNode) UTxOs(context.Context, cardano.Address) ([]cardano.UTxO, error) {
+	return nil, n.request()
+}
+
+func (n *fakeNode) Tip(context.Context) (*cardano.NodeTip, error) {
+	if err := n.request(); err != nil {
+		return nil, err
+	}
+	return &cardano.NodeTip{Block: uint64(n.Calls())}, nil
+}
+
+func (n *fakeNode) SubmitTx(context.Context, *cardano.Tx) (*cardano.Hash32, error) {
+	return nil, n.request()
+}
+
+func (n *fakeNode) ProtocolParams(context.Context) (*cardano.ProtocolParams, error) {
+	if err := n.request(); err != nil {
+		return nil, err
+	}
+	return &cardano.ProtocolParams{MinFeeA: cardano.Coin(n.Calls())}, nil
+}
+
+func (n *fakeNode) Network() cardano.Network {
+	return cardano.Testnet
+}
+
+// statusNode is a fakeNode implementing cardano.TxStatusNode.
+type statusNode struct {
+	*fakeNode
+}
+
+func (n *statusNode) TxStatus(_ context.Context, txHash cardano.Hash32) (*cardano.TxStatus, error) {
+	if err := n.request(); err != nil {
+		return nil, err
+	}
+	return &cardano.TxStatus{TxHash: txHash, Confirmed: true}, nil
+}
+
+var (
+	errTooMany     = fmt.Errorf("test: %w", &cardano.HTTPError{StatusCode: http.StatusTooManyRequests})
+	errUnavailable = fmt.Errorf("test: %w", &cardano.HTTPError{StatusCode: http.StatusServiceUnavailable})
+	errBadRequest  = fmt.Errorf("test: %w", &cardano.HTTPError{StatusCode: http.StatusBadRequest})
+)
+
+func TestIsRetryable(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: errTooMany, want: true},
+		{err: errUnavailable, want: true},
+		{err: &cardano.HTTPError{StatusCode: http.StatusNotImplemented}, want: false},
+		{err: errBadRequest, want: false},
+		{err: cardano.ErrNotFound, want: false},
+		{err: fmt.Errorf("test: %w", io.ErrUnexpectedEOF), want: true},
+		{err: context.Canceled, want: false},
+		{err: errors.ErrUnsupported, want: false},
+	}
+
+	for _, tc := range testcases {
+		if got := IsRetryable(tc.err); got != tc.want {
+			t.Errorf("invalid retryable %v: got %v want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestIsNotSent(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want bool
+	}{
+		{err: errTooMany, want: true},
+		{err: fmt.Errorf("test: %w", syscall.ECONNREFUSED), want: true},
+		{err: errUnavailable, want: false},
+		{err: io.EOF, want: false},
+		{err: syscall.ECONNRESET, want: false},
+	}
+
+	for _, tc := range testcases {
+		if got := IsNotSent(tc.err); got != tc.want {
+			t.Errorf("invalid not sent %v: got %v want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRetryNode(t *testing.T) {
+	ctx := context.Background()
+	opts := &RetryOptions{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	testcases := []struct {
+		name      string
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "Success",
+			wantCalls: 1,
+		},
+		{
+			name:      "Transient",
+			errs:      []error{errTooMany, errUnavailable, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "Exhausted",
+			errs:      []error{errUnavailable},
+			wantErr:   errUnavailable,
+			wantCalls: 3,
+		},
+		{
+			name:      "Permanent",
+			errs:      []error{errBadRequest},
+			wantErr:   errBadRequest,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fakeNode{errs: tc.errs}
+			node := NewRetryNode(backend, opts)
+			_, err := node.UTxOs(ctx, cardano.Address{})
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("invalid error: got %v want %v", err, tc.wantErr)
+			}
+			if got := backend.Calls(); got != tc.wantCalls {
+				t.Errorf("invalid calls: got %v want %v", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryNodeSubmitTx(t *testing.T) {
+	ctx := context.Background()
+	opts := &RetryOptions{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	testcases := []struct {
+		name      string
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "NotSent",
+			errs:      []error{errTooMany, syscall.ECONNREFUSED, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "Unavailable",
+			errs:      []error{errUnavailable, nil},
+			wantErr:   errUnavailable,
+			wantCalls: 1,
+		},
+		{
+			name:      "Reset",
+			errs:      []error{syscall.ECONNRESET, nil},
+			wantErr:   syscall.ECONNRESET,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fakeNode{errs: tc.errs}
+			node := NewRetryNode(backend, opts)
+			_, err := node.SubmitTx(ctx, nil)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("invalid error: got %v want %v", err, tc.wantErr)
+			}
+			if got := backend.Calls(); got != tc.wantCalls {
+				t.Errorf("invalid calls: got %v want %v", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryNodeCancel(t *testing.T) {
+	backend := &fakeNode{errs: []error{errUnavailable}}
+	node := NewRetryNode(backend, &RetryOptions{InitialBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := node.Tip(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("invalid error: got %v want %v", err, context.DeadlineExceeded)
+	}
+	if got := backend.Calls(); got != 1 {
+		t.Errorf("invalid calls: got %v want %v", got, 1)
+	}
+}
+
+func TestCacheNode(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeNode{}
+	node := NewCacheNode(backend, &CacheOptions{TipTTL: 20 * time.Millisecond})
+
+	for range 3 {
+		pparams, err := node.ProtocolParams(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pparams.MinFeeA != 1 {
+			t.Fatalf("invalid protocol params: got %v want %v", pparams.MinFeeA, 1)
+		}
+	}
+
+	tip, err := node.Tip(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Block != 2 {
+		t.Fatalf("invalid tip: got %v want %v", tip.Block, 2)
+	}
+	time.Sleep(30 * time.Millisecond)
+	tip, err = node.Tip(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Block != 3 {
+		t.Fatalf("invalid tip after expiry: got %v want %v", tip.Block, 3)
+	}
+
+	node.Invalidate()
+	pparams, err := node.ProtocolParams(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pparams.MinFeeA != 4 {
+		t.Errorf("invalid protocol params after invalidate: got %v want %v", pparams.MinFeeA, 4)
+	}
+}
+
+func TestCacheNodeError(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeNode{errs: []error{errUnavailable, nil}}
+	node := NewCacheNode(backend, nil)
+
+	if _, err := node.ProtocolParams(ctx); !errors.Is(err, errUnavailable) {
+		t.Fatalf("invalid error: got %v want %v", err, errUnavailable)
+	}
+	if _, err := node.ProtocolParams(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.Calls(); got != 2 {
+		t.Errorf("invalid calls: got %v want %v", got, 2)
+	}
+}
+
+func TestRateLimitNode(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeNode{}
+	node := NewRateLimitNode(backend, &RateLimitOptions{Rate: 100, Burst: 2})
+
+	start := time.Now()
+	for range 6 {
+		if _, err := node.UTxOs(ctx, cardano.Address{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 requests at once, then 4 at 10ms intervals
+	if elapsed, want := time.Since(start), 35*time.Millisecond; elapsed < want {
+		t.Errorf("invalid elapsed time: got %v want at least %v", elapsed, want)
+	}
+}
+
+func TestRateLimitNodeCancel(t *testing.T) {
+	backend := &fakeNode{}
+	node := NewRateLimitNode(backend, &RateLimitOptions{Rate: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := node.Tip(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.Tip(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("invalid error: got %v want %v", err, context.DeadlineExceeded)
+	}
+	if got := backend.Calls(); got != 1 {
+		t.Errorf("invalid calls: got %v want %v", got, 1)
+	}
+}
+
+func TestFailoverNode(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeNode{errs: []error{errUnavailable}}
+	secondary := &fakeNode{}
+	node := NewFailoverNode([]cardano.Node{primary, secondary}, &FailoverOptions{MaxFailures: 2, Cooldown: time.Hour})
+
+	for range 3 {
+		if _, err := node.UTxOs(ctx, cardano.Address{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the primary is skipped after 2 failures
+	if primary.Calls() != 2 || secondary.Calls() != 3 {
+		t.Errorf("invalid calls: got primary %v secondary %v want primary %v secondary %v", primary.Calls(), secondary.Calls(), 2, 3)
+	}
+
+	health := node.Health()
+	if health[0].Healthy || health[0].Failures != 2 || !errors.Is(health[0].LastError, errUnavailable) {
+		t.Errorf("invalid primary health: got %+v", health[0])
+	}
+	if !health[1].Healthy || health[1].Failures != 0 || health[1].LastError != nil {
+		t.Errorf("invalid secondary health: got %+v", health[1])
+	}
+}
+
+func TestFailoverNodeErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Permanent", func(t *testing.T) {
+		primary := &fakeNode{errs: []error{errBadRequest}}
+		secondary := &fakeNode{}
+		node := NewFailoverNode([]cardano.Node{primary, secondary}, nil)
+		if _, err := node.SubmitTx(ctx, nil); !errors.Is(err, errBadRequest) {
+			t.Errorf("invalid error: got %v want %v", err, errBadRequest)
+		}
+		if got := secondary.Calls(); got != 0 {
+			t.Errorf("invalid secondary calls: got %v want %v", got, 0)
+		}
+	})
+
+	t.Run("SubmitTx", func(t *testing.T) {
+		primary := &fakeNode{errs: []error{errTooMany}}
+		secondary := &fakeNode{errs: []error{io.EOF}}
+		tertiary := &fakeNode{}
+		node := NewFailoverNode([]cardano.Node{primary, secondary, tertiary}, nil)
+		// the submission to the secondary may have been accepted
+		if _, err := node.SubmitTx(ctx, nil); !errors.Is(err, io.EOF) {
+			t.Errorf("invalid error: got %v want %v", err, io.EOF)
+		}
+		if got := tertiary.Calls(); got != 0 {
+			t.Errorf("invalid tertiary calls: got %v want %v", got, 0)
+		}
+		if got := node.Health()[1].Failures; got != 1 {
+			t.Errorf("invalid secondary failures: got %v want %v", got, 1)
+		}
+	})
+
+	t.Run("AllFailed", func(t *testing.T) {
+		primary := &fakeNode{errs: []error{errTooMany}}
+		secondary := &fakeNode{errs: []error{errUnavailable}}
+		node := NewFailoverNode([]cardano.Node{primary, secondary}, nil)
+		_, err := node.Tip(ctx)
+		if !errors.Is(err, errTooMany) || !errors.Is(err, errUnavailable) {
+			t.Errorf("invalid error: got %v want %v and %v", err, errTooMany, errUnavailable)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		primary := &fakeNode{}
+		secondary := &statusNode{&fakeNode{}}
+		node := NewFailoverNode([]cardano.Node{primary, secondary}, nil)
+		status, err := node.TxStatus(ctx, cardano.Hash32{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !status.Confirmed {
+			t.Errorf("invalid status: got %+v", status)
+		}
+		if _, err := node.UTxOByRef(ctx, cardano.Hash32{}, 0); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("invalid error: got %v want %v", err, errors.ErrUnsupported)
+		}
+		if !node.Health()[0].Healthy {
+			t.Errorf("invalid primary health: got unhealthy")
+		}
+	})
+}
+
+func TestWrapperUnsupported(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeNode{}
+	nodes := []cardano.Node{
+		NewRetryNode(backend, nil),
+		NewCacheNode(backend, nil),
+		NewRateLimitNode(backend, nil),
+	}
+
+	for _, node := range nodes {
+		_, err := node.(cardano.EvaluateTxNode).EvaluateTx(ctx, nil)
+		if !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("invalid error of %T: got %v want %v", node, err, errors.ErrUnsupported)
+		}
+	}
+	if got := backend.Calls(); got != 0 {
+		t.Errorf("invalid calls: got %v want %v", got, 0)
+	}
+}
+
+func TestWrapperSupports(t *testing.T) {
+	backend := &fakeNode{}
+	status := &statusNode{&fakeNode{}}
+
+	testcases := []struct {
+		name string
+		node cardano.Node
+		want bool
+	}{
+		{name: "Retry", node: NewRetryNode(backend, nil), want: false},
+		{name: "RetryStatus", node: NewRetryNode(status, nil), want: true},
+		{name: "Cache", node: NewCacheNode(backend, nil), want: false},
+		{name: "RateLimit", node: NewRateLimitNode(status, nil), want: true},
+		{name: "Nested", node: NewRetryNode(NewRateLimitNode(backend, nil), nil), want: false},
+		{name: "NestedStatus", node: NewRetryNode(NewRateLimitNode(status, nil), nil), want: true},
+		{name: "Failover", node: NewFailoverNode([]cardano.Node{backend, backend}, nil), want: false},
+		{name: "FailoverStatus", node: NewFailoverNode([]cardano.Node{backend, status}, nil), want: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cardano.Supports(tc.node, cardano.CapabilityTxStatus); got != tc.want {
+				t.Errorf("invalid supports: got %v want %v", got, tc.want)
+			}
+			if cardano.Supports(tc.node, cardano.CapabilityEvaluateTx) {
+				t.Error("invalid supports: got true want false")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// RateLimitOptions are the options of a RateLimitNode.
+type RateLimitOptions struct {
+	// Rate is the number of requests per second, 10 if zero.
+	Rate float64
+
+	// Burst is the number of requests sent at once after a pause, the rate
+	// rounded up if zero.
+	Burst int
+}
+
+const defaultRate = 10
+
+// RateLimitNode is a Node limiting the rate of the requests with a token
+// bucket: the bucket holds up to Burst tokens, refilled at Rate tokens per
+// second, and each request waits for a token.
+type RateLimitNode struct {
+	*wrapper
+	node cardano.Node
+
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitNode returns a new RateLimitNode limiting the rate of the
+// requests to node.
+func NewRateLimitNode(node cardano.Node, opts *RateLimitOptions) *RateLimitNode {
+	r := &RateLimitNode{node: node, rate: defaultRate}
+	if opts != nil {
+		if opts.Rate > 0 {
+			r.rate = opts.Rate
+		}
+		if opts.Burst > 0 {
+			r.burst = float64(opts.Burst)
+		}
+	}
+	if r.burst == 0 {
+		r.burst = float64(int(r.rate + 0.999999))
+	}
+	r.tokens = r.burst
+	r.wrapper = newWrapper(node, r.do)
+	return r
+}
+
+func (r *RateLimitNode) do(ctx context.Context, _ bool, request func(node cardano.Node) error) error {
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+	return request(r.node)
+}
+
+// wait takes a token from the bucket, waiting for it to be refilled if empty.
+func (r *RateLimitNode) wait(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	if !r.last.IsZero() {
+		r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	}
+	r.last = now
+	// the token is reserved, the bucket going negative while waiting
+	r.tokens--
+	delay := time.Duration(-r.tokens / r.rate * float64(time.Second))
+	r.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, delay); err != nil {
+		r.mu.Lock()
+		r.tokens++
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
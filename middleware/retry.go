@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// RetryOptions are the options of a RetryNode.
+type RetryOptions struct {
+	// MaxAttempts is the number of attempts of a request, 5 if zero.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry, 500ms if zero. The
+	// delay doubles at each retry, with a random jitter of up to a half.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay before a retry, 30s if zero.
+	MaxBackoff time.Duration
+
+	// Retryable reports whether a failed request is retried, IsRetryable if nil.
+	Retryable func(err error) bool
+}
+
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// RetryNode is a Node retrying the failed requests with an exponential backoff.
+// The transactions are only submitted again if the failed submission did not
+// reach the backend, see IsNotSent.
+type RetryNode struct {
+	*wrapper
+	node cardano.Node
+
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retryable      func(err error) bool
+}
+
+// NewRetryNode returns a new RetryNode retrying the requests to node.
+func NewRetryNode(node cardano.Node, opts *RetryOptions) *RetryNode {
+	r := &RetryNode{
+		node:           node,
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		retryable:      IsRetryable,
+	}
+	if opts != nil {
+		if opts.MaxAttempts > 0 {
+			r.maxAttempts = opts.MaxAttempts
+		}
+		if opts.InitialBackoff > 0 {
+			r.initialBackoff = opts.InitialBackoff
+		}
+		if opts.MaxBackoff > 0 {
+			r.maxBackoff = opts.MaxBackoff
+		}
+		if opts.Retryable != nil {
+			r.retryable = opts.Retryable
+		}
+	}
+	r.wrapper = newWrapper(node, r.do)
+	return r
+}
+
+func (r *RetryNode) do(ctx context.Context, idempotent bool, request func(node cardano.Node) error) error {
+	backoff := r.initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := request(r.node)
+		if err == nil || attempt >= r.maxAttempts || !r.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if !idempotent && !IsNotSent(err) {
+			return err
+		}
+
+		delay := backoff/2 + rand.N(backoff/2+1)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		backoff = min(2*backoff, r.maxBackoff)
+	}
+}
+
+// sleep waits for the delay or until the context is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package cardano
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NetworkConfig describes a Cardano network, as configured by the genesis files.
+type NetworkConfig struct {
+	// Network is the preset matching the protocol magic, Testnet for unknown testnets.
+	Network       Network
+	NetworkID     byte
+	ProtocolMagic uint32
+	SystemStart   time.Time
+	SlotLength    time.Duration
+	EpochLength   uint64
+	SlotConfig    *SlotConfig
+
+	// ProtocolParams are the initial protocol parameters defined by the genesis files.
+	ProtocolParams *ProtocolParams
+}
+
+// NewNetworkConfig returns the network configuration described by the genesis files.
+// The Byron, Alonzo and Conway genesis are optional. Without Byron genesis the chain
+// is assumed to start in the Shelley era. The epoch of the transition to Shelley is
+// required when the Byron genesis is provided.
+func NewNetworkConfig(
+	byron *ByronGenesis,
+	shelley *ShelleyGenesis,
+	alonzo *AlonzoGenesis,
+	conway *ConwayGenesis,
+	shelleyStartEpoch uint64,
+) (*NetworkConfig, error) {
+	if shelley == nil {
+		return nil, errors.New("shelley genesis is required")
+	}
+
+	var slotConfig *SlotConfig
+	if byron != nil {
+		var err error
+		slotConfig, err = NewSlotConfigFromGenesis(byron, shelley, shelleyStartEpoch)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if shelley.EpochLength == 0 || shelley.SlotLengthDuration() <= 0 {
+			return nil, errors.New("slot and epoch lengths must be positive")
+		}
+		slotConfig = &SlotConfig{Eras: []EraSummary{{
+			StartTime:   shelley.SystemStart,
+			SlotLength:  shelley.SlotLengthDuration(),
+			EpochLength: shelley.EpochLength,
+		}}}
+	}
+
+	network := shelley.Network()
+	switch shelley.NetworkMagic {
+	case PreprodProtocolMagic:
+		network = Preprod
+	case PreviewProtocolMagic:
+		network = Preview
+	}
+
+	return &NetworkConfig{
+		Network:        network,
+		NetworkID:      network.ID(),
+		ProtocolMagic:  shelley.NetworkMagic,
+		SystemStart:    slotConfig.SystemStart(),
+		SlotLength:     shelley.SlotLengthDuration(),
+		EpochLength:    shelley.EpochLength,
+		SlotConfig:     slotConfig,
+		ProtocolParams: genesisProtocolParams(shelley, alonzo, conway),
+	}, nil
+}
+
+// LoadNetworkConfig loads the network configuration from a cardano-node configuration
+// file and the genesis files it references. Relative genesis paths are resolved from
+// the directory of the configuration file.
+func LoadNetworkConfig(nodeConfigPath string) (*NetworkConfig, error) {
+	data, err := os.ReadFile(nodeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseNodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if config.ShelleyGenesisFile == "" {
+		return nil, errors.New("node config does not reference a shelley genesis")
+	}
+
+	dir := filepath.Dir(nodeConfigPath)
+	readGenesis := func(path string) ([]byte, error) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return os.ReadFile(path)
+	}
+
+	data, err = readGenesis(config.ShelleyGenesisFile)
+	if err != nil {
+		return nil, err
+	}
+	shelley, err := ParseShelleyGenesis(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var byron *ByronGenesis
+	var shelleyStartEpoch uint64
+	if config.ByronGenesisFile != "" {
+		data, err := readGenesis(config.ByronGenesisFile)
+		if err != nil {
+			return nil, err
+		}
+		if byron, err = ParseByronGenesis(data); err != nil {
+			return nil, err
+		}
+		if shelleyStartEpoch, err = config.shelleyStartEpoch(shelley.NetworkMagic); err != nil {
+			return nil, err
+		}
+	}
+
+	var alonzo *AlonzoGenesis
+	if config.AlonzoGenesisFile != "" {
+		data, err := readGenesis(config.AlonzoGenesisFile)
+		if err != nil {
+			return nil, err
+		}
+		if alonzo, err = ParseAlonzoGenesis(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var conway *ConwayGenesis
+	if config.ConwayGenesisFile != "" {
+		data, err := readGenesis(config.ConwayGenesisFile)
+		if err != nil {
+			return nil, err
+		}
+		if conway, err = ParseConwayGenesis(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewNetworkConfig(byron, shelley, alonzo, conway, shelleyStartEpoch)
+}
+
+// shelleyStartEpoch returns the epoch of the transition to Shelley, either forced by
+// the node configuration or known for the public networks.
+func (c *NodeConfig) shelleyStartEpoch(protocolMagic uint32) (uint64, error) {
+	if c.TestShelleyHardForkAtEpoch != nil {
+		return *c.TestShelleyHardForkAtEpoch, nil
+	}
+	switch protocolMagic {
+	case MainnetProtocolMagic:
+		return MainnetSlotConfig.Eras[1].StartEpoch, nil
+	case PreprodProtocolMagic:
+		return PreprodSlotConfig.Eras[1].StartEpoch, nil
+	case PreviewProtocolMagic:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unknown shelley hard fork epoch for protocol magic %v", protocolMagic)
+}
+
+// genesisProtocolParams returns the protocol parameters defined by the genesis files.
+func genesisProtocolParams(shelley *ShelleyGenesis, alonzo *AlonzoGenesis, conway *ConwayGenesis) *ProtocolParams {
+	pp := shelley.ProtocolParams
+	params := &ProtocolParams{
+		MinFeeA:             pp.MinFeeA,
+		MinFeeB:             pp.MinFeeB,
+		MaxBlockBodySize:    pp.MaxBlockBodySize,
+		MaxTxSize:           pp.MaxTxSize,
+		MaxBlockHeaderSize:  pp.MaxBlockHeaderSize,
+		KeyDeposit:          pp.KeyDeposit,
+		PoolDeposit:         pp.PoolDeposit,
+		MaxEpoch:            pp.EMax,
+		NOpt:                pp.NOpt,
+		PoolPledgeInfluence: pp.A0,
+		ExpansionRate:       pp.Rho,
+		TreasuryGrowthRate:  pp.Tau,
+		D:                   pp.D,
+		MinPoolCost:         pp.MinPoolCost,
+		ProtocolVersion: ProtocolVersion{
+			Major: pp.ProtocolVersion.Major,
+			Minor: pp.ProtocolVersion.Minor,
+		},
+	}
+	if alonzo != nil {
+		params.CoinsPerUTXOWord = alonzo.LovelacePerUTxOWord
+		params.ExecutionCosts = alonzo.ExUnitPrices()
+		params.MaxTxExUnits = alonzo.MaxTxExUnits.exUnits()
+		params.MaxBlockTxExUnits = alonzo.MaxBlockExUnits.exUnits()
+		params.MaxValueSize = alonzo.MaxValueSize
+		params.CollateralPercentage = alonzo.CollateralPercentage
+		params.MaxCollateralInputs = alonzo.MaxCollateralInputs
+		params.CostModels = alonzo.CostModels
+	}
+	if conway != nil {
+		params.PoolVotingThresholds = conway.PoolVotingThresholds
+		params.DRepVotingThresholds = conway.DRepVotingThresholds
+		params.CommitteeMinSize = uint(conway.CommitteeMinSize)
+		params.CommitteeMaxTermLength = conway.CommitteeMaxTermLength
+		params.GovActionLifetime = conway.GovActionLifetime
+		params.GovActionDeposit = conway.GovActionDeposit
+		params.DRepDeposit = conway.DRepDeposit
+		params.DRepActivity = conway.DRepActivity
+		params.MinFeeRefScriptCostPerByte = conway.MinFeeRefScriptCostPerByte
+		if len(conway.PlutusV3CostModel) != 0 {
+			if params.CostModels == nil {
+				params.CostModels = CostModels{}
+			}
+			params.CostModels[PlutusV3] = conway.PlutusV3CostModel
+		}
+	}
+	return params
+}
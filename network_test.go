@@ -0,0 +1,195 @@
+package cardano
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	devnetShelleyGenesis = `{
+		"systemStart": "2024-01-01T00:00:00Z",
+		"networkMagic": 42,
+		"networkId": "Testnet",
+		"epochLength": 500,
+		"slotLength": 0.1,
+		"securityParam": 10,
+		"activeSlotsCoeff": 0.1,
+		"protocolParams": {
+			"minFeeA": 44,
+			"minFeeB": 155381,
+			"maxTxSize": 16384,
+			"keyDeposit": 2000000,
+			"poolDeposit": 500000000,
+			"a0": 0.3,
+			"rho": 0.003,
+			"tau": 0.2,
+			"decentralisationParam": 0,
+			"protocolVersion": {"major": 8, "minor": 0}
+		}
+	}`
+	devnetAlonzoGenesis = `{
+		"lovelacePerUTxOWord": 34482,
+		"executionPrices": {
+			"prSteps": {"numerator": 721, "denominator": 10000000},
+			"prMem": {"numerator": 577, "denominator": 10000}
+		},
+		"maxTxExUnits": {"exUnitsMem": 14000000, "exUnitsSteps": 10000000000},
+		"maxBlockExUnits": {"exUnitsMem": 62000000, "exUnitsSteps": 20000000000},
+		"maxValueSize": 5000,
+		"collateralPercentage": 150,
+		"maxCollateralInputs": 3
+	}`
+	devnetConwayGenesis = `{
+		"govActionLifetime": 6,
+		"govActionDeposit": 100000000000,
+		"dRepDeposit": 500000000,
+		"minFeeRefScriptCostPerByte": 15
+	}`
+)
+
+func TestLoadNetworkConfig(t *testing.T) {
+	testcases := []struct {
+		name       string
+		nodeConfig string
+		files      map[string]string
+		want       NetworkConfig
+		slot       uint64
+		slotTime   time.Time
+		wantErr    bool
+	}{
+		{
+			name: "Devnet",
+			nodeConfig: `{
+				"ShelleyGenesisFile": "shelley-genesis.json",
+				"AlonzoGenesisFile": "alonzo-genesis.json",
+				"ConwayGenesisFile": "conway-genesis.json",
+				"RequiresNetworkMagic": "RequiresMagic"
+			}`,
+			files: map[string]string{
+				"shelley-genesis.json": devnetShelleyGenesis,
+				"alonzo-genesis.json":  devnetAlonzoGenesis,
+				"conway-genesis.json":  devnetConwayGenesis,
+			},
+			want: NetworkConfig{
+				Network:       Testnet,
+				NetworkID:     0,
+				ProtocolMagic: 42,
+				SystemStart:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				SlotLength:    100 * time.Millisecond,
+				EpochLength:   500,
+			},
+			slot:     1200,
+			slotTime: time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC),
+		},
+		{
+			name: "Mainnet",
+			nodeConfig: `{
+				"ByronGenesisFile": "byron-genesis.json",
+				"ShelleyGenesisFile": "shelley-genesis.json"
+			}`,
+			files: map[string]string{
+				"byron-genesis.json":   mainnetByronGenesis,
+				"shelley-genesis.json": mainnetShelleyGenesis,
+			},
+			want: NetworkConfig{
+				Network:       Mainnet,
+				NetworkID:     1,
+				ProtocolMagic: MainnetProtocolMagic,
+				SystemStart:   time.Date(2017, 9, 23, 21, 44, 51, 0, time.UTC),
+				SlotLength:    time.Second,
+				EpochLength:   432000,
+			},
+			slot:     100000000,
+			slotTime: time.Unix(1591566291+100000000, 0).UTC(),
+		},
+		{
+			name: "UnknownShelleyHardFork",
+			nodeConfig: `{
+				"ByronGenesisFile": "byron-genesis.json",
+				"ShelleyGenesisFile": "shelley-genesis.json"
+			}`,
+			files: map[string]string{
+				"byron-genesis.json":   mainnetByronGenesis,
+				"shelley-genesis.json": devnetShelleyGenesis,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			configPath := filepath.Join(dir, "config.json")
+			if err := os.WriteFile(configPath, []byte(tc.nodeConfig), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadNetworkConfig(configPath)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected error")
+			}
+
+			if got.Network != tc.want.Network || got.NetworkID != tc.want.NetworkID ||
+				got.ProtocolMagic != tc.want.ProtocolMagic || !got.SystemStart.Equal(tc.want.SystemStart) ||
+				got.SlotLength != tc.want.SlotLength || got.EpochLength != tc.want.EpochLength {
+				t.Errorf("invalid network config: got %+v want %+v", got, tc.want)
+			}
+			if slotTime := got.SlotConfig.SlotToTime(tc.slot); !slotTime.Equal(tc.slotTime) {
+				t.Errorf("invalid slot time: got %v want %v", slotTime, tc.slotTime)
+			}
+		})
+	}
+}
+
+func TestGenesisProtocolParams(t *testing.T) {
+	shelley, err := ParseShelleyGenesis([]byte(devnetShelleyGenesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alonzo, err := ParseAlonzoGenesis([]byte(devnetAlonzoGenesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conway, err := ParseConwayGenesis([]byte(devnetConwayGenesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := NewNetworkConfig(nil, shelley, alonzo, conway, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := config.ProtocolParams
+	if got.MinFeeA != 44 || got.MinFeeB != 155381 || got.MaxTxSize != 16384 {
+		t.Errorf("invalid fee params: got %+v", got)
+	}
+	if got.ExpansionRate != (Rational{P: 3, Q: 1000}) || got.TreasuryGrowthRate != (Rational{P: 1, Q: 5}) {
+		t.Errorf("invalid monetary expansion params: got %+v %+v", got.ExpansionRate, got.TreasuryGrowthRate)
+	}
+	wantPrices := ExUnitPrices{MemPrice: Rational{P: 577, Q: 10000}, StepPrice: Rational{P: 721, Q: 10000000}}
+	if got.ExecutionCosts != wantPrices {
+		t.Errorf("invalid execution costs: got %+v want %+v", got.ExecutionCosts, wantPrices)
+	}
+	if want := (ExUnits{Mem: 14000000, Steps: 10000000000}); got.MaxTxExUnits != want {
+		t.Errorf("invalid max tx ex units: got %+v want %+v", got.MaxTxExUnits, want)
+	}
+	if want := (Rational{P: 15, Q: 1}); got.MinFeeRefScriptCostPerByte != want {
+		t.Errorf("invalid min fee ref script cost per byte: got %+v want %+v", got.MinFeeRefScriptCostPerByte, want)
+	}
+	if got.Era() != BabbageEra {
+		t.Errorf("invalid era: got %v want %v", got.Era(), BabbageEra)
+	}
+}
@@ -1,9 +1,19 @@
 package cardano
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 const (
+	// ProtocolMagic is the protocol magic of the legacy testnet.
 	ProtocolMagic = 1097911063
+
+	MainnetProtocolMagic = 764824073
+	PreprodProtocolMagic = 1
+	PreviewProtocolMagic = 2
 )
 
 // Node is the interface required for a Cardano backend/node.
@@ -31,3 +41,170 @@ type NodeTip struct {
 	Epoch uint64
 	Slot  uint64
 }
+
+// The capability interfaces below are implemented by the nodes supporting
+// more than the Node interface, discovered with Supports. The wrappers of a
+// node implement them all and report the capabilities of the node wrapped
+// with CapabilityNode.
+
+// Capability is a capability of a Node beyond the Node interface.
+type Capability int
+
+const (
+	// CapabilityUTxOByRef is the capability of an UTxOByRefNode.
+	CapabilityUTxOByRef Capability = iota
+	// CapabilityTxStatus is the capability of a TxStatusNode.
+	CapabilityTxStatus
+	// CapabilityAccountInfo is the capability of an AccountInfoNode.
+	CapabilityAccountInfo
+	// CapabilityDatumByHash is the capability of a DatumByHashNode.
+	CapabilityDatumByHash
+	// CapabilityScriptByHash is the capability of a ScriptByHashNode.
+	CapabilityScriptByHash
+	// CapabilityEvaluateTx is the capability of an EvaluateTxNode.
+	CapabilityEvaluateTx
+	// CapabilityAddressHistory is the capability of an AddressHistoryNode.
+	CapabilityAddressHistory
+)
+
+// CapabilityNode is a Node implementing capability interfaces it may not
+// support, such as a wrapper of another node.
+type CapabilityNode interface {
+	Node
+
+	// Supports reports whether the node supports the capability
+	Supports(capability Capability) bool
+}
+
+// Supports reports whether the node supports the capability: it implements
+// the capability interface and, if it is a CapabilityNode, reports supporting
+// it.
+func Supports(node Node, capability Capability) bool {
+	var ok bool
+	switch capability {
+	case CapabilityUTxOByRef:
+		_, ok = node.(UTxOByRefNode)
+	case CapabilityTxStatus:
+		_, ok = node.(TxStatusNode)
+	case CapabilityAccountInfo:
+		_, ok = node.(AccountInfoNode)
+	case CapabilityDatumByHash:
+		_, ok = node.(DatumByHashNode)
+	case CapabilityScriptByHash:
+		_, ok = node.(ScriptByHashNode)
+	case CapabilityEvaluateTx:
+		_, ok = node.(EvaluateTxNode)
+	case CapabilityAddressHistory:
+		_, ok = node.(AddressHistoryNode)
+	}
+	if !ok {
+		return false
+	}
+	if node, isCapabilityNode := node.(CapabilityNode); isCapabilityNode {
+		return node.Supports(capability)
+	}
+	return true
+}
+
+// ErrNotFound is returned, possibly wrapped, by the lookups of a Node when the
+// object looked up is unknown.
+var ErrNotFound = errors.New("not found")
+
+// HTTPError is an unsuccessful response of the HTTP API of a backend.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// UTxOByRefNode is a Node resolving an unspent output by its reference.
+type UTxOByRefNode interface {
+	Node
+
+	// UTxOByRef returns the unspent output at index of a transaction, or
+	// ErrNotFound if it is unknown or spent
+	UTxOByRef(ctx context.Context, txHash Hash32, index uint64) (*UTxO, error)
+}
+
+// TxStatus is the confirmation status of a transaction.
+type TxStatus struct {
+	TxHash Hash32
+
+	// Confirmed is false while the transaction is not in a block.
+	Confirmed     bool
+	Confirmations uint64
+
+	// Block and Slot are the number and slot of the block including the
+	// transaction, zero when unknown to the node.
+	Block uint64
+	Slot  uint64
+}
+
+// TxStatusNode is a Node reporting whether a transaction is in a block.
+type TxStatusNode interface {
+	Node
+
+	// TxStatus returns the confirmation status of a transaction, unconfirmed
+	// if the transaction is unknown
+	TxStatus(ctx context.Context, txHash Hash32) (*TxStatus, error)
+}
+
+// AccountInfo is the state of a stake address. The balances a node does not
+// track are left zero.
+type AccountInfo struct {
+	StakeAddress     Address
+	Registered       bool
+	DelegatedPool    string // bech32 pool id, or empty
+	TotalBalance     Coin
+	UTxO             Coin
+	Rewards          Coin
+	Withdrawals      Coin
+	RewardsAvailable Coin
+	Deposit          Coin
+}
+
+// AccountInfoNode is a Node reading the state of stake addresses.
+type AccountInfoNode interface {
+	Node
+
+	// AccountInfo returns the state of a stake address, unregistered if the
+	// stake address is unknown
+	AccountInfo(ctx context.Context, stakeAddr Address) (*AccountInfo, error)
+}
+
+// DatumByHashNode is a Node resolving datums by their hash.
+type DatumByHashNode interface {
+	Node
+
+	// DatumByHash returns the datum with the given hash, or ErrNotFound
+	DatumByHash(ctx context.Context, datumHash Hash32) (PlutusData, error)
+}
+
+// ScriptByHashNode is a Node resolving scripts by their hash.
+type ScriptByHashNode interface {
+	Node
+
+	// ScriptByHash returns the script with the given hash, or ErrNotFound
+	ScriptByHash(ctx context.Context, scriptHash Hash28) (*ScriptRef, error)
+}
+
+// EvaluateTxNode is a Node evaluating the scripts of transactions.
+type EvaluateTxNode interface {
+	Node
+
+	// EvaluateTx evaluates the scripts of a transaction and returns the
+	// execution units of its redeemers, only their Tag, Index and ExUnits set
+	EvaluateTx(ctx context.Context, tx *Tx) (Redeemers, error)
+}
+
+// AddressHistoryNode is a Node knowing the transaction history of addresses.
+type AddressHistoryNode interface {
+	Node
+
+	// AddressUsed reports whether an address received an output of a
+	// transaction, spent or not
+	AddressUsed(ctx context.Context, addr Address) (bool, error)
+}
@@ -0,0 +1,26 @@
+package cardano
+
+import "testing"
+
+func TestSupports(t *testing.T) {
+	tx := newBlockTestTx(t)
+	testcases := []struct {
+		name       string
+		node       Node
+		capability Capability
+		want       bool
+	}{
+		{name: "NotImplemented", node: &pollNode{tx: tx}, capability: CapabilityTxStatus, want: false},
+		{name: "Implemented", node: &statusNode{&pollNode{tx: tx}}, capability: CapabilityTxStatus, want: true},
+		{name: "NotSupported", node: &unsupportedNode{&pollNode{tx: tx}}, capability: CapabilityTxStatus, want: false},
+		{name: "NotImplementedCapabilityNode", node: &unsupportedNode{&pollNode{tx: tx}}, capability: CapabilityUTxOByRef, want: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Supports(tc.node, tc.capability); got != tc.want {
+				t.Errorf("invalid supports: got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package ogmios
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/chainsync"
+)
+
+// ErrIntersectionNotFound is returned by findIntersection when no point is on the chain.
+var ErrIntersectionNotFound = &Error{Code: 1000, Message: "intersection not found"}
+
+// ChainSync follows the chain over the chain synchronization of Ogmios. It
+// implements chainsync.Source.
+//
+// The transactions are decoded from their CBOR encoding, included in the
+// blocks only when Ogmios is started with --include-transaction-cbor. The
+// transactions of the Byron blocks are not decoded.
+type ChainSync struct {
+	node *OgmiosNode
+
+	mu         sync.Mutex
+	started    bool
+	generation uint64 // connection the intersection was found on
+}
+
+// check interface
+var _ chainsync.Source = (*ChainSync)(nil)
+
+// ChainSync returns a new chain-sync client of the node.
+func (o *OgmiosNode) ChainSync() *ChainSync {
+	return &ChainSync{node: o}
+}
+
+func newOgmiosPoint(point cardano.Point) any {
+	if point.IsOrigin() {
+		return origin
+	}
+	return &ogmiosPoint{Slot: point.Slot, ID: point.Hash.String()}
+}
+
+func parsePoint(data json.RawMessage) (cardano.Point, error) {
+	if string(data) == strconv.Quote(origin) {
+		return cardano.Point{}, nil
+	}
+	var point ogmiosPoint
+	if err := json.Unmarshal(data, &point); err != nil {
+		return cardano.Point{}, err
+	}
+	return cardano.NewPoint(point.Slot, point.ID)
+}
+
+func parseTip(data json.RawMessage) (chainsync.Tip, error) {
+	if string(data) == strconv.Quote(origin) {
+		return chainsync.Tip{}, nil
+	}
+	var tip struct {
+		ogmiosPoint
+		Height uint64 `json:"height"`
+	}
+	if err := json.Unmarshal(data, &tip); err != nil {
+		return chainsync.Tip{}, err
+	}
+	point, err := cardano.NewPoint(tip.Slot, tip.ID)
+	if err != nil {
+		return chainsync.Tip{}, err
+	}
+	return chainsync.Tip{Point: point, Block: tip.Height}, nil
+}
+
+func (cs *ChainSync) FindIntersect(ctx context.Context, points []cardano.Point) (cardano.Point, chainsync.Tip, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	params := map[string][]any{"points": {}}
+	for _, point := range points {
+		params["points"] = append(params["points"], newOgmiosPoint(point))
+	}
+	var result struct {
+		Intersection json.RawMessage `json:"intersection"`
+		Tip          json.RawMessage `json:"tip"`
+	}
+	if err := cs.node.client.call(ctx, "findIntersection", params, &result); err != nil {
+		if errors.Is(err, ErrIntersectionNotFound) {
+			return cardano.Point{}, chainsync.Tip{}, fmt.Errorf("%w: %w", chainsync.ErrNoIntersection, err)
+		}
+		return cardano.Point{}, chainsync.Tip{}, err
+	}
+	intersection, err := parsePoint(result.Intersection)
+	if err != nil {
+		return cardano.Point{}, chainsync.Tip{}, err
+	}
+	tip, err := parseTip(result.Tip)
+	if err != nil {
+		return cardano.Point{}, chainsync.Tip{}, err
+	}
+	cs.started, cs.generation = true, cs.node.client.connection()
+	return intersection, tip, nil
+}
+
+type ogmiosBlockTx struct {
+	ID   string `json:"id"`
+	CBOR string `json:"cbor"`
+}
+
+type ogmiosBlock struct {
+	Era          string          `json:"era"`
+	ID           string          `json:"id"`
+	Ancestor     string          `json:"ancestor"`
+	Height       uint64          `json:"height"`
+	Slot         uint64          `json:"slot"`
+	Transactions []ogmiosBlockTx `json:"transactions"`
+}
+
+var eras = map[string]cardano.Era{
+	"byron":   cardano.ByronEra,
+	"shelley": cardano.ShelleyEra,
+	"allegra": cardano.AllegraEra,
+	"mary":    cardano.MaryEra,
+	"alonzo":  cardano.AlonzoEra,
+	"babbage": cardano.BabbageEra,
+	"conway":  cardano.ConwayEra,
+}
+
+func (cs *ChainSync) Next(ctx context.Context) (*chainsync.Event, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if !cs.started {
+		return nil, errors.New("ogmios: chain sync not started, find an intersection first")
+	}
+	if cs.node.client.connection() != cs.generation {
+		return nil, fmt.Errorf("%w: chain sync interrupted", ErrClosed)
+	}
+
+	var result struct {
+		Direction string          `json:"direction"`
+		Block     *ogmiosBlock    `json:"block"`
+		Point     json.RawMessage `json:"point"`
+		Tip       json.RawMessage `json:"tip"`
+	}
+	err := cs.node.client.call(ctx, "nextBlock", nil, &result)
+	if cs.node.client.connection() != cs.generation {
+		// the request was sent on a new connection, from the origin
+		return nil, fmt.Errorf("%w: chain sync interrupted", ErrClosed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tip, err := parseTip(result.Tip)
+	if err != nil {
+		return nil, err
+	}
+	switch result.Direction {
+	case "forward":
+		if result.Block == nil {
+			return nil, errors.New("ogmios: missing block")
+		}
+		block, err := result.Block.block()
+		if err != nil {
+			return nil, err
+		}
+		return &chainsync.Event{Direction: chainsync.RollForward, Block: block, Tip: tip}, nil
+	case "backward":
+		point, err := parsePoint(result.Point)
+		if err != nil {
+			return nil, err
+		}
+		return &chainsync.Event{Direction: chainsync.RollBackward, Point: point, Tip: tip}, nil
+	default:
+		return nil, fmt.Errorf("ogmios: unknown direction %q", result.Direction)
+	}
+}
+
+func (b *ogmiosBlock) block() (*cardano.Block, error) {
+	era, ok := eras[b.Era]
+	if !ok {
+		return nil, fmt.Errorf("ogmios: unknown era %q", b.Era)
+	}
+	hash, err := cardano.NewHash32(b.ID)
+	if err != nil {
+		return nil, err
+	}
+	block := &cardano.Block{Era: era, Hash: hash, Number: b.Height, Slot: b.Slot}
+	if b.Ancestor != "genesis" {
+		if block.PrevHash, err = cardano.NewHash32(b.Ancestor); err != nil {
+			return nil, err
+		}
+	}
+	if era == cardano.ByronEra {
+		return block, nil
+	}
+
+	for _, tx := range b.Transactions {
+		if tx.CBOR == "" {
+			return nil, fmt.Errorf("ogmios: missing cbor of transaction %v, start ogmios with --include-transaction-cbor", tx.ID)
+		}
+		txBytes, err := hex.DecodeString(tx.CBOR)
+		if err != nil {
+			return nil, err
+		}
+		decoded := &cardano.Tx{}
+		if err := decoded.UnmarshalCBOR(txBytes); err != nil {
+			return nil, fmt.Errorf("ogmios: invalid transaction %v: %w", tx.ID, err)
+		}
+		txHash, err := cardano.NewHash32(tx.ID)
+		if err != nil {
+			return nil, err
+		}
+		block.Txs = append(block.Txs, decoded)
+		block.TxHashes = append(block.TxHashes, txHash)
+	}
+	return block, nil
+}
@@ -0,0 +1,173 @@
+// Package ogmios implements cardano.Node on top of the Ogmios v6 JSON-RPC
+// WebSocket API.
+package ogmios
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrClosed is returned for the requests pending when the connection was closed.
+var ErrClosed = errors.New("ogmios: connection closed")
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+	ID      uint64 `json:"id"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Result  json.RawMessage `json:"result"`
+	Error   *Error          `json:"error"`
+	ID      uint64          `json:"id"`
+
+	err error
+}
+
+// client is a JSON-RPC client multiplexing the requests over a single
+// WebSocket connection, dialed on first use and redialed after a failure.
+type client struct {
+	url    string
+	dialer *websocket.Dialer
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	nextID  uint64
+	pending map[uint64]chan *response
+
+	// generation counts the connections dialed, the state of the chain
+	// synchronization is lost with the connection.
+	generation uint64
+}
+
+func newClient(url string) *client {
+	return &client{url: url, dialer: websocket.DefaultDialer}
+}
+
+func (c *client) connect(ctx context.Context) (*websocket.Conn, error) {
+	if c.conn != nil {
+		return c.conn, nil
+	}
+	conn, _, err := c.dialer.DialContext(ctx, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ogmios: %w", err)
+	}
+	c.conn = conn
+	c.generation++
+	c.pending = make(map[uint64]chan *response)
+	go c.readLoop(conn)
+	return conn, nil
+}
+
+func (c *client) readLoop(conn *websocket.Conn) {
+	for {
+		var resp response
+		if err := conn.ReadJSON(&resp); err != nil {
+			c.fail(conn, err)
+			return
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[resp.ID]
+		delete(c.pending, resp.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- &resp
+		}
+	}
+}
+
+// fail drops the connection and fails all the requests pending on it.
+func (c *client) fail(conn *websocket.Conn, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != conn {
+		return
+	}
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure) || errors.Is(err, websocket.ErrCloseSent) {
+		err = ErrClosed
+	}
+	if !errors.Is(err, ErrClosed) {
+		err = fmt.Errorf("ogmios: %w", err)
+	}
+	for id, ch := range c.pending {
+		ch <- &response{err: err}
+		delete(c.pending, id)
+	}
+	_ = conn.Close()
+	c.conn = nil
+}
+
+// call sends a request and decodes its result into result.
+func (c *client) call(ctx context.Context, method string, params, result any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	conn, err := c.connect(ctx)
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *response, 1)
+	c.pending[id] = ch
+	err = conn.WriteJSON(&request{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+	c.mu.Unlock()
+	if err != nil {
+		c.fail(conn, err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.err != nil {
+			return resp.err
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("ogmios: invalid %v result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// connection returns the generation of the current connection.
+func (c *client) connection() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// close closes the connection, failing the pending requests with ErrClosed.
+func (c *client) close() error {
+	c.mu.Lock()
+	conn := c.conn
+	if conn != nil {
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteMessage(websocket.CloseMessage, msg)
+	}
+	c.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	c.fail(conn, ErrClosed)
+	return nil
+}
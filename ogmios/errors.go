@@ -0,0 +1,125 @@
+package ogmios
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// Error is a JSON-RPC error returned by Ogmios.
+//
+// Errors are matched with errors.Is on their code, so a submit error can be
+// tested against the sentinels below, or against the equivalent ledger
+// predicate failure of the cardano package.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("ogmios: %v (%v): %s", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("ogmios: %v (%v)", e.Message, e.Code)
+}
+
+// Is reports whether the target is an Error with the same code, or the
+// ledger predicate failure the code maps to.
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *Error:
+		return t.Code == e.Code
+	case *cardano.PredicateFailure:
+		failure, ok := predicateFailures[e.Code]
+		return ok && failure.Is(t)
+	}
+	return false
+}
+
+// Transaction evaluation errors.
+var (
+	ErrIncompatibleEra               = &Error{Code: 3000, Message: "incompatible era"}
+	ErrUnsupportedEra                = &Error{Code: 3001, Message: "unsupported era"}
+	ErrOverlappingAdditionalUtxo     = &Error{Code: 3002, Message: "overlapping additional utxo"}
+	ErrNodeTipTooOld                 = &Error{Code: 3003, Message: "node tip too old"}
+	ErrCannotCreateEvaluationContext = &Error{Code: 3004, Message: "cannot create evaluation context"}
+	ErrScriptExecutionFailure        = &Error{Code: 3010, Message: "script execution failure"}
+)
+
+// Transaction submission errors.
+var (
+	ErrEraMismatch                 = &Error{Code: 3005, Message: "era mismatch"}
+	ErrInvalidSignatures           = &Error{Code: 3100, Message: "invalid signatures"}
+	ErrMissingSignatures           = &Error{Code: 3101, Message: "missing signatures"}
+	ErrMissingScripts              = &Error{Code: 3102, Message: "missing scripts"}
+	ErrFailingNativeScript         = &Error{Code: 3103, Message: "failing native script"}
+	ErrExtraneousScripts           = &Error{Code: 3104, Message: "extraneous scripts"}
+	ErrMissingMetadataHash         = &Error{Code: 3105, Message: "missing metadata hash"}
+	ErrMissingMetadata             = &Error{Code: 3106, Message: "missing metadata"}
+	ErrMetadataHashMismatch        = &Error{Code: 3107, Message: "metadata hash mismatch"}
+	ErrInvalidMetadata             = &Error{Code: 3108, Message: "invalid metadata"}
+	ErrMissingRedeemers            = &Error{Code: 3109, Message: "missing redeemers"}
+	ErrExtraneousRedeemers         = &Error{Code: 3110, Message: "extraneous redeemers"}
+	ErrMissingDatums               = &Error{Code: 3111, Message: "missing datums"}
+	ErrExtraneousDatums            = &Error{Code: 3112, Message: "extraneous datums"}
+	ErrScriptIntegrityHashMismatch = &Error{Code: 3113, Message: "script integrity hash mismatch"}
+	ErrOrphanScriptInputs          = &Error{Code: 3114, Message: "orphan script inputs"}
+	ErrMissingCostModels           = &Error{Code: 3115, Message: "missing cost models"}
+	ErrMalformedScripts            = &Error{Code: 3116, Message: "malformed scripts"}
+	ErrUnknownOutputReferences     = &Error{Code: 3117, Message: "unknown output references"}
+	ErrOutsideOfValidityInterval   = &Error{Code: 3118, Message: "outside of validity interval"}
+	ErrTransactionTooLarge         = &Error{Code: 3119, Message: "transaction too large"}
+	ErrValueTooLarge               = &Error{Code: 3120, Message: "value too large"}
+	ErrEmptyInputSet               = &Error{Code: 3121, Message: "empty input set"}
+	ErrTransactionFeeTooSmall      = &Error{Code: 3122, Message: "transaction fee too small"}
+	ErrValueNotConserved           = &Error{Code: 3123, Message: "value not conserved"}
+	ErrNetworkMismatch             = &Error{Code: 3124, Message: "network mismatch"}
+	ErrInsufficientlyFundedOutputs = &Error{Code: 3125, Message: "insufficiently funded outputs"}
+	ErrBootstrapAttributesTooLarge = &Error{Code: 3126, Message: "bootstrap attributes too large"}
+	ErrMintingOrBurningAda         = &Error{Code: 3127, Message: "minting or burning ada"}
+	ErrInsufficientCollateral      = &Error{Code: 3128, Message: "insufficient collateral"}
+	ErrCollateralLockedByScript    = &Error{Code: 3129, Message: "collateral locked by script"}
+	ErrUnforeseeableSlot           = &Error{Code: 3130, Message: "unforeseeable slot"}
+	ErrTooManyCollateralInputs     = &Error{Code: 3131, Message: "too many collateral inputs"}
+	ErrMissingCollateralInputs     = &Error{Code: 3132, Message: "missing collateral inputs"}
+	ErrNonAdaCollateral            = &Error{Code: 3133, Message: "non ada collateral"}
+	ErrExecutionUnitsTooLarge      = &Error{Code: 3134, Message: "execution units too large"}
+	ErrTotalCollateralMismatch     = &Error{Code: 3135, Message: "total collateral mismatch"}
+	ErrSpendsMismatch              = &Error{Code: 3136, Message: "spends mismatch"}
+	ErrUnknownStakePool            = &Error{Code: 3140, Message: "unknown stake pool"}
+	ErrIncompleteWithdrawals       = &Error{Code: 3141, Message: "incomplete withdrawals"}
+	ErrCredentialAlreadyRegistered = &Error{Code: 3145, Message: "credential already registered"}
+	ErrUnknownCredential           = &Error{Code: 3146, Message: "unknown credential"}
+	ErrNonEmptyRewardAccount       = &Error{Code: 3147, Message: "non empty reward account"}
+	ErrUnexpectedMempoolError      = &Error{Code: 3997, Message: "unexpected mempool error"}
+	ErrDeserialisationFailure      = &Error{Code: 3999, Message: "deserialisation failure"}
+)
+
+// predicateFailures maps the submission errors to the ledger predicate
+// failures checked by cardano.LedgerState.
+var predicateFailures = map[int]*cardano.PredicateFailure{
+	ErrInvalidSignatures.Code:           cardano.ErrInvalidWitnessesUTXOW,
+	ErrMissingSignatures.Code:           cardano.ErrMissingVKeyWitnessesUTXOW,
+	ErrMissingScripts.Code:              cardano.ErrMissingScriptWitnessesUTXOW,
+	ErrFailingNativeScript.Code:         cardano.ErrScriptWitnessNotValidatingUTXOW,
+	ErrMissingMetadataHash.Code:         cardano.ErrMissingTxBodyMetadataHash,
+	ErrMissingMetadata.Code:             cardano.ErrMissingTxMetadata,
+	ErrMetadataHashMismatch.Code:        cardano.ErrConflictingMetadataHash,
+	ErrUnknownOutputReferences.Code:     cardano.ErrBadInputsUTxO,
+	ErrOutsideOfValidityInterval.Code:   cardano.ErrOutsideValidityIntervalUTxO,
+	ErrTransactionTooLarge.Code:         cardano.ErrMaxTxSizeUTxO,
+	ErrValueTooLarge.Code:               cardano.ErrOutputTooBigUTxO,
+	ErrEmptyInputSet.Code:               cardano.ErrInputSetEmptyUTxO,
+	ErrTransactionFeeTooSmall.Code:      cardano.ErrFeeTooSmallUTxO,
+	ErrValueNotConserved.Code:           cardano.ErrValueNotConservedUTxO,
+	ErrNetworkMismatch.Code:             cardano.ErrWrongNetwork,
+	ErrInsufficientlyFundedOutputs.Code: cardano.ErrOutputTooSmallUTxO,
+	ErrInsufficientCollateral.Code:      cardano.ErrInsufficientCollateral,
+	ErrTooManyCollateralInputs.Code:     cardano.ErrTooManyCollateralInputs,
+	ErrMissingCollateralInputs.Code:     cardano.ErrNoCollateralInputs,
+	ErrNonAdaCollateral.Code:            cardano.ErrCollateralContainsNonADA,
+	ErrExecutionUnitsTooLarge.Code:      cardano.ErrExUnitsTooBigUTxO,
+}
@@ -0,0 +1,301 @@
+package ogmios
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// OgmiosNode implements Node using the Ogmios v6 JSON-RPC API.
+type OgmiosNode struct {
+	client  *client
+	network cardano.Network
+}
+
+// check interface
+var (
+	_ cardano.Node           = (*OgmiosNode)(nil)
+	_ cardano.EvaluateTxNode = (*OgmiosNode)(nil)
+)
+
+// NewNode returns a new instance of OgmiosNode connecting to the Ogmios
+// WebSocket server at url, e.g. ws://localhost:1337.
+//
+// The connection is established on the first request and kept open until
+// Close is called.
+func NewNode(network cardano.Network, url string) *OgmiosNode {
+	return &OgmiosNode{client: newClient(url), network: network}
+}
+
+// Close closes the connection to the Ogmios server.
+func (o *OgmiosNode) Close() error {
+	return o.client.close()
+}
+
+type ogmiosTransaction struct {
+	ID string `json:"id,omitempty"`
+}
+
+type ogmiosScript struct {
+	Language string `json:"language"`
+	CBOR     string `json:"cbor"`
+}
+
+type ogmiosUTxO struct {
+	Transaction ogmiosTransaction            `json:"transaction"`
+	Index       uint64                       `json:"index"`
+	Address     string                       `json:"address"`
+	Value       map[string]map[string]uint64 `json:"value"`
+	DatumHash   string                       `json:"datumHash,omitempty"`
+	Datum       string                       `json:"datum,omitempty"`
+	Script      *ogmiosScript                `json:"script,omitempty"`
+}
+
+func (o *OgmiosNode) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	params := map[string][]string{"addresses": {addr.Bech32()}}
+	var outputs []ogmiosUTxO
+	if err := o.client.call(ctx, "queryLedgerState/utxo", params, &outputs); err != nil {
+		return nil, err
+	}
+
+	utxos := make([]cardano.UTxO, len(outputs))
+	for i, output := range outputs {
+		utxo, err := output.utxo()
+		if err != nil {
+			return nil, err
+		}
+		utxos[i] = *utxo
+	}
+	return utxos, nil
+}
+
+func (u *ogmiosUTxO) utxo() (*cardano.UTxO, error) {
+	txHash, err := cardano.NewHash32(u.Transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	spender, err := cardano.NewAddress(u.Address)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseValue(u.Value)
+	if err != nil {
+		return nil, err
+	}
+	utxo := &cardano.UTxO{
+		TxHash:  txHash,
+		Spender: spender,
+		Amount:  amount,
+		Index:   u.Index,
+	}
+	if u.Datum != "" {
+		if utxo.Datum, err = cardano.NewPlutusData(u.Datum); err != nil {
+			return nil, err
+		}
+	} else if u.DatumHash != "" {
+		if utxo.DatumHash, err = cardano.NewHash32(u.DatumHash); err != nil {
+			return nil, err
+		}
+	}
+	if u.Script != nil {
+		if utxo.ScriptRef, err = u.Script.scriptRef(); err != nil {
+			return nil, err
+		}
+	}
+	return utxo, nil
+}
+
+// parseValue parses an Ogmios value, the lovelace under ada and the assets
+// under their policy id, with hex encoded asset names.
+func parseValue(value map[string]map[string]uint64) (*cardano.Value, error) {
+	amount := cardano.NewValue(0)
+	for policy, assets := range value {
+		if policy == "ada" {
+			amount.Coin = cardano.Coin(assets["lovelace"])
+			continue
+		}
+		policyHash, err := cardano.NewHash28(policy)
+		if err != nil {
+			return nil, err
+		}
+		policyID := cardano.NewPolicyIDFromHash(policyHash)
+		parsed := cardano.NewAssets()
+		for name, quantity := range assets {
+			nameBytes, err := hex.DecodeString(name)
+			if err != nil {
+				return nil, err
+			}
+			parsed.Set(cardano.NewAssetName(string(nameBytes)), cardano.BigNum(quantity))
+		}
+		amount.MultiAsset.Set(policyID, parsed)
+	}
+	return amount, nil
+}
+
+var plutusLanguages = map[string]cardano.ScriptHashNamespace{
+	"plutus:v1": cardano.PlutusScriptNamespace,
+	"plutus:v2": cardano.PlutusV2ScriptNamespace,
+	"plutus:v3": cardano.PlutusV3ScriptNamespace,
+}
+
+func (s *ogmiosScript) scriptRef() (*cardano.ScriptRef, error) {
+	switch s.Language {
+	case "native":
+		scriptBytes, err := hex.DecodeString(s.CBOR)
+		if err != nil {
+			return nil, err
+		}
+		var script cardano.NativeScript
+		if err := script.UnmarshalCBOR(scriptBytes); err != nil {
+			return nil, err
+		}
+		return cardano.NewNativeScriptRef(script), nil
+	default:
+		version, ok := plutusLanguages[s.Language]
+		if !ok {
+			return nil, fmt.Errorf("ogmios: unknown script language %v", s.Language)
+		}
+		// The plutus script cbor is the serialized script the script hash is
+		// computed over, it is kept as is.
+		script, err := hex.DecodeString(s.CBOR)
+		if err != nil {
+			return nil, err
+		}
+		return cardano.NewPlutusScriptRef(version, script), nil
+	}
+}
+
+// origin is returned by Ogmios in place of a point or a height when the
+// chain is empty.
+const origin = "origin"
+
+type ogmiosPoint struct {
+	Slot uint64 `json:"slot"`
+	ID   string `json:"id"`
+}
+
+func (o *OgmiosNode) Tip(ctx context.Context) (*cardano.NodeTip, error) {
+	var tip, height json.RawMessage
+	if err := o.client.call(ctx, "queryNetwork/tip", nil, &tip); err != nil {
+		return nil, err
+	}
+	if err := o.client.call(ctx, "queryNetwork/blockHeight", nil, &height); err != nil {
+		return nil, err
+	}
+	var epoch uint64
+	if err := o.client.call(ctx, "queryLedgerState/epoch", nil, &epoch); err != nil {
+		return nil, err
+	}
+
+	nodeTip := &cardano.NodeTip{Epoch: epoch}
+	if string(tip) != strconv.Quote(origin) {
+		var point ogmiosPoint
+		if err := json.Unmarshal(tip, &point); err != nil {
+			return nil, fmt.Errorf("ogmios: invalid tip: %w", err)
+		}
+		nodeTip.Slot = point.Slot
+	}
+	if string(height) != strconv.Quote(origin) {
+		if err := json.Unmarshal(height, &nodeTip.Block); err != nil {
+			return nil, fmt.Errorf("ogmios: invalid block height: %w", err)
+		}
+	}
+	return nodeTip, nil
+}
+
+type ogmiosTxParams struct {
+	Transaction struct {
+		CBOR string `json:"cbor"`
+	} `json:"transaction"`
+}
+
+func newTxParams(tx *cardano.Tx) *ogmiosTxParams {
+	params := &ogmiosTxParams{}
+	params.Transaction.CBOR = tx.Hex()
+	return params
+}
+
+// SubmitTx submits a transaction to the node.
+//
+// Transactions rejected by the ledger return an *Error that can be matched
+// with the submission error sentinels of this package.
+func (o *OgmiosNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	var result struct {
+		Transaction ogmiosTransaction `json:"transaction"`
+	}
+	if err := o.client.call(ctx, "submitTransaction", newTxParams(tx), &result); err != nil {
+		return nil, err
+	}
+	txHash, err := cardano.NewHash32(result.Transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &txHash, nil
+}
+
+func (o *OgmiosNode) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
+	var result json.RawMessage
+	if err := o.client.call(ctx, "queryLedgerState/protocolParameters", nil, &result); err != nil {
+		return nil, err
+	}
+	return cardano.NewProtocolParamsFromOgmiosJSON(result)
+}
+
+func (o *OgmiosNode) Network() cardano.Network {
+	return o.network
+}
+
+var redeemerTags = map[string]cardano.RedeemerTag{
+	"spend":    cardano.RedeemerTagSpend,
+	"mint":     cardano.RedeemerTagMint,
+	"publish":  cardano.RedeemerTagCert,
+	"withdraw": cardano.RedeemerTagReward,
+	"vote":     cardano.RedeemerTagVoting,
+	"propose":  cardano.RedeemerTagProposing,
+}
+
+type ogmiosEvaluation struct {
+	Validator struct {
+		Index   uint64 `json:"index"`
+		Purpose string `json:"purpose"`
+	} `json:"validator"`
+	Budget ogmiosExUnits `json:"budget"`
+}
+
+type ogmiosExUnits struct {
+	Memory uint64 `json:"memory"`
+	CPU    uint64 `json:"cpu"`
+}
+
+// EvaluateTx evaluates the scripts of a transaction and returns the execution
+// units of its redeemers. The returned redeemers only have their Tag, Index and
+// ExUnits set.
+//
+// Script failures return an *Error matching ErrScriptExecutionFailure.
+func (o *OgmiosNode) EvaluateTx(ctx context.Context, tx *cardano.Tx) (cardano.Redeemers, error) {
+	var evaluations []ogmiosEvaluation
+	if err := o.client.call(ctx, "evaluateTransaction", newTxParams(tx), &evaluations); err != nil {
+		return nil, err
+	}
+
+	redeemers := make(cardano.Redeemers, len(evaluations))
+	for i, evaluation := range evaluations {
+		tag, ok := redeemerTags[evaluation.Validator.Purpose]
+		if !ok {
+			return nil, fmt.Errorf("ogmios: unknown validator purpose %v", evaluation.Validator.Purpose)
+		}
+		redeemers[i] = cardano.Redeemer{
+			Tag:   tag,
+			Index: evaluation.Validator.Index,
+			ExUnits: cardano.ExUnits{
+				Mem:   evaluation.Budget.Memory,
+				Steps: evaluation.Budget.CPU,
+			},
+		}
+	}
+	return redeemers, nil
+}
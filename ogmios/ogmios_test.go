@@ -0,0 +1,473 @@
+package ogmios
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/chainsync"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/gorilla/websocket"
+)
+
+type handlerFunc func(params json.RawMessage) (any, *Error)
+
+// newFakeServer returns a WebSocket server answering the JSON-RPC methods
+// with the given handlers.
+func newFakeServer(t *testing.T, handlers map[string]handlerFunc) *httptest.Server {
+	t.Helper()
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+				ID     uint64          `json:"id"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			resp := map[string]any{"jsonrpc": "2.0", "method": req.Method, "id": req.ID}
+			handler, ok := handlers[req.Method]
+			if !ok {
+				resp["error"] = &Error{Code: -32601, Message: "method not found"}
+			} else if result, rpcErr := handler(req.Params); rpcErr != nil {
+				resp["error"] = rpcErr
+			} else {
+				resp["result"] = result
+			}
+			if err := conn.WriteJSON(resp); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestNode(t *testing.T, handlers map[string]handlerFunc) *OgmiosNode {
+	t.Helper()
+	server := newFakeServer(t, handlers)
+	node := NewNode(cardano.Preprod, "ws"+strings.TrimPrefix(server.URL, "http"))
+	t.Cleanup(func() { _ = node.Close() })
+	return node
+}
+
+func newTestTx(t *testing.T) *cardano.Tx {
+	t.Helper()
+	key := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	payment, err := cardano.NewKeyCredential(key.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := cardano.NewEnterpriseAddress(cardano.Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := cardano.NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{MinFeeA: 44, MinFeeB: 155381, CoinsPerUTXOByte: 4310})
+	txBuilder.AddInputs(&cardano.TxInput{TxHash: txHash, Index: 0, Amount: cardano.NewValue(10e6), Spender: &addr})
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.Sign(key.PrvKey())
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestUTxOs(t *testing.T) {
+	addr, err := cardano.NewAddress("addr_test1vpu5vlrf4xkxv2qpwngf6cjhtw542ayty80v8dyr49rf5eg57c2qv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyKey := crypto.NewXPrvKeyFromEntropy([]byte("policy"), "")
+	policyScript, err := cardano.NewScriptPubKey(policyKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyID, err := cardano.NewPolicyID(policyScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptBytes, err := policyScript.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txID := "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+	datumHash := "923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec"
+	// always succeeds plutus v2 script
+	plutusScript := "4d01000033222220051200120011"
+	plutusScriptHash := "793f8c8cffba081b2a56462fc219cc8fe652d6a338b62c7b134876e7"
+
+	node := newTestNode(t, map[string]handlerFunc{
+		"queryLedgerState/utxo": func(params json.RawMessage) (any, *Error) {
+			var p struct {
+				Addresses []string `json:"addresses"`
+			}
+			if err := json.Unmarshal(params, &p); err != nil || len(p.Addresses) != 1 || p.Addresses[0] != addr.Bech32() {
+				return nil, &Error{Code: -32602, Message: "invalid params"}
+			}
+			return []map[string]any{
+				{
+					"transaction": map[string]any{"id": txID},
+					"index":       0,
+					"address":     addr.Bech32(),
+					"value":       map[string]any{"ada": map[string]any{"lovelace": 5000000}},
+					"datumHash":   datumHash,
+				},
+				{
+					"transaction": map[string]any{"id": txID},
+					"index":       1,
+					"address":     addr.Bech32(),
+					"value": map[string]any{
+						"ada":             map[string]any{"lovelace": 2000000},
+						policyID.String(): map[string]any{hex.EncodeToString([]byte("token")): 42},
+					},
+					"script": map[string]any{"language": "native", "cbor": hex.EncodeToString(scriptBytes)},
+				},
+				{
+					"transaction": map[string]any{"id": txID},
+					"index":       2,
+					"address":     addr.Bech32(),
+					"value": map[string]any{
+						"ada":             map[string]any{"lovelace": 3000000},
+						policyID.String(): map[string]any{hex.EncodeToString([]byte("token")): uint64(math.MaxUint64)},
+					},
+					"datum":  "d87980",
+					"script": map[string]any{"language": "plutus:v2", "cbor": plutusScript},
+				},
+			}, nil
+		},
+	})
+
+	utxos, err := node.UTxOs(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(utxos), 3; got != want {
+		t.Fatalf("invalid number of utxos: got %v want %v", got, want)
+	}
+	if got, want := utxos[0].Amount.Coin, cardano.Coin(5e6); got != want {
+		t.Errorf("invalid coin: got %v want %v", got, want)
+	}
+	if got, want := utxos[1].TxHash.String(), txID; got != want {
+		t.Errorf("invalid tx hash: got %v want %v", got, want)
+	}
+	if got, want := utxos[1].Index, uint64(1); got != want {
+		t.Errorf("invalid index: got %v want %v", got, want)
+	}
+	if got, want := utxos[1].Amount.MultiAsset.Get(policyID).Get(cardano.NewAssetName("token")), cardano.BigNum(42); got != want {
+		t.Errorf("invalid asset quantity: got %v want %v", got, want)
+	}
+	if utxos[1].ScriptRef == nil {
+		t.Fatal("missing script ref")
+	}
+	scriptHash, err := utxos[1].ScriptRef.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := scriptHash.String(), policyID.String(); got != want {
+		t.Errorf("invalid script hash: got %v want %v", got, want)
+	}
+	if got, want := utxos[0].DatumHash.String(), datumHash; got != want {
+		t.Errorf("invalid datum hash: got %v want %v", got, want)
+	}
+
+	if got, want := utxos[2].Amount.MultiAsset.Get(policyID).Get(cardano.NewAssetName("token")), cardano.BigNum(math.MaxUint64); got != want {
+		t.Errorf("invalid asset quantity: got %v want %v", got, want)
+	}
+	if utxos[2].Datum == nil {
+		t.Error("missing inline datum")
+	} else if got, want := hex.EncodeToString(utxos[2].Datum), "d87980"; got != want {
+		t.Errorf("invalid inline datum: got %v want %v", got, want)
+	}
+	if utxos[2].ScriptRef == nil {
+		t.Fatal("missing plutus script ref")
+	}
+	plutusHash, err := utxos[2].ScriptRef.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := plutusHash.String(), plutusScriptHash; got != want {
+		t.Errorf("invalid plutus script hash: got %v want %v", got, want)
+	}
+}
+
+func TestTip(t *testing.T) {
+	node := newTestNode(t, map[string]handlerFunc{
+		"queryNetwork/tip": func(json.RawMessage) (any, *Error) {
+			return map[string]any{"slot": 51000000, "id": "b15b0a4f5f4e2f6b2b9a0b0c3c0e6a1f2d0c4b3e2a1f0e9d8c7b6a5f4e3d2c1b"}, nil
+		},
+		"queryNetwork/blockHeight": func(json.RawMessage) (any, *Error) {
+			return 2100000, nil
+		},
+		"queryLedgerState/epoch": func(json.RawMessage) (any, *Error) {
+			return 120, nil
+		},
+	})
+
+	tip, err := node.Tip(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cardano.NodeTip{Block: 2100000, Epoch: 120, Slot: 51000000}
+	if *tip != want {
+		t.Errorf("invalid tip: got %+v want %+v", *tip, want)
+	}
+}
+
+func TestProtocolParams(t *testing.T) {
+	node := newTestNode(t, map[string]handlerFunc{
+		"queryLedgerState/protocolParameters": func(json.RawMessage) (any, *Error) {
+			return json.RawMessage(`{
+				"minFeeCoefficient": 44,
+				"minFeeConstant": {"ada": {"lovelace": 155381}},
+				"minUtxoDepositCoefficient": 4310,
+				"maxTransactionSize": {"bytes": 16384},
+				"collateralPercentage": 150,
+				"version": {"major": 9, "minor": 0}
+			}`), nil
+		},
+	})
+
+	protocol, err := node.ProtocolParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol.MinFeeA != 44 || protocol.MinFeeB != 155381 || protocol.CoinsPerUTXOByte != 4310 {
+		t.Errorf("invalid fee parameters: got %+v", protocol)
+	}
+	if got, want := protocol.MaxTxSize, uint(16384); got != want {
+		t.Errorf("invalid max tx size: got %v want %v", got, want)
+	}
+	if got, want := protocol.ProtocolVersion.Major, uint(9); got != want {
+		t.Errorf("invalid protocol version: got %v want %v", got, want)
+	}
+}
+
+func TestSubmitTx(t *testing.T) {
+	tx := newTestTx(t)
+	txHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reject := false
+	node := newTestNode(t, map[string]handlerFunc{
+		"submitTransaction": func(params json.RawMessage) (any, *Error) {
+			var p ogmiosTxParams
+			if err := json.Unmarshal(params, &p); err != nil || p.Transaction.CBOR != tx.Hex() {
+				return nil, &Error{Code: -32602, Message: "invalid params"}
+			}
+			if reject {
+				return nil, &Error{
+					Code:    3123,
+					Message: "Some value in the transaction isn't balanced.",
+					Data:    json.RawMessage(`{"consumed":{"ada":{"lovelace":10000000}},"produced":{"ada":{"lovelace":9000000}}}`),
+				}
+			}
+			return map[string]any{"transaction": map[string]any{"id": txHash.String()}}, nil
+		},
+	})
+
+	got, err := node.SubmitTx(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != txHash.String() {
+		t.Errorf("invalid tx hash: got %v want %v", got, txHash)
+	}
+
+	reject = true
+	_, err = node.SubmitTx(context.Background(), tx)
+	if !errors.Is(err, ErrValueNotConserved) {
+		t.Errorf("invalid error: got %v want %v", err, ErrValueNotConserved)
+	}
+	if !errors.Is(err, cardano.ErrValueNotConservedUTxO) {
+		t.Errorf("invalid error: got %v want %v", err, cardano.ErrValueNotConservedUTxO)
+	}
+	if errors.Is(err, ErrTransactionFeeTooSmall) || errors.Is(err, cardano.ErrFeeTooSmallUTxO) {
+		t.Errorf("unexpected match: %v", err)
+	}
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || len(rpcErr.Data) == 0 {
+		t.Errorf("missing error data: %v", err)
+	}
+}
+
+func TestEvaluateTx(t *testing.T) {
+	tx := newTestTx(t)
+	fail := false
+	node := newTestNode(t, map[string]handlerFunc{
+		"evaluateTransaction": func(json.RawMessage) (any, *Error) {
+			if fail {
+				return nil, &Error{Code: 3010, Message: "Some scripts of the transactions terminated with error(s)."}
+			}
+			return []map[string]any{
+				{"validator": map[string]any{"index": 0, "purpose": "spend"}, "budget": map[string]any{"memory": 1700, "cpu": 476468}},
+				{"validator": map[string]any{"index": 1, "purpose": "mint"}, "budget": map[string]any{"memory": 2000, "cpu": 500000}},
+			}, nil
+		},
+	})
+
+	redeemers, err := node.EvaluateTx(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(redeemers), 2; got != want {
+		t.Fatalf("invalid number of redeemers: got %v want %v", got, want)
+	}
+	if got, want := redeemers[1].Tag, cardano.RedeemerTagMint; got != want {
+		t.Errorf("invalid tag: got %v want %v", got, want)
+	}
+	if got, want := redeemers.ExUnits(), (cardano.ExUnits{Mem: 3700, Steps: 976468}); got != want {
+		t.Errorf("invalid ex units: got %v want %v", got, want)
+	}
+
+	fail = true
+	if _, err := node.EvaluateTx(context.Background(), tx); !errors.Is(err, ErrScriptExecutionFailure) {
+		t.Errorf("invalid error: got %v want %v", err, ErrScriptExecutionFailure)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	node := newTestNode(t, map[string]handlerFunc{
+		"queryLedgerState/epoch": func(json.RawMessage) (any, *Error) {
+			return 120, nil
+		},
+	})
+
+	var epoch uint64
+	if err := node.client.call(context.Background(), "queryLedgerState/epoch", nil, &epoch); err != nil {
+		t.Fatal(err)
+	}
+	if err := node.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := node.client.call(context.Background(), "queryLedgerState/epoch", nil, &epoch); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := epoch, uint64(120); got != want {
+		t.Errorf("invalid epoch: got %v want %v", got, want)
+	}
+
+	if err := node.client.call(context.Background(), "queryNetwork/unknown", nil, nil); !errors.Is(err, &Error{Code: -32601}) {
+		t.Errorf("invalid error: got %v want method not found", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := node.client.call(ctx, "queryLedgerState/epoch", nil, &epoch); !errors.Is(err, context.Canceled) {
+		t.Errorf("invalid error: got %v want %v", err, context.Canceled)
+	}
+}
+
+func TestChainSync(t *testing.T) {
+	ctx := context.Background()
+	tx := newTestTx(t)
+	txHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const blockID = "1111111111111111111111111111111111111111111111111111111111111111"
+	const ancestor = "2222222222222222222222222222222222222222222222222222222222222222"
+	tip := map[string]any{"slot": 1100, "id": blockID, "height": 11}
+	intersection := map[string]any{"slot": 1000, "id": ancestor}
+
+	var next int
+	node := newTestNode(t, map[string]handlerFunc{
+		"findIntersection": func(params json.RawMessage) (any, *Error) {
+			var req struct {
+				Points []json.RawMessage `json:"points"`
+			}
+			if err := json.Unmarshal(params, &req); err != nil {
+				t.Error(err)
+			}
+			if len(req.Points) == 1 && string(req.Points[0]) == `"origin"` {
+				return nil, &Error{Code: 1000, Message: "no intersection found"}
+			}
+			return map[string]any{"intersection": intersection, "tip": tip}, nil
+		},
+		"nextBlock": func(params json.RawMessage) (any, *Error) {
+			next++
+			if next == 1 {
+				return map[string]any{"direction": "backward", "point": intersection, "tip": tip}, nil
+			}
+			return map[string]any{"direction": "forward", "tip": tip, "block": map[string]any{
+				"era":      "conway",
+				"id":       blockID,
+				"ancestor": ancestor,
+				"height":   11,
+				"slot":     1100,
+				"transactions": []any{
+					map[string]any{"id": txHash.String(), "cbor": hex.EncodeToString(tx.Bytes())},
+				},
+			}}, nil
+		},
+	})
+	cs := node.ChainSync()
+
+	if _, err := cs.Next(ctx); err == nil {
+		t.Fatal("expected error before the intersection")
+	}
+	if _, _, err := cs.FindIntersect(ctx, []cardano.Point{{}}); !errors.Is(err, chainsync.ErrNoIntersection) {
+		t.Fatalf("invalid error: got %v want %v", err, chainsync.ErrNoIntersection)
+	}
+	wantPoint, err := cardano.NewPoint(1000, ancestor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, gotTip, err := cs.FindIntersect(ctx, []cardano.Point{wantPoint, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := point.String(), wantPoint.String(); got != want {
+		t.Errorf("invalid intersection: got %v want %v", got, want)
+	}
+	if got, want := gotTip.Block, uint64(11); got != want {
+		t.Errorf("invalid tip block: got %v want %v", got, want)
+	}
+
+	event, err := cs.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Direction != chainsync.RollBackward || event.Point.String() != wantPoint.String() {
+		t.Errorf("invalid rollback: got %+v", event)
+	}
+
+	event, err = cs.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Direction != chainsync.RollForward {
+		t.Fatalf("invalid direction: got %v want %v", event.Direction, chainsync.RollForward)
+	}
+	block := event.Block
+	if block.Era != cardano.ConwayEra || block.Slot != 1100 || block.Number != 11 || block.PrevHash.String() != ancestor {
+		t.Errorf("invalid block: got %+v", block)
+	}
+	if got, want := len(block.Txs), 1; got != want {
+		t.Fatalf("invalid number of txs: got %v want %v", got, want)
+	}
+	if got, _ := block.Txs[0].Hash(); got.String() != txHash.String() {
+		t.Errorf("invalid tx hash: got %v want %v", got, txHash)
+	}
+}
@@ -0,0 +1,108 @@
+package ouroboros
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// versionN2C is the bit set in the node-to-client protocol versions.
+const versionN2C = 1 << 15
+
+// Node-to-client protocol versions supported, all of them know the Conway
+// era and the local tx monitor.
+var supportedVersions = []uint64{
+	versionN2C | 16,
+	versionN2C | 17,
+	versionN2C | 18,
+	versionN2C | 19,
+}
+
+// Handshake messages.
+const (
+	msgProposeVersions = 0
+	msgAcceptVersion   = 1
+	msgRefuse          = 2
+	msgQueryReply      = 3
+)
+
+// ErrVersionRefused is returned when the node refuses the protocol versions
+// or the network magic proposed.
+var ErrVersionRefused = errors.New("ouroboros: handshake refused")
+
+// versionData are the parameters of a node-to-client version: the network
+// magic and whether the version table is queried instead of negotiated.
+type versionData struct {
+	_     struct{} `cbor:",toarray"`
+	Magic uint32
+	Query bool
+}
+
+// handshake proposes the supported versions for the network magic and
+// returns the version accepted by the node.
+func handshake(m *muxer, magic uint32) (uint64, error) {
+	c := m.channel(protocolHandshake)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versions := make(map[uint64]versionData, len(supportedVersions))
+	for _, v := range supportedVersions {
+		versions[v] = versionData{Magic: magic}
+	}
+	if err := c.send(msgProposeVersions, versions); err != nil {
+		return 0, err
+	}
+
+	tag, fields, err := c.recv()
+	if err != nil {
+		return 0, err
+	}
+	switch tag {
+	case msgAcceptVersion:
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("ouroboros: invalid accept version message")
+		}
+		var version uint64
+		if err := cborDec.Unmarshal(fields[0], &version); err != nil {
+			return 0, err
+		}
+		return version, nil
+	case msgRefuse:
+		if len(fields) != 1 {
+			return 0, fmt.Errorf("ouroboros: invalid refuse message")
+		}
+		return 0, fmt.Errorf("%w: %v", ErrVersionRefused, refuseReason(fields[0]))
+	default:
+		return 0, c.unexpected(tag)
+	}
+}
+
+// refuseReason describes the reason of a refused handshake:
+// [0, versions] for a version mismatch, [1, version, message] for a decode
+// error and [2, version, message] for a refused version, e.g. a wrong magic.
+func refuseReason(data cbor.RawMessage) string {
+	var reason []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &reason); err != nil || len(reason) == 0 {
+		return "invalid reason"
+	}
+	var tag uint64
+	_ = cborDec.Unmarshal(reason[0], &tag)
+	switch {
+	case tag == 0 && len(reason) == 2:
+		var versions []uint64
+		_ = cborDec.Unmarshal(reason[1], &versions)
+		for i := range versions {
+			versions[i] &^= versionN2C
+		}
+		return fmt.Sprintf("version mismatch, node versions %v", versions)
+	case (tag == 1 || tag == 2) && len(reason) == 3:
+		var version uint64
+		var message string
+		_ = cborDec.Unmarshal(reason[1], &version)
+		_ = cborDec.Unmarshal(reason[2], &message)
+		return fmt.Sprintf("version %v: %v", version&^versionN2C, message)
+	default:
+		return "unknown reason"
+	}
+}
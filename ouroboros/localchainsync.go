@@ -0,0 +1,172 @@
+package ouroboros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/chainsync"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// ChainSync messages.
+const (
+	msgRequestNext       = 0
+	msgAwaitReply        = 1
+	msgRollForward       = 2
+	msgRollBackward      = 3
+	msgFindIntersect     = 4
+	msgIntersectFound    = 5
+	msgIntersectNotFound = 6
+)
+
+// ChainSync follows the chain of the node over the ChainSync mini-protocol,
+// the blocks are delivered whole. It implements chainsync.Source.
+type ChainSync struct {
+	node *OuroborosNode
+
+	mu  sync.Mutex
+	mux *muxer // connection the intersection was found on
+}
+
+// check interface
+var _ chainsync.Source = (*ChainSync)(nil)
+
+// ChainSync returns a new chain-sync client of the node.
+func (o *OuroborosNode) ChainSync() *ChainSync {
+	return &ChainSync{node: o}
+}
+
+// tip is the tip of the chain, its point and block number.
+type tip struct {
+	_     struct{} `cbor:",toarray"`
+	Point cardano.Point
+	Block uint64
+}
+
+func (t *tip) tip() chainsync.Tip {
+	return chainsync.Tip{Point: t.Point, Block: t.Block}
+}
+
+func (cs *ChainSync) FindIntersect(ctx context.Context, points []cardano.Point) (cardano.Point, chainsync.Tip, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return cardano.Point{}, chainsync.Tip{}, err
+	}
+	m, err := cs.node.connect(ctx)
+	if err != nil {
+		return cardano.Point{}, chainsync.Tip{}, err
+	}
+
+	var intersection cardano.Point
+	var t tip
+	err = exchange(ctx, m, protocolChainSync, func(c *channel) error {
+		if err := c.send(msgFindIntersect, points); err != nil {
+			return err
+		}
+		tag, fields, err := c.recv()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tag == msgIntersectFound && len(fields) == 2:
+			if err := cborDec.Unmarshal(fields[0], &intersection); err != nil {
+				return err
+			}
+			return cborDec.Unmarshal(fields[1], &t)
+		case tag == msgIntersectNotFound && len(fields) == 1:
+			return chainsync.ErrNoIntersection
+		default:
+			return c.unexpected(tag)
+		}
+	})
+	if err != nil {
+		return cardano.Point{}, chainsync.Tip{}, err
+	}
+	cs.mux = m
+	return intersection, t.tip(), nil
+}
+
+func (cs *ChainSync) Next(ctx context.Context) (*chainsync.Event, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cs.mux == nil {
+		return nil, errors.New("ouroboros: chain sync not started, find an intersection first")
+	}
+
+	var event *chainsync.Event
+	err := exchange(ctx, cs.mux, protocolChainSync, func(c *channel) error {
+		if err := c.send(msgRequestNext); err != nil {
+			return err
+		}
+		for {
+			tag, fields, err := c.recv()
+			if err != nil {
+				return err
+			}
+			switch {
+			case tag == msgAwaitReply:
+				continue
+			case tag == msgRollForward && len(fields) == 2:
+				event, err = rollForward(fields[0], fields[1])
+				return err
+			case tag == msgRollBackward && len(fields) == 2:
+				var t tip
+				event = &chainsync.Event{Direction: chainsync.RollBackward}
+				if err := cborDec.Unmarshal(fields[0], &event.Point); err != nil {
+					return err
+				}
+				if err := cborDec.Unmarshal(fields[1], &t); err != nil {
+					return err
+				}
+				event.Tip = t.tip()
+				return nil
+			default:
+				return c.unexpected(tag)
+			}
+		}
+	})
+	return event, err
+}
+
+// rollForward decodes a block rolled forward, wrapped in a CBOR data item
+// with the tag of its era: 0 for the Byron epoch boundary blocks, 1 for the
+// Byron main blocks and the index of the era plus one from Shelley onwards.
+func rollForward(data, tipData cbor.RawMessage) (*chainsync.Event, error) {
+	var wrapped cbor.RawTag
+	if err := cborDec.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+	var blockData []byte
+	if err := cborDec.Unmarshal(wrapped.Content, &blockData); err != nil {
+		return nil, err
+	}
+	var tagged struct {
+		_     struct{} `cbor:",toarray"`
+		Tag   uint64
+		Block cbor.RawMessage
+	}
+	if err := cborDec.Unmarshal(blockData, &tagged); err != nil {
+		return nil, err
+	}
+	era := cardano.ByronEra
+	if tagged.Tag > 1 {
+		era = cardano.Era(tagged.Tag - 1)
+	}
+	block, err := cardano.NewBlockFromCBOR(era, tagged.Block)
+	if err != nil {
+		return nil, fmt.Errorf("ouroboros: invalid %v block: %w", era, err)
+	}
+
+	var t tip
+	if err := cborDec.Unmarshal(tipData, &t); err != nil {
+		return nil, err
+	}
+	return &chainsync.Event{Direction: chainsync.RollForward, Block: block, Tip: t.tip()}, nil
+}
@@ -0,0 +1,273 @@
+package ouroboros
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// LocalStateQuery messages.
+const (
+	msgAcquire    = 0
+	msgAcquired   = 1
+	msgFailure    = 2
+	msgQuery      = 3
+	msgResult     = 4
+	msgRelease    = 5
+	msgAcquireTip = 8
+)
+
+// ErrAcquireFailed is returned when the node cannot acquire the ledger state
+// at the point requested.
+var ErrAcquireFailed = errors.New("ouroboros: acquire failed")
+
+// EraMismatchError is returned when a query is made for another era than the
+// current era of the ledger.
+type EraMismatchError struct {
+	LedgerEra string
+	QueryEra  string
+}
+
+func (e *EraMismatchError) Error() string {
+	return fmt.Sprintf("ouroboros: era mismatch: ledger is in %v, query is for %v", e.LedgerEra, e.QueryEra)
+}
+
+// stateQuery runs queries over the LocalStateQuery mini-protocol, against the
+// ledger state acquired at a point.
+type stateQuery struct {
+	c *channel
+}
+
+// acquire acquires the ledger state at the volatile tip of the chain.
+func (s *stateQuery) acquire() error {
+	if err := s.c.send(msgAcquireTip); err != nil {
+		return err
+	}
+	tag, fields, err := s.c.recv()
+	if err != nil {
+		return err
+	}
+	switch tag {
+	case msgAcquired:
+		return nil
+	case msgFailure:
+		var reason uint64
+		if len(fields) == 1 {
+			_ = cborDec.Unmarshal(fields[0], &reason)
+		}
+		if reason == 0 {
+			return fmt.Errorf("%w: point too old", ErrAcquireFailed)
+		}
+		return fmt.Errorf("%w: point not on chain", ErrAcquireFailed)
+	default:
+		return s.c.unexpected(tag)
+	}
+}
+
+// release releases the ledger state acquired.
+func (s *stateQuery) release() error {
+	return s.c.send(msgRelease)
+}
+
+// query runs a query and decodes its result into result.
+func (s *stateQuery) query(q any, result any) error {
+	if err := s.c.send(msgQuery, q); err != nil {
+		return err
+	}
+	tag, fields, err := s.c.recv()
+	if err != nil {
+		return err
+	}
+	if tag != msgResult || len(fields) != 1 {
+		return s.c.unexpected(tag)
+	}
+	return cborDec.Unmarshal(fields[0], result)
+}
+
+// Queries of the hard fork combinator, wrapping the queries of the eras.
+var (
+	querySystemStart   = []any{1}
+	queryChainBlockNo  = []any{2}
+	queryChainPoint    = []any{3}
+	queryEraHistory    = []any{0, []any{2, []any{0}}}
+	queryCurrentEra    = []any{0, []any{2, []any{1}}}
+	shelleyEpochNo     = []any{1}
+	shelleyPParams     = []any{3}
+	shelleyStakeDistr  = []any{5}
+	shelleyUTxOByAddr  = 6
+	shelleyUTxOByTxIns = 15
+)
+
+// currentEra returns the era of the ledger state acquired.
+func (s *stateQuery) currentEra() (cardano.Era, error) {
+	var era cardano.Era
+	if err := s.query(queryCurrentEra, &era); err != nil {
+		return 0, err
+	}
+	return era, nil
+}
+
+// queryIfCurrent runs a query of the current era of the ledger, Shelley
+// onwards. The result is wrapped in a one element array, or is the
+// names of the eras for a mismatch.
+func (s *stateQuery) queryIfCurrent(q any, result any) error {
+	era, err := s.currentEra()
+	if err != nil {
+		return err
+	}
+	if era < cardano.ShelleyEra {
+		return fmt.Errorf("ouroboros: unsupported era %v", era)
+	}
+
+	var wrapped []cbor.RawMessage
+	if err := s.query([]any{0, []any{0, []any{era, q}}}, &wrapped); err != nil {
+		return err
+	}
+	switch len(wrapped) {
+	case 1:
+		return cborDec.Unmarshal(wrapped[0], result)
+	case 2:
+		mismatch := &EraMismatchError{}
+		_ = cborDec.Unmarshal(wrapped[0], &mismatch.LedgerEra)
+		_ = cborDec.Unmarshal(wrapped[1], &mismatch.QueryEra)
+		return mismatch
+	default:
+		return fmt.Errorf("ouroboros: invalid query result")
+	}
+}
+
+// tip returns the tip of the ledger state acquired.
+func (s *stateQuery) tip() (*cardano.NodeTip, error) {
+	var point cardano.Point
+	if err := s.query(queryChainPoint, &point); err != nil {
+		return nil, err
+	}
+	var blockNo []uint64
+	if err := s.query(queryChainBlockNo, &blockNo); err != nil {
+		return nil, err
+	}
+	tip := &cardano.NodeTip{Slot: point.Slot}
+	if len(blockNo) == 2 {
+		tip.Block = blockNo[1]
+	}
+	if point.IsOrigin() {
+		return tip, nil
+	}
+	if err := s.queryIfCurrent(shelleyEpochNo, &tip.Epoch); err != nil {
+		return nil, err
+	}
+	return tip, nil
+}
+
+type txIn struct {
+	_      struct{} `cbor:",toarray"`
+	TxHash cbor.ByteString
+	Index  uint64
+}
+
+// utxos runs a UTxO query and converts its result, a map of the inputs to
+// the outputs.
+func (s *stateQuery) utxos(q any) ([]cardano.UTxO, error) {
+	var outputs map[txIn]cardano.TxOutput
+	if err := s.queryIfCurrent(q, &outputs); err != nil {
+		return nil, err
+	}
+
+	utxos := make([]cardano.UTxO, 0, len(outputs))
+	for in, out := range outputs {
+		utxos = append(utxos, cardano.UTxO{
+			TxHash:    in.TxHash.Bytes(),
+			Spender:   out.Address,
+			Amount:    out.Amount,
+			Index:     in.Index,
+			ScriptRef: out.ScriptRef,
+			DatumHash: out.DatumHash,
+			Datum:     out.Datum,
+		})
+	}
+	sort.Slice(utxos, func(i, j int) bool {
+		if c := bytes.Compare(utxos[i].TxHash, utxos[j].TxHash); c != 0 {
+			return c < 0
+		}
+		return utxos[i].Index < utxos[j].Index
+	})
+	return utxos, nil
+}
+
+// utcTime is the encoding of a UTC time: the year, the day of the year and
+// the picoseconds of the day.
+type utcTime struct {
+	_           struct{} `cbor:",toarray"`
+	Year        int
+	Day         int
+	Picoseconds big.Int
+}
+
+func (t *utcTime) time() time.Time {
+	nanos := new(big.Int).Div(&t.Picoseconds, big.NewInt(1000))
+	return time.Date(t.Year, 1, t.Day, 0, 0, 0, 0, time.UTC).Add(time.Duration(nanos.Int64()))
+}
+
+// bound is the start or the end of an era, the time is relative to the
+// system start in picoseconds.
+type bound struct {
+	_     struct{} `cbor:",toarray"`
+	Time  big.Int
+	Slot  uint64
+	Epoch uint64
+}
+
+// eraSummary is the summary of an era, its parameters start with the epoch
+// length and the slot length in milliseconds, followed by the safe zone and,
+// depending on the node version, the genesis window.
+type eraSummary struct {
+	_      struct{} `cbor:",toarray"`
+	Start  bound
+	End    *bound // nil for an unbounded era
+	Params []cbor.RawMessage
+}
+
+// slotConfig returns the era history of the chain.
+func (s *stateQuery) slotConfig() (*cardano.SlotConfig, error) {
+	var start utcTime
+	if err := s.query(querySystemStart, &start); err != nil {
+		return nil, err
+	}
+	var summaries []eraSummary
+	if err := s.query(queryEraHistory, &summaries); err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, fmt.Errorf("ouroboros: empty era history")
+	}
+
+	systemStart := start.time()
+	config := &cardano.SlotConfig{}
+	for _, summary := range summaries {
+		var epochLength, slotLength uint64
+		if len(summary.Params) < 2 {
+			return nil, fmt.Errorf("ouroboros: invalid era parameters")
+		}
+		if err := cborDec.Unmarshal(summary.Params[0], &epochLength); err != nil {
+			return nil, err
+		}
+		if err := cborDec.Unmarshal(summary.Params[1], &slotLength); err != nil {
+			return nil, err
+		}
+		nanos := new(big.Int).Div(&summary.Start.Time, big.NewInt(1000))
+		config.Eras = append(config.Eras, cardano.EraSummary{
+			StartSlot:   summary.Start.Slot,
+			StartEpoch:  summary.Start.Epoch,
+			StartTime:   systemStart.Add(time.Duration(nanos.Int64())),
+			SlotLength:  time.Duration(slotLength) * time.Millisecond,
+			EpochLength: epochLength,
+		})
+	}
+	return config, nil
+}
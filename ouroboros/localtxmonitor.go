@@ -0,0 +1,137 @@
+package ouroboros
+
+import (
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// LocalTxMonitor messages.
+const (
+	msgMonitorAcquire  = 1
+	msgMonitorAcquired = 2
+	msgMonitorRelease  = 3
+	msgNextTx          = 5
+	msgReplyNextTx     = 6
+	msgHasTx           = 7
+	msgReplyHasTx      = 8
+	msgGetSizes        = 9
+	msgReplyGetSizes   = 10
+)
+
+// MempoolSizes are the sizes of the mempool of the node.
+type MempoolSizes struct {
+	_ struct{} `cbor:",toarray"`
+
+	// Capacity is the maximum size of the mempool in bytes.
+	Capacity uint32
+
+	// Size is the size of the transactions in the mempool in bytes.
+	Size uint32
+
+	// NumberOfTxs is the number of transactions in the mempool.
+	NumberOfTxs uint32
+}
+
+// mempool inspects the mempool of the node over the LocalTxMonitor
+// mini-protocol, against the snapshot of the mempool acquired.
+type mempool struct {
+	c *channel
+}
+
+// acquire acquires a snapshot of the mempool and returns its slot.
+func (m *mempool) acquire() (uint64, error) {
+	if err := m.c.send(msgMonitorAcquire); err != nil {
+		return 0, err
+	}
+	tag, fields, err := m.c.recv()
+	if err != nil {
+		return 0, err
+	}
+	if tag != msgMonitorAcquired || len(fields) != 1 {
+		return 0, m.c.unexpected(tag)
+	}
+	var slot uint64
+	if err := cborDec.Unmarshal(fields[0], &slot); err != nil {
+		return 0, err
+	}
+	return slot, nil
+}
+
+// release releases the snapshot acquired.
+func (m *mempool) release() error {
+	return m.c.send(msgMonitorRelease)
+}
+
+// request sends a message and returns the only field of the reply.
+func (m *mempool) request(reply uint64, msg ...any) ([]cbor.RawMessage, error) {
+	if err := m.c.send(msg...); err != nil {
+		return nil, err
+	}
+	tag, fields, err := m.c.recv()
+	if err != nil {
+		return nil, err
+	}
+	if tag != reply {
+		return nil, m.c.unexpected(tag)
+	}
+	return fields, nil
+}
+
+// nextTx returns the next transaction of the snapshot, or nil once all the
+// transactions were returned.
+func (m *mempool) nextTx() (*cardano.Tx, error) {
+	fields, err := m.request(msgReplyNextTx, msgNextTx)
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+
+	var wrapped struct {
+		_    struct{} `cbor:",toarray"`
+		Era  cardano.Era
+		Body cbor.RawTag
+	}
+	if err := cborDec.Unmarshal(fields[0], &wrapped); err != nil {
+		return nil, err
+	}
+	var txBytes []byte
+	if err := cborDec.Unmarshal(wrapped.Body.Content, &txBytes); err != nil {
+		return nil, err
+	}
+	tx := &cardano.Tx{}
+	if err := tx.UnmarshalCBOR(txBytes); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// hasTx reports whether the snapshot contains the transaction.
+func (m *mempool) hasTx(txHash cardano.Hash32) (bool, error) {
+	fields, err := m.request(msgReplyHasTx, msgHasTx, []byte(txHash))
+	if err != nil {
+		return false, err
+	}
+	if len(fields) != 1 {
+		return false, m.c.unexpected(msgReplyHasTx)
+	}
+	var has bool
+	if err := cborDec.Unmarshal(fields[0], &has); err != nil {
+		return false, err
+	}
+	return has, nil
+}
+
+// sizes returns the sizes of the snapshot.
+func (m *mempool) sizes() (*MempoolSizes, error) {
+	fields, err := m.request(msgReplyGetSizes, msgGetSizes)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 1 {
+		return nil, m.c.unexpected(msgReplyGetSizes)
+	}
+	sizes := &MempoolSizes{}
+	if err := cborDec.Unmarshal(fields[0], sizes); err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
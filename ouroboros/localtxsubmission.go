@@ -0,0 +1,222 @@
+package ouroboros
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// LocalTxSubmission messages.
+const (
+	msgSubmitTx = 0
+	msgAcceptTx = 1
+	msgRejectTx = 2
+)
+
+// RejectionError is returned when the node rejects a transaction. The
+// failures of the Conway ledger rules are decoded, errors.Is matches them
+// with the predicate failures of the cardano package, e.g.
+// errors.Is(err, cardano.ErrValueNotConservedUTxO).
+type RejectionError struct {
+	// Era is the era the transaction was validated in.
+	Era cardano.Era
+
+	// Failures are the predicate failures of the ledger rules.
+	Failures []*cardano.PredicateFailure
+
+	// Raw is the CBOR encoding of the rejection reason.
+	Raw []byte
+}
+
+func (e *RejectionError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("ouroboros: transaction rejected: %x", e.Raw)
+	}
+	failures := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		failures[i] = f.Error()
+	}
+	return "ouroboros: transaction rejected: " + strings.Join(failures, ", ")
+}
+
+// Unwrap returns the predicate failures.
+func (e *RejectionError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f
+	}
+	return errs
+}
+
+// submitTx submits a transaction of the era, returning nil once accepted in
+// the mempool of the node.
+func submitTx(c *channel, era cardano.Era, tx *cardano.Tx) error {
+	body := cbor.Tag{Number: 24, Content: tx.Bytes()}
+	if err := c.send(msgSubmitTx, []any{era, body}); err != nil {
+		return err
+	}
+	tag, fields, err := c.recv()
+	if err != nil {
+		return err
+	}
+	switch tag {
+	case msgAcceptTx:
+		return nil
+	case msgRejectTx:
+		if len(fields) != 1 {
+			return c.unexpected(tag)
+		}
+		return newRejectionError(fields[0])
+	default:
+		return c.unexpected(tag)
+	}
+}
+
+// newRejectionError decodes the reason of a rejection, the failures of the
+// ledger rules of an era: [era, [failure, ...]].
+func newRejectionError(reason cbor.RawMessage) *RejectionError {
+	rejection := &RejectionError{Raw: reason}
+
+	var wrapped []cbor.RawMessage
+	if err := cborDec.Unmarshal(reason, &wrapped); err != nil || len(wrapped) != 2 {
+		return rejection
+	}
+	if err := cborDec.Unmarshal(wrapped[0], &rejection.Era); err != nil {
+		return rejection
+	}
+	var failures []cbor.RawMessage
+	if err := cborDec.Unmarshal(wrapped[1], &failures); err != nil {
+		return rejection
+	}
+	for _, failure := range failures {
+		if rejection.Era == cardano.ConwayEra {
+			rejection.Failures = append(rejection.Failures, conwayLedgerFailure(failure))
+		} else {
+			rejection.Failures = append(rejection.Failures, newFailure("LEDGER", "", failure))
+		}
+	}
+	return rejection
+}
+
+// Names of the failures of the Conway LEDGER, UTXOW and UTXO rules, by tag.
+// The tags of the LEDGER failures start at 1.
+var (
+	conwayLedgerFailures = []string{
+		"", "ConwayUtxowFailure", "ConwayCertsFailure", "ConwayGovFailure",
+		"ConwayWdrlNotDelegatedToDRep", "ConwayTreasuryValueMismatch",
+		"ConwayTxRefScriptsSizeTooBig", "ConwayMempoolFailure",
+	}
+	conwayUtxowFailures = []string{
+		"UtxoFailure", "InvalidWitnessesUTXOW", "MissingVKeyWitnessesUTXOW",
+		"MissingScriptWitnessesUTXOW", "ScriptWitnessNotValidatingUTXOW",
+		"MissingTxBodyMetadataHash", "MissingTxMetadata", "ConflictingMetadataHash",
+		"InvalidMetadata", "ExtraneousScriptWitnessesUTXOW", "MissingRedeemers",
+		"MissingRequiredDatums", "NotAllowedSupplementalDatums", "PPViewHashesDontMatch",
+		"UnspendableUTxONoDatumHash", "ExtraRedeemers", "MalformedScriptWitnesses",
+		"MalformedReferenceScripts",
+	}
+	conwayUtxoFailures = []string{
+		"UtxosFailure", "BadInputsUTxO", "OutsideValidityIntervalUTxO", "MaxTxSizeUTxO",
+		"InputSetEmptyUTxO", "FeeTooSmallUTxO", "ValueNotConservedUTxO", "WrongNetwork",
+		"WrongNetworkWithdrawal", "OutputTooSmallUTxO", "OutputBootAddrAttrsTooBig",
+		"OutputTooBigUTxO", "InsufficientCollateral", "ScriptsNotPaidUTxO",
+		"ExUnitsTooBigUTxO", "CollateralContainsNonADA", "WrongNetworkInTxBody",
+		"OutsideForecast", "TooManyCollateralInputs", "NoCollateralInputs",
+		"IncorrectTotalCollateralField", "BabbageOutputTooSmallUTxO",
+		"BabbageNonDisjointRefInputs",
+	}
+)
+
+// conwayLedgerFailure decodes a failure of the Conway LEDGER rule, unwrapping
+// the failures of the UTXOW and UTXO rules.
+func conwayLedgerFailure(data cbor.RawMessage) *cardano.PredicateFailure {
+	tag, fields, ok := decodeSum(data)
+	if !ok {
+		return newFailure("LEDGER", "", data)
+	}
+	if tag != 1 || len(fields) != 1 {
+		return newFailure("LEDGER", nameOf(conwayLedgerFailures, tag), fields...)
+	}
+
+	tag, fields, ok = decodeSum(fields[0])
+	if !ok {
+		return newFailure("UTXOW", "", fields[0])
+	}
+	if tag != 0 || len(fields) != 1 {
+		return newFailure("UTXOW", nameOf(conwayUtxowFailures, tag), fields...)
+	}
+
+	tag, fields, ok = decodeSum(fields[0])
+	if !ok {
+		return newFailure("UTXO", "", fields[0])
+	}
+	return newFailure("UTXO", nameOf(conwayUtxoFailures, tag), fields...)
+}
+
+// decodeSum decodes a sum type encoded as an array starting with its tag.
+func decodeSum(data cbor.RawMessage) (uint64, []cbor.RawMessage, bool) {
+	var raw []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &raw); err != nil || len(raw) == 0 {
+		return 0, nil, false
+	}
+	var tag uint64
+	if err := cborDec.Unmarshal(raw[0], &tag); err != nil {
+		return 0, nil, false
+	}
+	return tag, raw[1:], true
+}
+
+func nameOf(names []string, tag uint64) string {
+	if tag < uint64(len(names)) && names[tag] != "" {
+		return names[tag]
+	}
+	return fmt.Sprintf("Unknown%v", tag)
+}
+
+// newFailure returns a predicate failure described by the fields of the
+// failure, with the byte strings hex encoded.
+func newFailure(rule, name string, fields ...cbor.RawMessage) *cardano.PredicateFailure {
+	if name == "" {
+		name = "Unknown"
+	}
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		var v any
+		if err := cborDec.Unmarshal(field, &v); err != nil {
+			args[i] = hex.EncodeToString(field)
+			continue
+		}
+		args[i] = describe(v)
+	}
+	return &cardano.PredicateFailure{Rule: rule, Name: name, Message: strings.Join(args, " ")}
+}
+
+func describe(v any) string {
+	switch v := v.(type) {
+	case []byte:
+		return hex.EncodeToString(v)
+	case cbor.ByteString:
+		return hex.EncodeToString(v.Bytes())
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = describe(item)
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	case map[any]any:
+		items := make([]string, 0, len(v))
+		for k, item := range v {
+			items = append(items, describe(k)+":"+describe(item))
+		}
+		sort.Strings(items)
+		return "{" + strings.Join(items, " ") + "}"
+	case cbor.Tag:
+		return fmt.Sprintf("%v(%v)", v.Number, describe(v.Content))
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,236 @@
+package ouroboros
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// ErrClosed is returned for the messages pending when the connection was closed.
+var ErrClosed = errors.New("ouroboros: connection closed")
+
+var cborEnc, _ = cbor.CanonicalEncOptions().EncMode()
+var cborDec, _ = cbor.DecOptions{MapKeyByteString: cbor.MapKeyByteStringWrap}.DecMode()
+
+type protocolID uint16
+
+// Node-to-client mini-protocol numbers.
+const (
+	protocolHandshake         protocolID = 0
+	protocolChainSync         protocolID = 5
+	protocolLocalTxSubmission protocolID = 6
+	protocolLocalStateQuery   protocolID = 7
+	protocolLocalTxMonitor    protocolID = 9
+)
+
+const (
+	headerSize = 8
+
+	// modeResponder is set in the protocol number of the segments sent by
+	// the responder side of a mini-protocol.
+	modeResponder = 0x8000
+
+	// maxSegmentSize is the maximum payload of the segments sent, the
+	// messages larger than this are split over several segments.
+	maxSegmentSize = 12288
+)
+
+// muxer multiplexes the mini-protocols over a single connection. Each
+// segment carries an 8 bytes header: a timestamp in microseconds, the
+// protocol number with the mode bit, and the payload length.
+type muxer struct {
+	conn      net.Conn
+	responder bool
+	start     time.Time
+
+	wmu sync.Mutex // serialises the segments written
+
+	mu       sync.Mutex
+	channels map[protocolID]*channel
+	err      error
+	done     chan struct{}
+}
+
+func newMuxer(conn net.Conn, responder bool) *muxer {
+	m := &muxer{
+		conn:      conn,
+		responder: responder,
+		start:     time.Now(),
+		channels:  make(map[protocolID]*channel),
+		done:      make(chan struct{}),
+	}
+	go m.readLoop()
+	return m
+}
+
+// channel returns the channel of a mini-protocol.
+func (m *muxer) channel(id protocolID) *channel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.channels[id]; ok {
+		return c
+	}
+	c := &channel{mux: m, id: id, in: make(chan []byte, 16)}
+	c.dec = cborDec.NewDecoder(c)
+	m.channels[id] = c
+	return c
+}
+
+func (m *muxer) readLoop() {
+	header := make([]byte, headerSize)
+	for {
+		if _, err := io.ReadFull(m.conn, header); err != nil {
+			m.fail(err)
+			return
+		}
+		mode := binary.BigEndian.Uint16(header[4:6])
+		id := protocolID(mode &^ modeResponder)
+		if (mode&modeResponder != 0) == m.responder {
+			m.fail(fmt.Errorf("ouroboros: invalid mode for protocol %v", id))
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint16(header[6:8]))
+		if _, err := io.ReadFull(m.conn, payload); err != nil {
+			m.fail(err)
+			return
+		}
+
+		m.mu.Lock()
+		c, ok := m.channels[id]
+		m.mu.Unlock()
+		if !ok {
+			m.fail(fmt.Errorf("ouroboros: unexpected message for protocol %v", id))
+			return
+		}
+		select {
+		case c.in <- payload:
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// write sends a message in as many segments as needed.
+func (m *muxer) write(id protocolID, msg []byte) error {
+	mode := uint16(id)
+	if m.responder {
+		mode |= modeResponder
+	}
+
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	for len(msg) > 0 {
+		n := min(len(msg), maxSegmentSize)
+		segment := make([]byte, headerSize+n)
+		binary.BigEndian.PutUint32(segment[0:4], uint32(time.Since(m.start).Microseconds()))
+		binary.BigEndian.PutUint16(segment[4:6], mode)
+		binary.BigEndian.PutUint16(segment[6:8], uint16(n))
+		copy(segment[headerSize:], msg[:n])
+		if _, err := m.conn.Write(segment); err != nil {
+			m.fail(err)
+			return err
+		}
+		msg = msg[n:]
+	}
+	return nil
+}
+
+// fail closes the connection, the pending and future reads return err.
+func (m *muxer) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return
+	}
+	if !errors.Is(err, ErrClosed) {
+		err = fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+	m.err = err
+	close(m.done)
+	_ = m.conn.Close()
+}
+
+func (m *muxer) close() error {
+	m.fail(ErrClosed)
+	return nil
+}
+
+// closed returns the error the connection was closed with, or nil.
+func (m *muxer) closed() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// channel is the state of a mini-protocol on the connection. The messages
+// of a mini-protocol are CBOR items, possibly split over several segments.
+type channel struct {
+	mux *muxer
+	id  protocolID
+
+	// mu serialises the exchanges with the peer, the mini-protocols
+	// are state machines where only one side has agency at a time.
+	mu sync.Mutex
+
+	in  chan []byte
+	buf []byte
+	dec *cbor.Decoder
+}
+
+// Read implements io.Reader over the payloads of the segments received.
+func (c *channel) Read(p []byte) (int, error) {
+	if len(c.buf) == 0 {
+		select {
+		case c.buf = <-c.in:
+		case <-c.mux.done:
+			// deliver the payloads received before the failure
+			select {
+			case c.buf = <-c.in:
+			default:
+				return 0, c.mux.closed()
+			}
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// send encodes and sends a message.
+func (c *channel) send(msg ...any) error {
+	data, err := cborEnc.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.mux.write(c.id, data)
+}
+
+// recv receives a message, returning its tag and its fields.
+func (c *channel) recv() (uint64, []cbor.RawMessage, error) {
+	var msg []cbor.RawMessage
+	if err := c.dec.Decode(&msg); err != nil {
+		if closed := c.mux.closed(); closed != nil {
+			return 0, nil, closed
+		}
+		return 0, nil, err
+	}
+	if len(msg) == 0 {
+		return 0, nil, fmt.Errorf("ouroboros: empty message on protocol %v", c.id)
+	}
+	var tag uint64
+	if err := cborDec.Unmarshal(msg[0], &tag); err != nil {
+		return 0, nil, fmt.Errorf("ouroboros: invalid message tag on protocol %v: %w", c.id, err)
+	}
+	return tag, msg[1:], nil
+}
+
+// unexpected returns the error for a message not allowed in the protocol state.
+func (c *channel) unexpected(tag uint64) error {
+	return fmt.Errorf("ouroboros: unexpected message %v on protocol %v", tag, c.id)
+}
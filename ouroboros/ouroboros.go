@@ -0,0 +1,337 @@
+// Package ouroboros implements cardano.Node with the node-to-client
+// mini-protocols of the Ouroboros network, spoken by cardano-node over
+// its local socket.
+//
+// The mini-protocols are multiplexed over a single connection, dialed on
+// first use and redialed after a failure: LocalStateQuery for the UTxOs,
+// tip, protocol parameters, era history and stake distribution,
+// LocalTxSubmission to submit transactions and LocalTxMonitor to inspect
+// the mempool.
+package ouroboros
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+// ErrNotFound is returned when the ledger has no unspent output for a lookup.
+var ErrNotFound = fmt.Errorf("ouroboros: %w", cardano.ErrNotFound)
+
+// Options are the options of an OuroborosNode.
+type Options struct {
+	// Magic is the network magic, the protocol magic of the network if zero.
+	Magic uint32
+
+	// Dial connects to the node, the socket path is dialed if nil.
+	Dial func(ctx context.Context) (net.Conn, error)
+}
+
+// OuroborosNode implements Node using the node-to-client mini-protocols.
+type OuroborosNode struct {
+	network cardano.Network
+	magic   uint32
+	dial    func(ctx context.Context) (net.Conn, error)
+
+	mu      sync.Mutex
+	mux     *muxer
+	version uint64
+}
+
+// check interface
+var (
+	_ cardano.Node          = (*OuroborosNode)(nil)
+	_ cardano.UTxOByRefNode = (*OuroborosNode)(nil)
+)
+
+// NewNode returns a new instance of OuroborosNode connecting to the node
+// socket at socketPath, e.g. /ipc/node.socket.
+func NewNode(network cardano.Network, socketPath string, opts *Options) *OuroborosNode {
+	o := &OuroborosNode{network: network, magic: network.ProtocolMagic()}
+	o.dial = func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socketPath)
+	}
+	if opts != nil {
+		if opts.Magic != 0 {
+			o.magic = opts.Magic
+		}
+		if opts.Dial != nil {
+			o.dial = opts.Dial
+		}
+	}
+	return o
+}
+
+// Close closes the connection to the node.
+func (o *OuroborosNode) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.mux == nil {
+		return nil
+	}
+	err := o.mux.close()
+	o.mux = nil
+	return err
+}
+
+// Version returns the node-to-client version negotiated with the node, zero
+// before the first request.
+func (o *OuroborosNode) Version() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.version &^ versionN2C
+}
+
+// connect returns the connection to the node, dialing it and negotiating
+// the version if needed.
+func (o *OuroborosNode) connect(ctx context.Context) (*muxer, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.mux != nil && o.mux.closed() == nil {
+		return o.mux, nil
+	}
+
+	conn, err := o.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := newMuxer(conn, false)
+	stop := context.AfterFunc(ctx, func() { m.fail(ctx.Err()) })
+	defer stop()
+	version, err := handshake(m, o.magic)
+	if err != nil {
+		_ = m.close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	o.mux, o.version = m, version
+	return m, nil
+}
+
+// run runs an exchange on the channel of a mini-protocol. The connection
+// is closed if the context is done during the exchange, as the state of
+// the mini-protocol is then unknown.
+func (o *OuroborosNode) run(ctx context.Context, id protocolID, fn func(c *channel) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m, err := o.connect(ctx)
+	if err != nil {
+		return err
+	}
+	return exchange(ctx, m, id, fn)
+}
+
+// exchange runs an exchange on the channel of a mini-protocol of the connection.
+func exchange(ctx context.Context, m *muxer, id protocolID, fn func(c *channel) error) error {
+	if err := m.closed(); err != nil {
+		return err
+	}
+	c := m.channel(id)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() { m.fail(ctx.Err()) })
+	defer stop()
+	if err := fn(c); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// queryState runs queries against the ledger state at the tip of the chain.
+func (o *OuroborosNode) queryState(ctx context.Context, fn func(s *stateQuery) error) error {
+	return o.run(ctx, protocolLocalStateQuery, func(c *channel) error {
+		s := &stateQuery{c: c}
+		if err := s.acquire(); err != nil {
+			return err
+		}
+		err := fn(s)
+		if rerr := s.release(); err == nil {
+			err = rerr
+		}
+		return err
+	})
+}
+
+// monitorMempool runs requests against a snapshot of the mempool.
+func (o *OuroborosNode) monitorMempool(ctx context.Context, fn func(m *mempool) error) error {
+	return o.run(ctx, protocolLocalTxMonitor, func(c *channel) error {
+		m := &mempool{c: c}
+		if _, err := m.acquire(); err != nil {
+			return err
+		}
+		err := fn(m)
+		if rerr := m.release(); err == nil {
+			err = rerr
+		}
+		return err
+	})
+}
+
+func (o *OuroborosNode) UTxOs(ctx context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
+	var utxos []cardano.UTxO
+	err := o.queryState(ctx, func(s *stateQuery) (err error) {
+		utxos, err = s.utxos([]any{shelleyUTxOByAddr, []cardano.Address{addr}})
+		return err
+	})
+	return utxos, err
+}
+
+// UTxOByRef returns the unspent output at index of a transaction, or
+// ErrNotFound if it is unknown or spent.
+func (o *OuroborosNode) UTxOByRef(ctx context.Context, txHash cardano.Hash32, index uint64) (*cardano.UTxO, error) {
+	var utxos []cardano.UTxO
+	err := o.queryState(ctx, func(s *stateQuery) (err error) {
+		in := txIn{TxHash: cbor.NewByteString(txHash), Index: index}
+		utxos, err = s.utxos([]any{shelleyUTxOByTxIns, []txIn{in}})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, ErrNotFound
+	}
+	return &utxos[0], nil
+}
+
+func (o *OuroborosNode) Tip(ctx context.Context) (*cardano.NodeTip, error) {
+	var tip *cardano.NodeTip
+	err := o.queryState(ctx, func(s *stateQuery) (err error) {
+		tip, err = s.tip()
+		return err
+	})
+	return tip, err
+}
+
+func (o *OuroborosNode) SubmitTx(ctx context.Context, tx *cardano.Tx) (*cardano.Hash32, error) {
+	var era cardano.Era
+	err := o.queryState(ctx, func(s *stateQuery) (err error) {
+		era, err = s.currentEra()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.run(ctx, protocolLocalTxSubmission, func(c *channel) error {
+		return submitTx(c, era, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return &txHash, nil
+}
+
+func (o *OuroborosNode) ProtocolParams(ctx context.Context) (*cardano.ProtocolParams, error) {
+	var data cbor.RawMessage
+	err := o.queryState(ctx, func(s *stateQuery) error {
+		return s.queryIfCurrent(shelleyPParams, &data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cardano.NewProtocolParamsFromCBOR(data)
+}
+
+// SlotConfig returns the era history of the chain, as known by the node.
+func (o *OuroborosNode) SlotConfig(ctx context.Context) (*cardano.SlotConfig, error) {
+	var config *cardano.SlotConfig
+	err := o.queryState(ctx, func(s *stateQuery) (err error) {
+		config, err = s.slotConfig()
+		return err
+	})
+	return config, err
+}
+
+// PoolStake is the share of the active stake delegated to a stake pool.
+type PoolStake struct {
+	PoolID     cardano.Hash28
+	Stake      cardano.Rational
+	VRFKeyHash cardano.Hash32
+}
+
+// StakeDistribution returns the stake distribution of the pools, ordered by
+// pool id.
+func (o *OuroborosNode) StakeDistribution(ctx context.Context) ([]PoolStake, error) {
+	var distribution map[cbor.ByteString]struct {
+		_          struct{} `cbor:",toarray"`
+		Stake      cardano.Rational
+		VRFKeyHash cbor.ByteString
+	}
+	err := o.queryState(ctx, func(s *stateQuery) error {
+		return s.queryIfCurrent(shelleyStakeDistr, &distribution)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make([]PoolStake, 0, len(distribution))
+	for poolID, stake := range distribution {
+		pools = append(pools, PoolStake{
+			PoolID:     poolID.Bytes(),
+			Stake:      stake.Stake,
+			VRFKeyHash: stake.VRFKeyHash.Bytes(),
+		})
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return bytes.Compare(pools[i].PoolID, pools[j].PoolID) < 0
+	})
+	return pools, nil
+}
+
+// MempoolTxs returns the transactions in the mempool of the node.
+func (o *OuroborosNode) MempoolTxs(ctx context.Context) ([]*cardano.Tx, error) {
+	var txs []*cardano.Tx
+	err := o.monitorMempool(ctx, func(m *mempool) error {
+		for {
+			tx, err := m.nextTx()
+			if err != nil || tx == nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+	})
+	return txs, err
+}
+
+// MempoolHasTx reports whether the transaction is in the mempool of the node.
+func (o *OuroborosNode) MempoolHasTx(ctx context.Context, txHash cardano.Hash32) (bool, error) {
+	var has bool
+	err := o.monitorMempool(ctx, func(m *mempool) (err error) {
+		has, err = m.hasTx(txHash)
+		return err
+	})
+	return has, err
+}
+
+// MempoolSizes returns the sizes of the mempool of the node.
+func (o *OuroborosNode) MempoolSizes(ctx context.Context) (*MempoolSizes, error) {
+	var sizes *MempoolSizes
+	err := o.monitorMempool(ctx, func(m *mempool) (err error) {
+		sizes, err = m.sizes()
+		return err
+	})
+	return sizes, err
+}
+
+func (o *OuroborosNode) Network() cardano.Network {
+	return o.network
+}
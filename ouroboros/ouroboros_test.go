@@ -0,0 +1,668 @@
+package ouroboros
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/chainsync"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+const testTxID = "030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518"
+
+// handlerFunc returns the reply to a message, or nil if there is none.
+type handlerFunc func(tag uint64, fields []cbor.RawMessage) []any
+
+// replies is returned by a handler, as the only element of the reply, to
+// reply with several messages.
+type replies [][]any
+
+// fakeNode is a scripted node-to-client peer.
+type fakeNode struct {
+	t       *testing.T
+	magic   uint32
+	era     cardano.Era
+	queries map[string]any
+
+	mu       sync.Mutex
+	dials    int
+	handlers map[protocolID]handlerFunc
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	f := &fakeNode{
+		t:        t,
+		magic:    cardano.PreprodProtocolMagic,
+		era:      cardano.ConwayEra,
+		queries:  make(map[string]any),
+		handlers: make(map[protocolID]handlerFunc),
+	}
+	f.handlers[protocolHandshake] = f.handshake
+	f.handlers[protocolLocalStateQuery] = f.stateQuery
+	f.setQuery(queryCurrentEra, f.era)
+	return f
+}
+
+// dial returns a connection to a new peer serving the handlers.
+func (f *fakeNode) dial(ctx context.Context) (net.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dials++
+
+	client, server := net.Pipe()
+	m := newMuxer(server, true)
+	for id, handler := range f.handlers {
+		go f.serve(m.channel(id), handler)
+	}
+	f.t.Cleanup(func() { _ = m.close() })
+	return client, nil
+}
+
+func (f *fakeNode) serve(c *channel, handler handlerFunc) {
+	for {
+		tag, fields, err := c.recv()
+		if err != nil {
+			return
+		}
+		reply := handler(tag, fields)
+		if reply == nil {
+			continue
+		}
+		msgs := [][]any{reply}
+		if r, ok := reply[0].(replies); ok {
+			msgs = r
+		}
+		for _, msg := range msgs {
+			if err := c.send(msg...); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeNode) handshake(tag uint64, fields []cbor.RawMessage) []any {
+	var versions map[uint64]versionData
+	if err := cborDec.Unmarshal(fields[0], &versions); err != nil {
+		f.t.Error(err)
+	}
+	var version uint64
+	for v := range versions {
+		version = max(version, v)
+	}
+	if versions[version].Magic != f.magic {
+		return []any{msgRefuse, []any{2, version, "magic mismatch"}}
+	}
+	return []any{msgAcceptVersion, version, versions[version]}
+}
+
+func (f *fakeNode) stateQuery(tag uint64, fields []cbor.RawMessage) []any {
+	switch tag {
+	case msgAcquireTip:
+		return []any{msgAcquired}
+	case msgQuery:
+		result, ok := f.queries[hex.EncodeToString(fields[0])]
+		if !ok {
+			f.t.Errorf("unexpected query %x", fields[0])
+		}
+		return []any{msgResult, result}
+	case msgRelease:
+		return nil
+	default:
+		f.t.Errorf("unexpected state query message %v", tag)
+		return nil
+	}
+}
+
+// setQuery sets the result of a query.
+func (f *fakeNode) setQuery(q any, result any) {
+	data, err := cborEnc.Marshal(q)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.queries[hex.EncodeToString(data)] = result
+}
+
+// setEraQuery sets the result of a query of the current era.
+func (f *fakeNode) setEraQuery(q any, result any) {
+	f.setQuery([]any{0, []any{0, []any{f.era, q}}}, []any{result})
+}
+
+func (f *fakeNode) node(opts *Options) *OuroborosNode {
+	if opts == nil {
+		opts = &Options{}
+	}
+	opts.Dial = f.dial
+	node := NewNode(cardano.Preprod, "", opts)
+	f.t.Cleanup(func() { _ = node.Close() })
+	return node
+}
+
+func newTestTx(t *testing.T) *cardano.Tx {
+	t.Helper()
+	key := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	payment, err := cardano.NewKeyCredential(key.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := cardano.NewEnterpriseAddress(cardano.Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := cardano.NewHash32(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{MinFeeA: 44, MinFeeB: 155381, CoinsPerUTXOByte: 4310})
+	txBuilder.AddInputs(&cardano.TxInput{TxHash: txHash, Index: 0, Amount: cardano.NewValue(10e6), Spender: &addr})
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.Sign(key.PrvKey())
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestHandshake(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeNode(t)
+	f.setQuery(queryChainPoint, []any{})
+	f.setQuery(queryChainBlockNo, []any{0})
+
+	node := f.node(nil)
+	if _, err := node.Tip(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := node.Version(), uint64(19); got != want {
+		t.Errorf("invalid version: got %v want %v", got, want)
+	}
+
+	node = f.node(&Options{Magic: 42})
+	if _, err := node.Tip(ctx); !errors.Is(err, ErrVersionRefused) {
+		t.Errorf("invalid error: got %v want %v", err, ErrVersionRefused)
+	}
+}
+
+func TestUTxOs(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeNode(t)
+	tx := newTestTx(t)
+	addr := tx.Body.Outputs[0].Address
+	txHash, err := cardano.NewHash32(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// enough outputs for the result to span several segments
+	outputs := make(map[txIn]*cardano.TxOutput)
+	for i := uint64(0); i < 500; i++ {
+		outputs[txIn{TxHash: cbor.NewByteString(txHash), Index: i}] = cardano.NewTxOutput(addr, cardano.NewValue(cardano.Coin(1e6+i)))
+	}
+	f.setEraQuery([]any{shelleyUTxOByAddr, []cardano.Address{addr}}, outputs)
+	ref := txIn{TxHash: cbor.NewByteString(txHash), Index: 3}
+	f.setEraQuery([]any{shelleyUTxOByTxIns, []txIn{ref}}, map[txIn]*cardano.TxOutput{ref: outputs[ref]})
+	missing := txIn{TxHash: cbor.NewByteString(txHash), Index: 500}
+	f.setEraQuery([]any{shelleyUTxOByTxIns, []txIn{missing}}, map[txIn]*cardano.TxOutput{})
+	node := f.node(nil)
+
+	utxos, err := node.UTxOs(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(utxos), 500; got != want {
+		t.Fatalf("invalid number of utxos: got %v want %v", got, want)
+	}
+	for i, utxo := range utxos {
+		if got, want := utxo.Index, uint64(i); got != want {
+			t.Fatalf("invalid index: got %v want %v", got, want)
+		}
+		if got, want := utxo.Amount.Coin, cardano.Coin(1e6+i); got != want {
+			t.Errorf("invalid coin: got %v want %v", got, want)
+		}
+		if got, want := utxo.Spender.Bech32(), addr.Bech32(); got != want {
+			t.Errorf("invalid address: got %v want %v", got, want)
+		}
+	}
+
+	utxo, err := node.UTxOByRef(ctx, txHash, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := utxo.Amount.Coin, cardano.Coin(1e6+3); got != want {
+		t.Errorf("invalid coin: got %v want %v", got, want)
+	}
+	if _, err := node.UTxOByRef(ctx, txHash, 500); !errors.Is(err, ErrNotFound) {
+		t.Errorf("invalid error: got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestEraMismatch(t *testing.T) {
+	f := newFakeNode(t)
+	f.setQuery([]any{0, []any{0, []any{f.era, shelleyPParams}}}, []any{"Babbage", "Conway"})
+
+	_, err := f.node(nil).ProtocolParams(context.Background())
+	var mismatch *EraMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("invalid error: got %v want %T", err, mismatch)
+	}
+	if got, want := mismatch.LedgerEra, "Babbage"; got != want {
+		t.Errorf("invalid ledger era: got %v want %v", got, want)
+	}
+}
+
+func TestTip(t *testing.T) {
+	f := newFakeNode(t)
+	hash, err := hex.DecodeString(testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.setQuery(queryChainPoint, []any{75000000, hash})
+	f.setQuery(queryChainBlockNo, []any{1, 3000000})
+	f.setEraQuery(shelleyEpochNo, 180)
+
+	tip, err := f.node(nil).Tip(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cardano.NodeTip{Block: 3000000, Epoch: 180, Slot: 75000000}
+	if *tip != want {
+		t.Errorf("invalid tip: got %+v want %+v", *tip, want)
+	}
+}
+
+func TestProtocolParams(t *testing.T) {
+	f := newFakeNode(t)
+	want := &cardano.ProtocolParams{
+		MinFeeA:             44,
+		MinFeeB:             155381,
+		MaxBlockBodySize:    90112,
+		MaxTxSize:           16384,
+		MaxBlockHeaderSize:  1100,
+		KeyDeposit:          2000000,
+		PoolDeposit:         500000000,
+		MaxEpoch:            18,
+		NOpt:                500,
+		PoolPledgeInfluence: cardano.Rational{P: 3, Q: 10},
+		ExpansionRate:       cardano.Rational{P: 3, Q: 1000},
+		TreasuryGrowthRate:  cardano.Rational{P: 1, Q: 5},
+		ProtocolVersion:     cardano.ProtocolVersion{Major: 10},
+		MinPoolCost:         170000000,
+		CoinsPerUTXOByte:    4310,
+		CostModels:          cardano.CostModels{cardano.PlutusV3: {100788, 420, 1, 1}},
+		ExecutionCosts: cardano.ExUnitPrices{
+			MemPrice:  cardano.Rational{P: 577, Q: 10000},
+			StepPrice: cardano.Rational{P: 721, Q: 10000000},
+		},
+		MaxTxExUnits:               cardano.ExUnits{Mem: 14000000, Steps: 10000000000},
+		MaxBlockTxExUnits:          cardano.ExUnits{Mem: 62000000, Steps: 20000000000},
+		MaxValueSize:               5000,
+		CollateralPercentage:       150,
+		MaxCollateralInputs:        3,
+		GovActionDeposit:           100000000000,
+		DRepDeposit:                500000000,
+		MinFeeRefScriptCostPerByte: cardano.Rational{P: 15, Q: 1},
+	}
+	f.setEraQuery(shelleyPParams, []any{
+		want.MinFeeA, want.MinFeeB, want.MaxBlockBodySize, want.MaxTxSize, want.MaxBlockHeaderSize,
+		want.KeyDeposit, want.PoolDeposit, want.MaxEpoch, want.NOpt, &want.PoolPledgeInfluence,
+		&want.ExpansionRate, &want.TreasuryGrowthRate, &want.ProtocolVersion, want.MinPoolCost,
+		want.CoinsPerUTXOByte, want.CostModels, &want.ExecutionCosts, &want.MaxTxExUnits,
+		&want.MaxBlockTxExUnits, want.MaxValueSize, want.CollateralPercentage, want.MaxCollateralInputs,
+		&want.PoolVotingThresholds, &want.DRepVotingThresholds, want.CommitteeMinSize,
+		want.CommitteeMaxTermLength, want.GovActionLifetime, want.GovActionDeposit,
+		want.DRepDeposit, want.DRepActivity, &want.MinFeeRefScriptCostPerByte,
+	})
+
+	got, err := f.node(nil).ProtocolParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MinFeeA != want.MinFeeA || got.MinFeeB != want.MinFeeB || got.CoinsPerUTXOByte != want.CoinsPerUTXOByte {
+		t.Errorf("invalid fee parameters: got %+v", got)
+	}
+	if got, want := got.ExecutionCosts.StepPrice, want.ExecutionCosts.StepPrice; got.P != want.P || got.Q != want.Q {
+		t.Errorf("invalid step price: got %v want %v", got, want)
+	}
+	if got, want := got.DRepDeposit, want.DRepDeposit; got != want {
+		t.Errorf("invalid drep deposit: got %v want %v", got, want)
+	}
+	if got, want := got.Era(), cardano.ConwayEra; got != want {
+		t.Errorf("invalid era: got %v want %v", got, want)
+	}
+}
+
+func TestSlotConfig(t *testing.T) {
+	f := newFakeNode(t)
+	byronEnd := []any{uint64(86400e12), 4320, 1}
+	f.setQuery(querySystemStart, []any{2022, 298, 0})
+	f.setQuery(queryEraHistory, []any{
+		[]any{[]any{0, 0, 0}, byronEnd, []any{4320, 20000, []any{0, 864, []any{0}}}},
+		[]any{byronEnd, nil, []any{86400, 1000, []any{0, 25920, []any{0}}, 0}},
+	})
+
+	config, err := f.node(nil).SlotConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(config.Eras), 2; got != want {
+		t.Fatalf("invalid number of eras: got %v want %v", got, want)
+	}
+	wantStart := time.Date(2022, 10, 25, 0, 0, 0, 0, time.UTC)
+	if got := config.SystemStart(); !got.Equal(wantStart) {
+		t.Errorf("invalid system start: got %v want %v", got, wantStart)
+	}
+	shelley := config.Eras[1]
+	if got, want := shelley.StartTime, wantStart.Add(24*time.Hour); !got.Equal(want) {
+		t.Errorf("invalid shelley start time: got %v want %v", got, want)
+	}
+	if shelley.StartSlot != 4320 || shelley.StartEpoch != 1 || shelley.EpochLength != 86400 || shelley.SlotLength != time.Second {
+		t.Errorf("invalid shelley summary: got %+v", shelley)
+	}
+	if got, want := config.SlotToEpoch(4320+86400), uint64(2); got != want {
+		t.Errorf("invalid epoch: got %v want %v", got, want)
+	}
+}
+
+func TestStakeDistribution(t *testing.T) {
+	f := newFakeNode(t)
+	poolA, poolB := make([]byte, 28), make([]byte, 28)
+	poolA[0], poolB[0] = 0xaa, 0xbb
+	vrf := make([]byte, 32)
+	f.setEraQuery(shelleyStakeDistr, map[cbor.ByteString]any{
+		cbor.NewByteString(poolB): []any{&cardano.Rational{P: 2, Q: 3}, vrf},
+		cbor.NewByteString(poolA): []any{&cardano.Rational{P: 1, Q: 3}, vrf},
+	})
+
+	pools, err := f.node(nil).StakeDistribution(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(pools), 2; got != want {
+		t.Fatalf("invalid number of pools: got %v want %v", got, want)
+	}
+	if got, want := pools[0].PoolID.String(), hex.EncodeToString(poolA); got != want {
+		t.Errorf("invalid pool id: got %v want %v", got, want)
+	}
+	if got, want := pools[1].Stake, (cardano.Rational{P: 2, Q: 3}); got.P != want.P || got.Q != want.Q {
+		t.Errorf("invalid stake: got %v want %v", got, want)
+	}
+}
+
+func TestSubmitTx(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeNode(t)
+	tx := newTestTx(t)
+	// A Conway rejection, as encoded by the node: a value not conserved and a
+	// missing vkey witness, wrapped in the UTXO and UTXOW failures, and a
+	// reference scripts size too big.
+	reason, err := hex.DecodeString("820683" +
+		"820182008306" + "1a00989680" + "1a00895440" +
+		"82018202" + "d9010281581c" + "00000000000000000000000000000000000000000000000000000000" +
+		"8306" + "1a000493e0" + "1a00030d40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var submitted [][]byte
+	f.handlers[protocolLocalTxSubmission] = func(tag uint64, fields []cbor.RawMessage) []any {
+		var wrapped struct {
+			_    struct{} `cbor:",toarray"`
+			Era  cardano.Era
+			Body cbor.RawTag
+		}
+		if err := cborDec.Unmarshal(fields[0], &wrapped); err != nil {
+			t.Error(err)
+		}
+		if wrapped.Era != f.era || wrapped.Body.Number != 24 {
+			t.Errorf("invalid submitted transaction: %x", fields[0])
+		}
+		submitted = append(submitted, fields[0])
+		if len(submitted) == 1 {
+			return []any{msgAcceptTx}
+		}
+		return []any{msgRejectTx, cbor.RawMessage(reason)}
+	}
+	node := f.node(nil)
+
+	txHash, err := node.SubmitTx(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := txHash.String(), wantHash.String(); got != want {
+		t.Errorf("invalid tx hash: got %v want %v", got, want)
+	}
+
+	_, err = node.SubmitTx(ctx, tx)
+	var rejection *RejectionError
+	if !errors.As(err, &rejection) {
+		t.Fatalf("invalid error: got %v want %T", err, rejection)
+	}
+	if got, want := len(rejection.Failures), 3; got != want {
+		t.Fatalf("invalid number of failures: got %v want %v", got, want)
+	}
+	for _, target := range []error{cardano.ErrValueNotConservedUTxO, cardano.ErrMissingVKeyWitnessesUTXOW} {
+		if !errors.Is(err, target) {
+			t.Errorf("invalid error: got %v want %v", err, target)
+		}
+	}
+	if errors.Is(err, cardano.ErrFeeTooSmallUTxO) {
+		t.Errorf("unexpected error %v", cardano.ErrFeeTooSmallUTxO)
+	}
+	if got, want := rejection.Failures[0].Message, "10000000 9000000"; got != want {
+		t.Errorf("invalid message: got %q want %q", got, want)
+	}
+	if got, want := rejection.Failures[2].Name, "ConwayTxRefScriptsSizeTooBig"; got != want {
+		t.Errorf("invalid failure name: got %v want %v", got, want)
+	}
+}
+
+func TestMempool(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeNode(t)
+	tx := newTestTx(t)
+	txHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var next int
+	f.handlers[protocolLocalTxMonitor] = func(tag uint64, fields []cbor.RawMessage) []any {
+		switch tag {
+		case msgMonitorAcquire:
+			next = 0
+			return []any{msgMonitorAcquired, 1000}
+		case msgNextTx:
+			next++
+			if next > 1 {
+				return []any{msgReplyNextTx}
+			}
+			return []any{msgReplyNextTx, []any{f.era, cbor.Tag{Number: 24, Content: tx.Bytes()}}}
+		case msgHasTx:
+			var hash []byte
+			_ = cborDec.Unmarshal(fields[0], &hash)
+			return []any{msgReplyHasTx, hex.EncodeToString(hash) == txHash.String()}
+		case msgGetSizes:
+			return []any{msgReplyGetSizes, []any{1000000, len(tx.Bytes()), 1}}
+		default:
+			return nil
+		}
+	}
+	node := f.node(nil)
+
+	txs, err := node.MempoolTxs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(txs), 1; got != want {
+		t.Fatalf("invalid number of txs: got %v want %v", got, want)
+	}
+	if got, _ := txs[0].Hash(); got.String() != txHash.String() {
+		t.Errorf("invalid tx hash: got %v want %v", got, txHash)
+	}
+
+	has, err := node.MempoolHasTx(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !has {
+		t.Error("transaction not found in mempool")
+	}
+	has, err = node.MempoolHasTx(ctx, make(cardano.Hash32, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Error("unexpected transaction in mempool")
+	}
+
+	sizes, err := node.MempoolSizes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sizes.NumberOfTxs, uint32(1); got != want {
+		t.Errorf("invalid number of txs: got %v want %v", got, want)
+	}
+	if got, want := sizes.Size, uint32(len(tx.Bytes())); got != want {
+		t.Errorf("invalid size: got %v want %v", got, want)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	f := newFakeNode(t)
+	f.setQuery(queryChainPoint, []any{})
+	f.setQuery(queryChainBlockNo, []any{0})
+	node := f.node(nil)
+
+	if _, err := node.Tip(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.Tip(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.dials, 1; got != want {
+		t.Errorf("invalid number of dials: got %v want %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := node.Tip(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("invalid error: got %v want %v", err, context.Canceled)
+	}
+
+	if err := node.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.Tip(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.dials, 2; got != want {
+		t.Errorf("invalid number of dials: got %v want %v", got, want)
+	}
+}
+
+func TestChainSync(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeNode(t)
+	tx := newTestTx(t)
+	txHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := cborEnc.Marshal([]any{[]any{11, 1100, make([]byte, 32)}, []byte{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := cborEnc.Marshal([]any{
+		uint64(f.era) + 1,
+		[]any{cbor.RawMessage(header), []any{&tx.Body}, []any{&tx.WitnessSet}, map[uint64]any{}, []any{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	intersection, err := cardano.NewPoint(1000, testTxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip := []any{intersection, 10}
+
+	var next int
+	f.handlers[protocolChainSync] = func(tag uint64, fields []cbor.RawMessage) []any {
+		switch tag {
+		case msgFindIntersect:
+			var points []cardano.Point
+			if err := cborDec.Unmarshal(fields[0], &points); err != nil {
+				t.Error(err)
+			}
+			if len(points) == 1 && points[0].IsOrigin() {
+				return []any{msgIntersectNotFound, tip}
+			}
+			return []any{msgIntersectFound, intersection, tip}
+		case msgRequestNext:
+			next++
+			if next == 1 {
+				return []any{msgRollBackward, intersection, tip}
+			}
+			return []any{replies{
+				{msgAwaitReply},
+				{msgRollForward, cbor.Tag{Number: 24, Content: block}, tip},
+			}}
+		default:
+			t.Errorf("unexpected chain sync message %v", tag)
+			return nil
+		}
+	}
+	node := f.node(nil)
+	cs := node.ChainSync()
+
+	if _, err := cs.Next(ctx); err == nil {
+		t.Fatal("expected error before the intersection")
+	}
+	if _, _, err := cs.FindIntersect(ctx, []cardano.Point{{}}); !errors.Is(err, chainsync.ErrNoIntersection) {
+		t.Fatalf("invalid error: got %v want %v", err, chainsync.ErrNoIntersection)
+	}
+	point, gotTip, err := cs.FindIntersect(ctx, []cardano.Point{intersection, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := point.String(), intersection.String(); got != want {
+		t.Errorf("invalid intersection: got %v want %v", got, want)
+	}
+	if got, want := gotTip.Block, uint64(10); got != want {
+		t.Errorf("invalid tip block: got %v want %v", got, want)
+	}
+
+	event, err := cs.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Direction != chainsync.RollBackward || event.Point.String() != intersection.String() {
+		t.Errorf("invalid rollback: got %+v", event)
+	}
+
+	event, err = cs.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Direction != chainsync.RollForward {
+		t.Fatalf("invalid direction: got %v want %v", event.Direction, chainsync.RollForward)
+	}
+	if got, want := event.Block.Era, f.era; got != want {
+		t.Errorf("invalid era: got %v want %v", got, want)
+	}
+	if event.Block.Slot != 1100 || event.Block.Number != 11 {
+		t.Errorf("invalid block: got slot %v number %v", event.Block.Slot, event.Block.Number)
+	}
+	if len(event.Block.TxHashes) != 1 || event.Block.TxHashes[0].String() != txHash.String() {
+		t.Errorf("invalid tx hashes: got %v want [%v]", event.Block.TxHashes, txHash)
+	}
+}
@@ -0,0 +1,179 @@
+package cardano
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// PlutusData is a CBOR encoded Plutus datum or redeemer.
+type PlutusData []byte
+
+// NewPlutusData returns a new PlutusData from a hex encoded CBOR value.
+func NewPlutusData(cborHex string) (PlutusData, error) {
+	data, err := hex.DecodeString(cborHex)
+	if err != nil {
+		return nil, err
+	}
+	if err := cborDec.Valid(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Hash returns the datum hash using blake2b256.
+func (pd PlutusData) Hash() Hash32 {
+	hash := blake2b.Sum256(pd)
+	return hash[:]
+}
+
+// String returns the hex encoding representation of the PlutusData.
+func (pd PlutusData) String() string {
+	return hex.EncodeToString(pd)
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (pd PlutusData) MarshalCBOR() ([]byte, error) {
+	if len(pd) == 0 {
+		return nil, errors.New("empty plutus data")
+	}
+	return pd, nil
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (pd *PlutusData) UnmarshalCBOR(data []byte) error {
+	*pd = make([]byte, len(data))
+	copy(*pd, data)
+	return nil
+}
+
+// PlutusScript is a serialized Plutus script.
+type PlutusScript []byte
+
+// NewPlutusScript returns a new PlutusScript from a hex encoded script, as found
+// in the cborHex field of a cardano-cli script file.
+func NewPlutusScript(cborHex string) (PlutusScript, error) {
+	data, err := hex.DecodeString(cborHex)
+	if err != nil {
+		return nil, err
+	}
+	var script []byte
+	if err := cborDec.Unmarshal(data, &script); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// ExUnits are the execution units (memory and cpu steps) consumed by a script.
+type ExUnits struct {
+	_     struct{} `cbor:",toarray"`
+	Mem   uint64
+	Steps uint64
+}
+
+// Add computes the addition of two ExUnits and returns the result.
+func (eu ExUnits) Add(rhs ExUnits) ExUnits {
+	return ExUnits{Mem: eu.Mem + rhs.Mem, Steps: eu.Steps + rhs.Steps}
+}
+
+// ExUnitPrices are the prices of the execution units, in lovelace.
+type ExUnitPrices struct {
+	_         struct{} `cbor:",toarray"`
+	MemPrice  Rational
+	StepPrice Rational
+}
+
+// RedeemerTag indicates which script purpose the redeemer is used for.
+type RedeemerTag uint64
+
+const (
+	RedeemerTagSpend RedeemerTag = iota
+	RedeemerTagMint
+	RedeemerTagCert
+	RedeemerTagReward
+	RedeemerTagVoting
+	RedeemerTagProposing
+)
+
+// Redeemer is the data passed to a plutus script along with its execution budget.
+type Redeemer struct {
+	_       struct{} `cbor:",toarray"`
+	Tag     RedeemerTag
+	Index   uint64
+	Data    PlutusData
+	ExUnits ExUnits
+}
+
+// Redeemers is the list of redeemers of a transaction.
+type Redeemers []Redeemer
+
+// ExUnits returns the total execution units of the redeemers.
+func (rs Redeemers) ExUnits() ExUnits {
+	var total ExUnits
+	for _, r := range rs {
+		total = total.Add(r.ExUnits)
+	}
+	return total
+}
+
+// redeemerKey is the key of a redeemer in the conway map format.
+type redeemerKey struct {
+	_     struct{} `cbor:",toarray"`
+	Tag   RedeemerTag
+	Index uint64
+}
+
+// redeemerValue is the value of a redeemer in the conway map format.
+type redeemerValue struct {
+	_       struct{} `cbor:",toarray"`
+	Data    PlutusData
+	ExUnits ExUnits
+}
+
+// redeemerMap encodes redeemers in the conway map format.
+type redeemerMap Redeemers
+
+// MarshalCBOR implements cbor.Marshaler.
+func (rm redeemerMap) MarshalCBOR() ([]byte, error) {
+	m := make(map[redeemerKey]redeemerValue, len(rm))
+	for _, r := range rm {
+		m[redeemerKey{Tag: r.Tag, Index: r.Index}] = redeemerValue{Data: r.Data, ExUnits: r.ExUnits}
+	}
+	return cborEnc.Marshal(m)
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+//
+// Both the legacy array format and the conway map format are supported, the
+// redeemers of a decoded witness set are encoded back in their format.
+func (rs *Redeemers) UnmarshalCBOR(data []byte) error {
+	type redeemers []Redeemer
+
+	if len(data) == 0 || data[0]>>5 != 5 {
+		var list redeemers
+		if err := cborDec.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		*rs = Redeemers(list)
+		return nil
+	}
+
+	var m map[redeemerKey]redeemerValue
+	if err := cborDec.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	list := make(Redeemers, 0, len(m))
+	for k, v := range m {
+		list = append(list, Redeemer{Tag: k.Tag, Index: k.Index, Data: v.Data, ExUnits: v.ExUnits})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Tag != list[j].Tag {
+			return list[i].Tag < list[j].Tag
+		}
+		return list[i].Index < list[j].Index
+	})
+	*rs = list
+	return nil
+}
@@ -34,6 +34,27 @@ func (n Network) String() string {
 	}
 }
 
+// ID returns the network ID carried by the addresses and transactions of the network,
+// that is 1 for the mainnet and 0 for the testnets.
+func (n Network) ID() byte {
+	if n == Mainnet {
+		return 1
+	}
+	return 0
+}
+
+// ProtocolMagic returns the protocol magic of the network.
+func (n Network) ProtocolMagic() uint32 {
+	switch n {
+	case Mainnet:
+		return MainnetProtocolMagic
+	case Preprod:
+		return PreprodProtocolMagic
+	default:
+		return PreviewProtocolMagic
+	}
+}
+
 type BigNum uint64
 
 // Coin represents the Cardano Native Token, in Lovelace.
@@ -43,10 +64,17 @@ func (c *Coin) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.FormatUint(uint64(*c), 10)), nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler.
+// The amount could be encoded either as a number or as a string.
 func (c *Coin) UnmarshalJSON(b []byte) error {
-	var coinStr string
-	if err := json.Unmarshal(b, &coinStr); err != nil {
-		return err
+	if string(b) == "null" {
+		return nil
+	}
+	coinStr := string(b)
+	if len(b) != 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &coinStr); err != nil {
+			return err
+		}
 	}
 	val, err := strconv.ParseUint(coinStr, 10, 64)
 	if err != nil {
@@ -329,6 +357,18 @@ func (ma *MultiAsset) Get(policyID PolicyID) *Assets {
 	return ma.m[policyID.bs]
 }
 
+// removeAsset removes the given asset, and its policy if left empty.
+func (ma *MultiAsset) removeAsset(policyID PolicyID, name AssetName) {
+	assets, ok := ma.m[policyID.bs]
+	if !ok {
+		return
+	}
+	delete(assets.m, name.bs)
+	if len(assets.m) == 0 {
+		delete(ma.m, policyID.bs)
+	}
+}
+
 // Keys returns all the Policies stored in MultiAsset.
 func (ma *MultiAsset) Keys() []PolicyID {
 	policyIDs := []PolicyID{}
@@ -470,6 +510,24 @@ func (m *Mint) MultiAsset() *MultiAsset {
 	return ma
 }
 
+// split returns the minted and the burned assets of the Mint.
+func (m *Mint) split() (minted, burned *MultiAsset) {
+	minted, burned = NewMultiAsset(), NewMultiAsset()
+	for policy, mintAssets := range m.m {
+		for assetName, value := range mintAssets.m {
+			ma := minted
+			if value.Sign() < 0 {
+				ma = burned
+			}
+			if _, ok := ma.m[policy]; !ok {
+				ma.m[policy] = NewAssets()
+			}
+			ma.m[policy].m[assetName] = BigNum(new(big.Int).Abs(value).Uint64())
+		}
+	}
+	return minted, burned
+}
+
 // func (ma *Mint) numPIDs() uint {
 // 	return uint(len(ma.m))
 // }
@@ -596,6 +654,53 @@ type Rational struct {
 	Q uint64
 }
 
+// Rat returns the rational number as a big.Rat.
+// A rational number with a zero denominator is treated as zero.
+func (r Rational) Rat() *big.Rat {
+	if r.Q == 0 {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(
+		new(big.Int).SetUint64(r.P),
+		new(big.Int).SetUint64(r.Q),
+	)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The rational number could be encoded as a decimal number, as a string holding a
+// decimal number or a fraction "p/q", or as an object {"numerator": p, "denominator": q}.
+func (r *Rational) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var fraction struct {
+		Numerator   *uint64 `json:"numerator"`
+		Denominator *uint64 `json:"denominator"`
+	}
+	if err := json.Unmarshal(b, &fraction); err == nil {
+		if fraction.Numerator == nil || fraction.Denominator == nil {
+			return fmt.Errorf("invalid rational %s", b)
+		}
+		r.P, r.Q = *fraction.Numerator, *fraction.Denominator
+		return nil
+	}
+
+	str := string(b)
+	if err := json.Unmarshal(b, &str); err != nil {
+		var num json.Number
+		if err := json.Unmarshal(b, &num); err != nil {
+			return fmt.Errorf("invalid rational %s", b)
+		}
+		str = num.String()
+	}
+	rat, ok := new(big.Rat).SetString(str)
+	if !ok || rat.Sign() < 0 || !rat.Num().IsUint64() || !rat.Denom().IsUint64() {
+		return fmt.Errorf("invalid rational %s", b)
+	}
+	r.P, r.Q = rat.Num().Uint64(), rat.Denom().Uint64()
+	return nil
+}
+
 // MarshalCBOR implements cbor.Marshaler
 func (r *Rational) MarshalCBOR() ([]byte, error) {
 	type rational Rational
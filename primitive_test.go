@@ -1,6 +1,7 @@
 package cardano
 
 import (
+	"encoding/json"
 	"errors"
 	"math/big"
 	"reflect"
@@ -297,3 +298,41 @@ func TestValueArithmetic(t *testing.T) {
 		})
 	}
 }
+
+func TestRationalJSONDecoding(t *testing.T) {
+	testcases := []struct {
+		name    string
+		json    string
+		want    Rational
+		wantErr bool
+	}{
+		{name: "Integer", json: `15`, want: Rational{P: 15, Q: 1}},
+		{name: "Decimal", json: `0.0577`, want: Rational{P: 577, Q: 10000}},
+		{name: "Exponent", json: `7.21e-05`, want: Rational{P: 721, Q: 10000000}},
+		{name: "DecimalString", json: `"0.3"`, want: Rational{P: 3, Q: 10}},
+		{name: "FractionString", json: `"1/5"`, want: Rational{P: 1, Q: 5}},
+		{name: "Object", json: `{"numerator": 577, "denominator": 10000}`, want: Rational{P: 577, Q: 10000}},
+		{name: "Negative", json: `-1`, wantErr: true},
+		{name: "MissingDenominator", json: `{"numerator": 1}`, wantErr: true},
+		{name: "Invalid", json: `"foo"`, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got Rational
+			err := json.Unmarshal([]byte(tc.json), &got)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected error")
+			}
+			if got != tc.want {
+				t.Errorf("invalid rational: got %+v want %+v", got, tc.want)
+			}
+		})
+	}
+}
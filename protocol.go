@@ -1,5 +1,11 @@
 package cardano
 
+import (
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
 // ProtocolParams is a Cardano Protocol Parameters.
 type ProtocolParams struct {
 	MinFeeA              Coin
@@ -19,13 +25,75 @@ type ProtocolParams struct {
 	ProtocolVersion      ProtocolVersion
 	MinPoolCost          Coin
 	CoinsPerUTXOWord     Coin
-	CostModels           any
-	ExecutionCosts       any
-	MaxTxExUnits         any
-	MaxBlockTxExUnits    any
+	CoinsPerUTXOByte     Coin
+	CostModels           CostModels
+	ExecutionCosts       ExUnitPrices
+	MaxTxExUnits         ExUnits
+	MaxBlockTxExUnits    ExUnits
 	MaxValueSize         uint
 	CollateralPercentage uint
 	MaxCollateralInputs  uint
+
+	PoolVotingThresholds       PoolVotingThresholds
+	DRepVotingThresholds       DRepVotingThresholds
+	CommitteeMinSize           uint
+	CommitteeMaxTermLength     uint64
+	GovActionLifetime          uint64
+	GovActionDeposit           Coin
+	DRepDeposit                Coin
+	DRepActivity               uint64
+	MinFeeRefScriptCostPerByte Rational
+}
+
+// Language is a Plutus language version, as identified in cost models.
+type Language uint
+
+const (
+	PlutusV1 Language = iota
+	PlutusV2
+	PlutusV3
+)
+
+// String implements Stringer.
+func (l Language) String() string {
+	switch l {
+	case PlutusV1:
+		return "PlutusV1"
+	case PlutusV2:
+		return "PlutusV2"
+	case PlutusV3:
+		return "PlutusV3"
+	default:
+		return fmt.Sprintf("PlutusV%d", l+1)
+	}
+}
+
+// CostModels are the cost models of the Plutus languages.
+type CostModels map[Language][]int64
+
+// PoolVotingThresholds are the stake pools voting thresholds of the governance actions.
+type PoolVotingThresholds struct {
+	_                     struct{}     `cbor:",toarray"`
+	MotionNoConfidence    UnitInterval `json:"motionNoConfidence"`
+	CommitteeNormal       UnitInterval `json:"committeeNormal"`
+	CommitteeNoConfidence UnitInterval `json:"committeeNoConfidence"`
+	HardForkInitiation    UnitInterval `json:"hardForkInitiation"`
+	PPSecurityGroup       UnitInterval `json:"ppSecurityGroup"`
+}
+
+// DRepVotingThresholds are the DReps voting thresholds of the governance actions.
+type DRepVotingThresholds struct {
+	_                     struct{}     `cbor:",toarray"`
+	MotionNoConfidence    UnitInterval `json:"motionNoConfidence"`
+	CommitteeNormal       UnitInterval `json:"committeeNormal"`
+	CommitteeNoConfidence UnitInterval `json:"committeeNoConfidence"`
+	UpdateConstitution    UnitInterval `json:"updateToConstitution"`
+	HardForkInitiation    UnitInterval `json:"hardForkInitiation"`
+	PPNetworkGroup        UnitInterval `json:"ppNetworkGroup"`
+	PPEconomicGroup       UnitInterval `json:"ppEconomicGroup"`
+	PPTechnicalGroup      UnitInterval `json:"ppTechnicalGroup"`
+	PPGovGroup            UnitInterval `json:"ppGovGroup"`
+	TreasuryWithdrawal    UnitInterval `json:"treasuryWithdrawal"`
 }
 
 // ProtocolVersion is the protocol version number.
@@ -34,3 +102,204 @@ type ProtocolVersion struct {
 	Major uint
 	Minor uint
 }
+
+// Era is a Cardano ledger era.
+type Era uint
+
+const (
+	ByronEra Era = iota
+	ShelleyEra
+	AllegraEra
+	MaryEra
+	AlonzoEra
+	BabbageEra
+	ConwayEra
+)
+
+// String implements Stringer.
+func (e Era) String() string {
+	switch e {
+	case ByronEra:
+		return "Byron"
+	case ShelleyEra:
+		return "Shelley"
+	case AllegraEra:
+		return "Allegra"
+	case MaryEra:
+		return "Mary"
+	case AlonzoEra:
+		return "Alonzo"
+	case BabbageEra:
+		return "Babbage"
+	case ConwayEra:
+		return "Conway"
+	default:
+		return "Unknown"
+	}
+}
+
+// Era returns the ledger era the protocol parameters belong to.
+// If the protocol version is not set, the era is guessed from the
+// parameters used to calculate the minimum UTxO value.
+func (p *ProtocolParams) Era() Era {
+	switch major := p.ProtocolVersion.Major; {
+	case major == 0:
+		if p.CoinsPerUTXOByte != 0 {
+			return BabbageEra
+		}
+		return AlonzoEra
+	case major == 1:
+		return ByronEra
+	case major == 2:
+		return ShelleyEra
+	case major == 3:
+		return AllegraEra
+	case major == 4:
+		return MaryEra
+	case major <= 6:
+		return AlonzoEra
+	case major <= 8:
+		return BabbageEra
+	default:
+		return ConwayEra
+	}
+}
+
+// ProtocolParamUpdate is a proposed update of the protocol parameters,
+// only the parameters set are updated.
+type ProtocolParamUpdate struct {
+	MinFeeA                    *Coin                 `cbor:"0,keyasint,omitempty"`
+	MinFeeB                    *Coin                 `cbor:"1,keyasint,omitempty"`
+	MaxBlockBodySize           *uint                 `cbor:"2,keyasint,omitempty"`
+	MaxTxSize                  *uint                 `cbor:"3,keyasint,omitempty"`
+	MaxBlockHeaderSize         *uint                 `cbor:"4,keyasint,omitempty"`
+	KeyDeposit                 *Coin                 `cbor:"5,keyasint,omitempty"`
+	PoolDeposit                *Coin                 `cbor:"6,keyasint,omitempty"`
+	MaxEpoch                   *uint                 `cbor:"7,keyasint,omitempty"`
+	NOpt                       *uint                 `cbor:"8,keyasint,omitempty"`
+	PoolPledgeInfluence        *Rational             `cbor:"9,keyasint,omitempty"`
+	ExpansionRate              *UnitInterval         `cbor:"10,keyasint,omitempty"`
+	TreasuryGrowthRate         *UnitInterval         `cbor:"11,keyasint,omitempty"`
+	D                          *UnitInterval         `cbor:"12,keyasint,omitempty"`
+	ProtocolVersion            *ProtocolVersion      `cbor:"14,keyasint,omitempty"`
+	MinPoolCost                *Coin                 `cbor:"16,keyasint,omitempty"`
+	CoinsPerUTXOByte           *Coin                 `cbor:"17,keyasint,omitempty"`
+	CostModels                 CostModels            `cbor:"18,keyasint,omitempty"`
+	ExecutionCosts             *ExUnitPrices         `cbor:"19,keyasint,omitempty"`
+	MaxTxExUnits               *ExUnits              `cbor:"20,keyasint,omitempty"`
+	MaxBlockTxExUnits          *ExUnits              `cbor:"21,keyasint,omitempty"`
+	MaxValueSize               *uint                 `cbor:"22,keyasint,omitempty"`
+	CollateralPercentage       *uint                 `cbor:"23,keyasint,omitempty"`
+	MaxCollateralInputs        *uint                 `cbor:"24,keyasint,omitempty"`
+	PoolVotingThresholds       *PoolVotingThresholds `cbor:"25,keyasint,omitempty"`
+	DRepVotingThresholds       *DRepVotingThresholds `cbor:"26,keyasint,omitempty"`
+	CommitteeMinSize           *uint                 `cbor:"27,keyasint,omitempty"`
+	CommitteeMaxTermLength     *uint64               `cbor:"28,keyasint,omitempty"`
+	GovActionLifetime          *uint64               `cbor:"29,keyasint,omitempty"`
+	GovActionDeposit           *Coin                 `cbor:"30,keyasint,omitempty"`
+	DRepDeposit                *Coin                 `cbor:"31,keyasint,omitempty"`
+	DRepActivity               *uint64               `cbor:"32,keyasint,omitempty"`
+	MinFeeRefScriptCostPerByte *Rational             `cbor:"33,keyasint,omitempty"`
+}
+
+// Bytes returns the CBOR encoding of the ProtocolParamUpdate as bytes.
+func (u *ProtocolParamUpdate) Bytes() ([]byte, error) {
+	return cborEnc.Marshal(u)
+}
+
+// Update is a proposal of the genesis delegates to update the protocol parameters
+// at a given epoch, used up to the Babbage era.
+type Update struct {
+	m     map[cbor.ByteString]*ProtocolParamUpdate
+	Epoch uint64
+}
+
+// NewUpdate returns a new empty Update for the given epoch.
+func NewUpdate(epoch uint64) *Update {
+	return &Update{m: make(map[cbor.ByteString]*ProtocolParamUpdate), Epoch: epoch}
+}
+
+// Set sets the proposed update of a given genesis delegate.
+func (u *Update) Set(genesisHash Hash28, update *ProtocolParamUpdate) *Update {
+	u.m[cbor.NewByteString(genesisHash)] = update
+	return u
+}
+
+// Get returns the proposed update of a given genesis delegate.
+func (u *Update) Get(genesisHash Hash28) *ProtocolParamUpdate {
+	return u.m[cbor.NewByteString(genesisHash)]
+}
+
+// Keys returns the genesis delegates that proposed an update.
+func (u *Update) Keys() []Hash28 {
+	genesisHashes := []Hash28{}
+	for k := range u.m {
+		genesisHashes = append(genesisHashes, k.Bytes())
+	}
+	return genesisHashes
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (u *Update) MarshalCBOR() ([]byte, error) {
+	return cborEnc.Marshal([]any{u.m, u.Epoch})
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (u *Update) UnmarshalCBOR(data []byte) error {
+	var update struct {
+		_               struct{} `cbor:",toarray"`
+		ProposedUpdates map[cbor.ByteString]*ProtocolParamUpdate
+		Epoch           uint64
+	}
+	if err := cborDec.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	u.m = update.ProposedUpdates
+	u.Epoch = update.Epoch
+	return nil
+}
+
+// ledgerFields returns the protocol parameters in the order of their ledger
+// CBOR encoding, the Babbage parameters followed by the ones added by Conway.
+func (p *ProtocolParams) ledgerFields() (babbage, conway []any) {
+	babbage = []any{
+		&p.MinFeeA, &p.MinFeeB, &p.MaxBlockBodySize, &p.MaxTxSize, &p.MaxBlockHeaderSize,
+		&p.KeyDeposit, &p.PoolDeposit, &p.MaxEpoch, &p.NOpt, &p.PoolPledgeInfluence,
+		&p.ExpansionRate, &p.TreasuryGrowthRate, &p.ProtocolVersion, &p.MinPoolCost,
+		&p.CoinsPerUTXOByte, &p.CostModels, &p.ExecutionCosts, &p.MaxTxExUnits,
+		&p.MaxBlockTxExUnits, &p.MaxValueSize, &p.CollateralPercentage, &p.MaxCollateralInputs,
+	}
+	conway = []any{
+		&p.PoolVotingThresholds, &p.DRepVotingThresholds, &p.CommitteeMinSize,
+		&p.CommitteeMaxTermLength, &p.GovActionLifetime, &p.GovActionDeposit,
+		&p.DRepDeposit, &p.DRepActivity, &p.MinFeeRefScriptCostPerByte,
+	}
+	return babbage, conway
+}
+
+// NewProtocolParamsFromCBOR returns the protocol parameters from their ledger CBOR
+// encoding, as returned by the local state query of the node in the Babbage and
+// Conway eras.
+func NewProtocolParamsFromCBOR(data []byte) (*ProtocolParams, error) {
+	var raw []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	pp := &ProtocolParams{}
+	babbage, conway := pp.ledgerFields()
+	fields := babbage
+	switch len(raw) {
+	case len(babbage):
+	case len(babbage) + len(conway):
+		fields = append(fields, conway...)
+	default:
+		return nil, fmt.Errorf("invalid number of protocol parameters: %v", len(raw))
+	}
+	for i, field := range fields {
+		if err := cborDec.Unmarshal(raw[i], field); err != nil {
+			return nil, fmt.Errorf("invalid protocol parameter %v: %w", i, err)
+		}
+	}
+	return pp, nil
+}
@@ -0,0 +1,517 @@
+package cardano
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The languages could be named as in cardano-cli ("PlutusV1", "PlutusScriptV1") or
+// in Ogmios ("plutus:v1"). The cost models could be encoded either as a list or as an
+// object of named parameters, ordered by name. The PlutusV3 parameters are not ordered
+// by name in the ledger, so a PlutusV3 cost model must be a list.
+func (cm *CostModels) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	costModels := CostModels{}
+	for name, rawModel := range raw {
+		lang, err := parseLanguage(name)
+		if err != nil {
+			return err
+		}
+
+		var model []int64
+		if err := json.Unmarshal(rawModel, &model); err == nil {
+			costModels[lang] = model
+			continue
+		}
+
+		var namedModel map[string]int64
+		if err := json.Unmarshal(rawModel, &namedModel); err != nil {
+			return fmt.Errorf("invalid %v cost model: %w", lang, err)
+		}
+		if lang == PlutusV3 {
+			return fmt.Errorf("invalid %v cost model: named parameters are not supported", lang)
+		}
+		paramNames := make([]string, 0, len(namedModel))
+		for paramName := range namedModel {
+			paramNames = append(paramNames, paramName)
+		}
+		sort.Strings(paramNames)
+		model = make([]int64, len(paramNames))
+		for i, paramName := range paramNames {
+			model[i] = namedModel[paramName]
+		}
+		costModels[lang] = model
+	}
+	*cm = costModels
+
+	return nil
+}
+
+func parseLanguage(name string) (Language, error) {
+	switch strings.ToLower(name) {
+	case "plutusv1", "plutusscriptv1", "plutus:v1":
+		return PlutusV1, nil
+	case "plutusv2", "plutusscriptv2", "plutus:v2":
+		return PlutusV2, nil
+	case "plutusv3", "plutusscriptv3", "plutus:v3":
+		return PlutusV3, nil
+	}
+	return 0, fmt.Errorf("unknown plutus language %q", name)
+}
+
+// jsonUint is an unsigned integer encoded either as a number or as a string.
+type jsonUint uint64
+
+func (u *jsonUint) UnmarshalJSON(b []byte) error {
+	str := string(b)
+	if str == "null" {
+		return nil
+	}
+	if len(b) != 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &str); err != nil {
+			return err
+		}
+	}
+	val, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return err
+	}
+	*u = jsonUint(val)
+	return nil
+}
+
+type cliExUnits struct {
+	Memory uint64 `json:"memory"`
+	Steps  uint64 `json:"steps"`
+}
+
+// cliProtocolParams is the format of the cardano-cli query protocol-parameters output.
+type cliProtocolParams struct {
+	TxFeePerByte        Coin     `json:"txFeePerByte"`
+	TxFeeFixed          Coin     `json:"txFeeFixed"`
+	MaxBlockBodySize    uint     `json:"maxBlockBodySize"`
+	MaxTxSize           uint     `json:"maxTxSize"`
+	MaxBlockHeaderSize  uint     `json:"maxBlockHeaderSize"`
+	StakeAddressDeposit Coin     `json:"stakeAddressDeposit"`
+	StakePoolDeposit    Coin     `json:"stakePoolDeposit"`
+	PoolRetireMaxEpoch  uint     `json:"poolRetireMaxEpoch"`
+	StakePoolTargetNum  uint     `json:"stakePoolTargetNum"`
+	PoolPledgeInfluence Rational `json:"poolPledgeInfluence"`
+	MonetaryExpansion   Rational `json:"monetaryExpansion"`
+	TreasuryCut         Rational `json:"treasuryCut"`
+	Decentralization    Rational `json:"decentralization"`
+	MinPoolCost         Coin     `json:"minPoolCost"`
+	UTxOCostPerWord     Coin     `json:"utxoCostPerWord"`
+	UTxOCostPerByte     Coin     `json:"utxoCostPerByte"`
+	ProtocolVersion     struct {
+		Major uint `json:"major"`
+		Minor uint `json:"minor"`
+	} `json:"protocolVersion"`
+	CostModels          CostModels `json:"costModels"`
+	ExecutionUnitPrices struct {
+		PriceMemory Rational `json:"priceMemory"`
+		PriceSteps  Rational `json:"priceSteps"`
+	} `json:"executionUnitPrices"`
+	MaxTxExecutionUnits        cliExUnits           `json:"maxTxExecutionUnits"`
+	MaxBlockExecutionUnits     cliExUnits           `json:"maxBlockExecutionUnits"`
+	MaxValueSize               uint                 `json:"maxValueSize"`
+	CollateralPercentage       uint                 `json:"collateralPercentage"`
+	MaxCollateralInputs        uint                 `json:"maxCollateralInputs"`
+	PoolVotingThresholds       PoolVotingThresholds `json:"poolVotingThresholds"`
+	DRepVotingThresholds       DRepVotingThresholds `json:"dRepVotingThresholds"`
+	CommitteeMinSize           uint                 `json:"committeeMinSize"`
+	CommitteeMaxTermLength     uint64               `json:"committeeMaxTermLength"`
+	GovActionLifetime          uint64               `json:"govActionLifetime"`
+	GovActionDeposit           Coin                 `json:"govActionDeposit"`
+	DRepDeposit                Coin                 `json:"dRepDeposit"`
+	DRepActivity               uint64               `json:"dRepActivity"`
+	MinFeeRefScriptCostPerByte Rational             `json:"minFeeRefScriptCostPerByte"`
+}
+
+// NewProtocolParamsFromCardanoCLIJSON parses the output of cardano-cli query protocol-parameters.
+func NewProtocolParamsFromCardanoCLIJSON(data []byte) (*ProtocolParams, error) {
+	var pp cliProtocolParams
+	if err := json.Unmarshal(data, &pp); err != nil {
+		return nil, fmt.Errorf("invalid cardano-cli protocol parameters: %w", err)
+	}
+	return &ProtocolParams{
+		MinFeeA:             pp.TxFeePerByte,
+		MinFeeB:             pp.TxFeeFixed,
+		MaxBlockBodySize:    pp.MaxBlockBodySize,
+		MaxTxSize:           pp.MaxTxSize,
+		MaxBlockHeaderSize:  pp.MaxBlockHeaderSize,
+		KeyDeposit:          pp.StakeAddressDeposit,
+		PoolDeposit:         pp.StakePoolDeposit,
+		MaxEpoch:            pp.PoolRetireMaxEpoch,
+		NOpt:                pp.StakePoolTargetNum,
+		PoolPledgeInfluence: pp.PoolPledgeInfluence,
+		ExpansionRate:       pp.MonetaryExpansion,
+		TreasuryGrowthRate:  pp.TreasuryCut,
+		D:                   pp.Decentralization,
+		ProtocolVersion: ProtocolVersion{
+			Major: pp.ProtocolVersion.Major,
+			Minor: pp.ProtocolVersion.Minor,
+		},
+		MinPoolCost:      pp.MinPoolCost,
+		CoinsPerUTXOWord: pp.UTxOCostPerWord,
+		CoinsPerUTXOByte: pp.UTxOCostPerByte,
+		CostModels:       pp.CostModels,
+		ExecutionCosts: ExUnitPrices{
+			MemPrice:  pp.ExecutionUnitPrices.PriceMemory,
+			StepPrice: pp.ExecutionUnitPrices.PriceSteps,
+		},
+		MaxTxExUnits:               ExUnits{Mem: pp.MaxTxExecutionUnits.Memory, Steps: pp.MaxTxExecutionUnits.Steps},
+		MaxBlockTxExUnits:          ExUnits{Mem: pp.MaxBlockExecutionUnits.Memory, Steps: pp.MaxBlockExecutionUnits.Steps},
+		MaxValueSize:               pp.MaxValueSize,
+		CollateralPercentage:       pp.CollateralPercentage,
+		MaxCollateralInputs:        pp.MaxCollateralInputs,
+		PoolVotingThresholds:       pp.PoolVotingThresholds,
+		DRepVotingThresholds:       pp.DRepVotingThresholds,
+		CommitteeMinSize:           pp.CommitteeMinSize,
+		CommitteeMaxTermLength:     pp.CommitteeMaxTermLength,
+		GovActionLifetime:          pp.GovActionLifetime,
+		GovActionDeposit:           pp.GovActionDeposit,
+		DRepDeposit:                pp.DRepDeposit,
+		DRepActivity:               pp.DRepActivity,
+		MinFeeRefScriptCostPerByte: pp.MinFeeRefScriptCostPerByte,
+	}, nil
+}
+
+// rawJSON is a JSON value decoded on demand.
+type rawJSON = json.RawMessage
+
+// blockfrostProtocolParams is the format of the Blockfrost epochs parameters endpoints.
+type blockfrostProtocolParams struct {
+	MinFeeA                    Coin       `json:"min_fee_a"`
+	MinFeeB                    Coin       `json:"min_fee_b"`
+	MaxBlockSize               uint       `json:"max_block_size"`
+	MaxTxSize                  uint       `json:"max_tx_size"`
+	MaxBlockHeaderSize         uint       `json:"max_block_header_size"`
+	KeyDeposit                 Coin       `json:"key_deposit"`
+	PoolDeposit                Coin       `json:"pool_deposit"`
+	EMax                       uint       `json:"e_max"`
+	NOpt                       uint       `json:"n_opt"`
+	A0                         Rational   `json:"a0"`
+	Rho                        Rational   `json:"rho"`
+	Tau                        Rational   `json:"tau"`
+	DecentralisationParam      Rational   `json:"decentralisation_param"`
+	ProtocolMajorVer           uint       `json:"protocol_major_ver"`
+	ProtocolMinorVer           uint       `json:"protocol_minor_ver"`
+	MinPoolCost                Coin       `json:"min_pool_cost"`
+	CostModels                 rawJSON    `json:"cost_models"`
+	CostModelsRaw              CostModels `json:"cost_models_raw"`
+	PriceMem                   Rational   `json:"price_mem"`
+	PriceStep                  Rational   `json:"price_step"`
+	MaxTxExMem                 jsonUint   `json:"max_tx_ex_mem"`
+	MaxTxExSteps               jsonUint   `json:"max_tx_ex_steps"`
+	MaxBlockExMem              jsonUint   `json:"max_block_ex_mem"`
+	MaxBlockExSteps            jsonUint   `json:"max_block_ex_steps"`
+	MaxValSize                 jsonUint   `json:"max_val_size"`
+	CollateralPercent          uint       `json:"collateral_percent"`
+	MaxCollateralInputs        uint       `json:"max_collateral_inputs"`
+	CoinsPerUTxOSize           Coin       `json:"coins_per_utxo_size"`
+	CoinsPerUTxOWord           Coin       `json:"coins_per_utxo_word"`
+	PvtMotionNoConfidence      Rational   `json:"pvt_motion_no_confidence"`
+	PvtCommitteeNormal         Rational   `json:"pvt_committee_normal"`
+	PvtCommitteeNoConfidence   Rational   `json:"pvt_committee_no_confidence"`
+	PvtHardForkInitiation      Rational   `json:"pvt_hard_fork_initiation"`
+	PvtPPSecurityGroup         Rational   `json:"pvt_p_p_security_group"`
+	PvtppSecurityGroup         Rational   `json:"pvtpp_security_group"`
+	DvtMotionNoConfidence      Rational   `json:"dvt_motion_no_confidence"`
+	DvtCommitteeNormal         Rational   `json:"dvt_committee_normal"`
+	DvtCommitteeNoConfidence   Rational   `json:"dvt_committee_no_confidence"`
+	DvtUpdateToConstitution    Rational   `json:"dvt_update_to_constitution"`
+	DvtHardForkInitiation      Rational   `json:"dvt_hard_fork_initiation"`
+	DvtPPNetworkGroup          Rational   `json:"dvt_p_p_network_group"`
+	DvtPPEconomicGroup         Rational   `json:"dvt_p_p_economic_group"`
+	DvtPPTechnicalGroup        Rational   `json:"dvt_p_p_technical_group"`
+	DvtPPGovGroup              Rational   `json:"dvt_p_p_gov_group"`
+	DvtTreasuryWithdrawal      Rational   `json:"dvt_treasury_withdrawal"`
+	CommitteeMinSize           jsonUint   `json:"committee_min_size"`
+	CommitteeMaxTermLength     jsonUint   `json:"committee_max_term_length"`
+	GovActionLifetime          jsonUint   `json:"gov_action_lifetime"`
+	GovActionDeposit           Coin       `json:"gov_action_deposit"`
+	DRepDeposit                Coin       `json:"drep_deposit"`
+	DRepActivity               jsonUint   `json:"drep_activity"`
+	MinFeeRefScriptCostPerByte Rational   `json:"min_fee_ref_script_cost_per_byte"`
+}
+
+// NewProtocolParamsFromBlockfrostJSON parses the response of the Blockfrost
+// epochs/{number}/parameters and epochs/latest/parameters endpoints.
+func NewProtocolParamsFromBlockfrostJSON(data []byte) (*ProtocolParams, error) {
+	var pp blockfrostProtocolParams
+	if err := json.Unmarshal(data, &pp); err != nil {
+		return nil, fmt.Errorf("invalid blockfrost protocol parameters: %w", err)
+	}
+	return pp.protocolParams()
+}
+
+func (pp *blockfrostProtocolParams) protocolParams() (*ProtocolParams, error) {
+	// The named cost models are only decoded without the raw ones, as the
+	// PlutusV3 parameters cannot be ordered by name.
+	costModels := pp.CostModelsRaw
+	if len(costModels) == 0 && len(pp.CostModels) != 0 && string(pp.CostModels) != "null" {
+		if err := json.Unmarshal(pp.CostModels, &costModels); err != nil {
+			return nil, fmt.Errorf("invalid cost models: %w", err)
+		}
+	}
+	ppSecurityGroup := pp.PvtPPSecurityGroup
+	if ppSecurityGroup.Q == 0 {
+		ppSecurityGroup = pp.PvtppSecurityGroup
+	}
+
+	return &ProtocolParams{
+		MinFeeA:             pp.MinFeeA,
+		MinFeeB:             pp.MinFeeB,
+		MaxBlockBodySize:    pp.MaxBlockSize,
+		MaxTxSize:           pp.MaxTxSize,
+		MaxBlockHeaderSize:  pp.MaxBlockHeaderSize,
+		KeyDeposit:          pp.KeyDeposit,
+		PoolDeposit:         pp.PoolDeposit,
+		MaxEpoch:            pp.EMax,
+		NOpt:                pp.NOpt,
+		PoolPledgeInfluence: pp.A0,
+		ExpansionRate:       pp.Rho,
+		TreasuryGrowthRate:  pp.Tau,
+		D:                   pp.DecentralisationParam,
+		ProtocolVersion: ProtocolVersion{
+			Major: pp.ProtocolMajorVer,
+			Minor: pp.ProtocolMinorVer,
+		},
+		MinPoolCost:          pp.MinPoolCost,
+		CoinsPerUTXOWord:     pp.CoinsPerUTxOWord,
+		CoinsPerUTXOByte:     pp.CoinsPerUTxOSize,
+		CostModels:           costModels,
+		ExecutionCosts:       ExUnitPrices{MemPrice: pp.PriceMem, StepPrice: pp.PriceStep},
+		MaxTxExUnits:         ExUnits{Mem: uint64(pp.MaxTxExMem), Steps: uint64(pp.MaxTxExSteps)},
+		MaxBlockTxExUnits:    ExUnits{Mem: uint64(pp.MaxBlockExMem), Steps: uint64(pp.MaxBlockExSteps)},
+		MaxValueSize:         uint(pp.MaxValSize),
+		CollateralPercentage: pp.CollateralPercent,
+		MaxCollateralInputs:  pp.MaxCollateralInputs,
+		PoolVotingThresholds: PoolVotingThresholds{
+			MotionNoConfidence:    pp.PvtMotionNoConfidence,
+			CommitteeNormal:       pp.PvtCommitteeNormal,
+			CommitteeNoConfidence: pp.PvtCommitteeNoConfidence,
+			HardForkInitiation:    pp.PvtHardForkInitiation,
+			PPSecurityGroup:       ppSecurityGroup,
+		},
+		DRepVotingThresholds: DRepVotingThresholds{
+			MotionNoConfidence:    pp.DvtMotionNoConfidence,
+			CommitteeNormal:       pp.DvtCommitteeNormal,
+			CommitteeNoConfidence: pp.DvtCommitteeNoConfidence,
+			UpdateConstitution:    pp.DvtUpdateToConstitution,
+			HardForkInitiation:    pp.DvtHardForkInitiation,
+			PPNetworkGroup:        pp.DvtPPNetworkGroup,
+			PPEconomicGroup:       pp.DvtPPEconomicGroup,
+			PPTechnicalGroup:      pp.DvtPPTechnicalGroup,
+			PPGovGroup:            pp.DvtPPGovGroup,
+			TreasuryWithdrawal:    pp.DvtTreasuryWithdrawal,
+		},
+		CommitteeMinSize:           uint(pp.CommitteeMinSize),
+		CommitteeMaxTermLength:     uint64(pp.CommitteeMaxTermLength),
+		GovActionLifetime:          uint64(pp.GovActionLifetime),
+		GovActionDeposit:           pp.GovActionDeposit,
+		DRepDeposit:                pp.DRepDeposit,
+		DRepActivity:               uint64(pp.DRepActivity),
+		MinFeeRefScriptCostPerByte: pp.MinFeeRefScriptCostPerByte,
+	}, nil
+}
+
+// koiosProtocolParams is the format of the Koios epoch_params endpoint. Both
+// Koios and Blockfrost use the db-sync names, except for the fields below.
+type koiosProtocolParams struct {
+	blockfrostProtocolParams
+	MaxBhSize          uint     `json:"max_bh_size"`
+	MaxEpoch           uint     `json:"max_epoch"`
+	OptimalPoolCount   uint     `json:"optimal_pool_count"`
+	Influence          Rational `json:"influence"`
+	MonetaryExpandRate Rational `json:"monetary_expand_rate"`
+	TreasuryGrowthRate Rational `json:"treasury_growth_rate"`
+	Decentralisation   Rational `json:"decentralisation"`
+	ProtocolMajor      uint     `json:"protocol_major"`
+	ProtocolMinor      uint     `json:"protocol_minor"`
+}
+
+// NewProtocolParamsFromKoiosJSON parses an element of the response of the
+// Koios epoch_params endpoint.
+func NewProtocolParamsFromKoiosJSON(data []byte) (*ProtocolParams, error) {
+	var pp koiosProtocolParams
+	if err := json.Unmarshal(data, &pp); err != nil {
+		return nil, fmt.Errorf("invalid koios protocol parameters: %w", err)
+	}
+	pp.MaxBlockHeaderSize = pp.MaxBhSize
+	pp.EMax = pp.MaxEpoch
+	pp.NOpt = pp.OptimalPoolCount
+	pp.A0 = pp.Influence
+	pp.Rho = pp.MonetaryExpandRate
+	pp.Tau = pp.TreasuryGrowthRate
+	pp.DecentralisationParam = pp.Decentralisation
+	pp.ProtocolMajorVer = pp.ProtocolMajor
+	pp.ProtocolMinorVer = pp.ProtocolMinor
+	return pp.blockfrostProtocolParams.protocolParams()
+}
+
+type ogmiosLovelace struct {
+	Ada struct {
+		Lovelace Coin `json:"lovelace"`
+	} `json:"ada"`
+}
+
+type ogmiosBytes struct {
+	Bytes uint `json:"bytes"`
+}
+
+type ogmiosExUnits struct {
+	Memory uint64 `json:"memory"`
+	CPU    uint64 `json:"cpu"`
+}
+
+type ogmiosCommitteeThresholds struct {
+	Default             Rational `json:"default"`
+	StateOfNoConfidence Rational `json:"stateOfNoConfidence"`
+}
+
+// ogmiosProtocolParams is the format of the Ogmios v6 queryLedgerState/protocolParameters result.
+type ogmiosProtocolParams struct {
+	MinFeeCoefficient      Coin           `json:"minFeeCoefficient"`
+	MinFeeConstant         ogmiosLovelace `json:"minFeeConstant"`
+	MinFeeReferenceScripts struct {
+		Base Rational `json:"base"`
+	} `json:"minFeeReferenceScripts"`
+	MaxBlockBodySize              ogmiosBytes    `json:"maxBlockBodySize"`
+	MaxBlockHeaderSize            ogmiosBytes    `json:"maxBlockHeaderSize"`
+	MaxTransactionSize            ogmiosBytes    `json:"maxTransactionSize"`
+	StakeCredentialDeposit        ogmiosLovelace `json:"stakeCredentialDeposit"`
+	StakePoolDeposit              ogmiosLovelace `json:"stakePoolDeposit"`
+	StakePoolRetirementEpochBound uint           `json:"stakePoolRetirementEpochBound"`
+	DesiredNumberOfStakePools     uint           `json:"desiredNumberOfStakePools"`
+	StakePoolPledgeInfluence      Rational       `json:"stakePoolPledgeInfluence"`
+	MonetaryExpansion             Rational       `json:"monetaryExpansion"`
+	TreasuryExpansion             Rational       `json:"treasuryExpansion"`
+	FederatedBlockProductionRatio Rational       `json:"federatedBlockProductionRatio"`
+	MinStakePoolCost              ogmiosLovelace `json:"minStakePoolCost"`
+	MinUTxODepositCoefficient     Coin           `json:"minUtxoDepositCoefficient"`
+	PlutusCostModels              CostModels     `json:"plutusCostModels"`
+	ScriptExecutionPrices         struct {
+		Memory Rational `json:"memory"`
+		CPU    Rational `json:"cpu"`
+	} `json:"scriptExecutionPrices"`
+	MaxExecutionUnitsPerTransaction ogmiosExUnits `json:"maxExecutionUnitsPerTransaction"`
+	MaxExecutionUnitsPerBlock       ogmiosExUnits `json:"maxExecutionUnitsPerBlock"`
+	MaxValueSize                    ogmiosBytes   `json:"maxValueSize"`
+	CollateralPercentage            uint          `json:"collateralPercentage"`
+	MaxCollateralInputs             uint          `json:"maxCollateralInputs"`
+	Version                         struct {
+		Major uint `json:"major"`
+		Minor uint `json:"minor"`
+	} `json:"version"`
+	StakePoolVotingThresholds struct {
+		NoConfidence             Rational                  `json:"noConfidence"`
+		ConstitutionalCommittee  ogmiosCommitteeThresholds `json:"constitutionalCommittee"`
+		HardForkInitiation       Rational                  `json:"hardForkInitiation"`
+		ProtocolParametersUpdate struct {
+			Security Rational `json:"security"`
+		} `json:"protocolParametersUpdate"`
+	} `json:"stakePoolVotingThresholds"`
+	DelegateRepresentativeVotingThresholds struct {
+		NoConfidence             Rational                  `json:"noConfidence"`
+		ConstitutionalCommittee  ogmiosCommitteeThresholds `json:"constitutionalCommittee"`
+		Constitution             Rational                  `json:"constitution"`
+		HardForkInitiation       Rational                  `json:"hardForkInitiation"`
+		ProtocolParametersUpdate struct {
+			Network    Rational `json:"network"`
+			Economic   Rational `json:"economic"`
+			Technical  Rational `json:"technical"`
+			Governance Rational `json:"governance"`
+		} `json:"protocolParametersUpdate"`
+		TreasuryWithdrawals Rational `json:"treasuryWithdrawals"`
+	} `json:"delegateRepresentativeVotingThresholds"`
+	ConstitutionalCommitteeMinSize       uint           `json:"constitutionalCommitteeMinSize"`
+	ConstitutionalCommitteeMaxTermLength uint64         `json:"constitutionalCommitteeMaxTermLength"`
+	GovernanceActionLifetime             uint64         `json:"governanceActionLifetime"`
+	GovernanceActionDeposit              ogmiosLovelace `json:"governanceActionDeposit"`
+	DelegateRepresentativeDeposit        ogmiosLovelace `json:"delegateRepresentativeDeposit"`
+	DelegateRepresentativeMaxIdleTime    uint64         `json:"delegateRepresentativeMaxIdleTime"`
+}
+
+// NewProtocolParamsFromOgmiosJSON parses the result of the Ogmios v6
+// queryLedgerState/protocolParameters method.
+func NewProtocolParamsFromOgmiosJSON(data []byte) (*ProtocolParams, error) {
+	var pp ogmiosProtocolParams
+	if err := json.Unmarshal(data, &pp); err != nil {
+		return nil, fmt.Errorf("invalid ogmios protocol parameters: %w", err)
+	}
+	pvt := pp.StakePoolVotingThresholds
+	dvt := pp.DelegateRepresentativeVotingThresholds
+	return &ProtocolParams{
+		MinFeeA:             pp.MinFeeCoefficient,
+		MinFeeB:             pp.MinFeeConstant.Ada.Lovelace,
+		MaxBlockBodySize:    pp.MaxBlockBodySize.Bytes,
+		MaxTxSize:           pp.MaxTransactionSize.Bytes,
+		MaxBlockHeaderSize:  pp.MaxBlockHeaderSize.Bytes,
+		KeyDeposit:          pp.StakeCredentialDeposit.Ada.Lovelace,
+		PoolDeposit:         pp.StakePoolDeposit.Ada.Lovelace,
+		MaxEpoch:            pp.StakePoolRetirementEpochBound,
+		NOpt:                pp.DesiredNumberOfStakePools,
+		PoolPledgeInfluence: pp.StakePoolPledgeInfluence,
+		ExpansionRate:       pp.MonetaryExpansion,
+		TreasuryGrowthRate:  pp.TreasuryExpansion,
+		D:                   pp.FederatedBlockProductionRatio,
+		ProtocolVersion: ProtocolVersion{
+			Major: pp.Version.Major,
+			Minor: pp.Version.Minor,
+		},
+		MinPoolCost:      pp.MinStakePoolCost.Ada.Lovelace,
+		CoinsPerUTXOByte: pp.MinUTxODepositCoefficient,
+		CostModels:       pp.PlutusCostModels,
+		ExecutionCosts: ExUnitPrices{
+			MemPrice:  pp.ScriptExecutionPrices.Memory,
+			StepPrice: pp.ScriptExecutionPrices.CPU,
+		},
+		MaxTxExUnits: ExUnits{
+			Mem:   pp.MaxExecutionUnitsPerTransaction.Memory,
+			Steps: pp.MaxExecutionUnitsPerTransaction.CPU,
+		},
+		MaxBlockTxExUnits: ExUnits{
+			Mem:   pp.MaxExecutionUnitsPerBlock.Memory,
+			Steps: pp.MaxExecutionUnitsPerBlock.CPU,
+		},
+		MaxValueSize:         pp.MaxValueSize.Bytes,
+		CollateralPercentage: pp.CollateralPercentage,
+		MaxCollateralInputs:  pp.MaxCollateralInputs,
+		PoolVotingThresholds: PoolVotingThresholds{
+			MotionNoConfidence:    pvt.NoConfidence,
+			CommitteeNormal:       pvt.ConstitutionalCommittee.Default,
+			CommitteeNoConfidence: pvt.ConstitutionalCommittee.StateOfNoConfidence,
+			HardForkInitiation:    pvt.HardForkInitiation,
+			PPSecurityGroup:       pvt.ProtocolParametersUpdate.Security,
+		},
+		DRepVotingThresholds: DRepVotingThresholds{
+			MotionNoConfidence:    dvt.NoConfidence,
+			CommitteeNormal:       dvt.ConstitutionalCommittee.Default,
+			CommitteeNoConfidence: dvt.ConstitutionalCommittee.StateOfNoConfidence,
+			UpdateConstitution:    dvt.Constitution,
+			HardForkInitiation:    dvt.HardForkInitiation,
+			PPNetworkGroup:        dvt.ProtocolParametersUpdate.Network,
+			PPEconomicGroup:       dvt.ProtocolParametersUpdate.Economic,
+			PPTechnicalGroup:      dvt.ProtocolParametersUpdate.Technical,
+			PPGovGroup:            dvt.ProtocolParametersUpdate.Governance,
+			TreasuryWithdrawal:    dvt.TreasuryWithdrawals,
+		},
+		CommitteeMinSize:           pp.ConstitutionalCommitteeMinSize,
+		CommitteeMaxTermLength:     pp.ConstitutionalCommitteeMaxTermLength,
+		GovActionLifetime:          pp.GovernanceActionLifetime,
+		GovActionDeposit:           pp.GovernanceActionDeposit.Ada.Lovelace,
+		DRepDeposit:                pp.DelegateRepresentativeDeposit.Ada.Lovelace,
+		DRepActivity:               pp.DelegateRepresentativeMaxIdleTime,
+		MinFeeRefScriptCostPerByte: pp.MinFeeReferenceScripts.Base,
+	}, nil
+}
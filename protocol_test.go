@@ -0,0 +1,454 @@
+package cardano
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var wantConwayParams = &ProtocolParams{
+	MinFeeA:             44,
+	MinFeeB:             155381,
+	MaxBlockBodySize:    90112,
+	MaxTxSize:           16384,
+	MaxBlockHeaderSize:  1100,
+	KeyDeposit:          2000000,
+	PoolDeposit:         500000000,
+	MaxEpoch:            18,
+	NOpt:                500,
+	PoolPledgeInfluence: Rational{P: 3, Q: 10},
+	ExpansionRate:       Rational{P: 3, Q: 1000},
+	TreasuryGrowthRate:  Rational{P: 1, Q: 5},
+	ProtocolVersion:     ProtocolVersion{Major: 10, Minor: 0},
+	MinPoolCost:         170000000,
+	CoinsPerUTXOByte:    4310,
+	CostModels: CostModels{
+		PlutusV1: {100788, 420, 1},
+		PlutusV3: {100788, 420, 1, 1},
+	},
+	ExecutionCosts: ExUnitPrices{
+		MemPrice:  Rational{P: 577, Q: 10000},
+		StepPrice: Rational{P: 721, Q: 10000000},
+	},
+	MaxTxExUnits:         ExUnits{Mem: 14000000, Steps: 10000000000},
+	MaxBlockTxExUnits:    ExUnits{Mem: 62000000, Steps: 20000000000},
+	MaxValueSize:         5000,
+	CollateralPercentage: 150,
+	MaxCollateralInputs:  3,
+	PoolVotingThresholds: PoolVotingThresholds{
+		MotionNoConfidence:    Rational{P: 51, Q: 100},
+		CommitteeNormal:       Rational{P: 51, Q: 100},
+		CommitteeNoConfidence: Rational{P: 51, Q: 100},
+		HardForkInitiation:    Rational{P: 51, Q: 100},
+		PPSecurityGroup:       Rational{P: 51, Q: 100},
+	},
+	DRepVotingThresholds: DRepVotingThresholds{
+		MotionNoConfidence:    Rational{P: 67, Q: 100},
+		CommitteeNormal:       Rational{P: 67, Q: 100},
+		CommitteeNoConfidence: Rational{P: 3, Q: 5},
+		UpdateConstitution:    Rational{P: 3, Q: 4},
+		HardForkInitiation:    Rational{P: 3, Q: 5},
+		PPNetworkGroup:        Rational{P: 67, Q: 100},
+		PPEconomicGroup:       Rational{P: 67, Q: 100},
+		PPTechnicalGroup:      Rational{P: 67, Q: 100},
+		PPGovGroup:            Rational{P: 3, Q: 4},
+		TreasuryWithdrawal:    Rational{P: 67, Q: 100},
+	},
+	CommitteeMinSize:           7,
+	CommitteeMaxTermLength:     146,
+	GovActionLifetime:          6,
+	GovActionDeposit:           100000000000,
+	DRepDeposit:                500000000,
+	DRepActivity:               20,
+	MinFeeRefScriptCostPerByte: Rational{P: 15, Q: 1},
+}
+
+const cardanoCLIProtocolParams = `{
+	"collateralPercentage": 150,
+	"committeeMaxTermLength": 146,
+	"committeeMinSize": 7,
+	"costModels": {
+		"PlutusV1": [100788, 420, 1],
+		"PlutusV3": [100788, 420, 1, 1]
+	},
+	"dRepActivity": 20,
+	"dRepDeposit": 500000000,
+	"dRepVotingThresholds": {
+		"committeeNoConfidence": 0.6,
+		"committeeNormal": 0.67,
+		"hardForkInitiation": 0.6,
+		"motionNoConfidence": 0.67,
+		"ppEconomicGroup": 0.67,
+		"ppGovGroup": 0.75,
+		"ppNetworkGroup": 0.67,
+		"ppTechnicalGroup": 0.67,
+		"treasuryWithdrawal": 0.67,
+		"updateToConstitution": 0.75
+	},
+	"executionUnitPrices": {"priceMemory": 5.77e-2, "priceSteps": 7.21e-5},
+	"govActionDeposit": 100000000000,
+	"govActionLifetime": 6,
+	"maxBlockBodySize": 90112,
+	"maxBlockExecutionUnits": {"memory": 62000000, "steps": 20000000000},
+	"maxBlockHeaderSize": 1100,
+	"maxCollateralInputs": 3,
+	"maxTxExecutionUnits": {"memory": 14000000, "steps": 10000000000},
+	"maxTxSize": 16384,
+	"maxValueSize": 5000,
+	"minFeeRefScriptCostPerByte": 15,
+	"minPoolCost": 170000000,
+	"monetaryExpansion": 3.0e-3,
+	"poolPledgeInfluence": 0.3,
+	"poolRetireMaxEpoch": 18,
+	"poolVotingThresholds": {
+		"committeeNoConfidence": 0.51,
+		"committeeNormal": 0.51,
+		"hardForkInitiation": 0.51,
+		"motionNoConfidence": 0.51,
+		"ppSecurityGroup": 0.51
+	},
+	"protocolVersion": {"major": 10, "minor": 0},
+	"stakeAddressDeposit": 2000000,
+	"stakePoolDeposit": 500000000,
+	"stakePoolTargetNum": 500,
+	"treasuryCut": 0.2,
+	"txFeeFixed": 155381,
+	"txFeePerByte": 44,
+	"utxoCostPerByte": 4310
+}`
+
+const blockfrostProtocolParamsJSON = `{
+	"epoch": 520,
+	"min_fee_a": 44,
+	"min_fee_b": 155381,
+	"max_block_size": 90112,
+	"max_tx_size": 16384,
+	"max_block_header_size": 1100,
+	"key_deposit": "2000000",
+	"pool_deposit": "500000000",
+	"e_max": 18,
+	"n_opt": 500,
+	"a0": 0.3,
+	"rho": 0.003,
+	"tau": 0.2,
+	"decentralisation_param": 0,
+	"extra_entropy": null,
+	"protocol_major_ver": 10,
+	"protocol_minor_ver": 0,
+	"min_utxo": "4310",
+	"min_pool_cost": "170000000",
+	"nonce": "1a3be38bcbb7911969283716ad7aa550250226b76a61fc51cc9a9a35d9276d81",
+	"cost_models": {
+		"PlutusV1": {"addInteger-cpu-arguments-intercept": 100788, "addInteger-cpu-arguments-slope": 420, "addInteger-memory-arguments-intercept": 1},
+		"PlutusV3": {"addInteger-cpu-arguments-intercept": 100788, "addInteger-cpu-arguments-slope": 420, "addInteger-memory-arguments-intercept": 1, "addInteger-memory-arguments-slope": 1}
+	},
+	"cost_models_raw": {
+		"PlutusV1": [100788, 420, 1],
+		"PlutusV3": [100788, 420, 1, 1]
+	},
+	"price_mem": 0.0577,
+	"price_step": 0.0000721,
+	"max_tx_ex_mem": "14000000",
+	"max_tx_ex_steps": "10000000000",
+	"max_block_ex_mem": "62000000",
+	"max_block_ex_steps": "20000000000",
+	"max_val_size": "5000",
+	"collateral_percent": 150,
+	"max_collateral_inputs": 3,
+	"coins_per_utxo_size": "4310",
+	"coins_per_utxo_word": "4310",
+	"pvt_motion_no_confidence": 0.51,
+	"pvt_committee_normal": 0.51,
+	"pvt_committee_no_confidence": 0.51,
+	"pvt_hard_fork_initiation": 0.51,
+	"pvtpp_security_group": 0.51,
+	"dvt_motion_no_confidence": 0.67,
+	"dvt_committee_normal": 0.67,
+	"dvt_committee_no_confidence": 0.6,
+	"dvt_update_to_constitution": 0.75,
+	"dvt_hard_fork_initiation": 0.6,
+	"dvt_p_p_network_group": 0.67,
+	"dvt_p_p_economic_group": 0.67,
+	"dvt_p_p_technical_group": 0.67,
+	"dvt_p_p_gov_group": 0.75,
+	"dvt_treasury_withdrawal": 0.67,
+	"committee_min_size": "7",
+	"committee_max_term_length": "146",
+	"gov_action_lifetime": "6",
+	"gov_action_deposit": "100000000000",
+	"drep_deposit": "500000000",
+	"drep_activity": "20",
+	"min_fee_ref_script_cost_per_byte": 15
+}`
+
+const koiosProtocolParamsJSON = `{
+	"epoch_no": 520,
+	"min_fee_a": 44,
+	"min_fee_b": 155381,
+	"max_block_size": 90112,
+	"max_tx_size": 16384,
+	"max_bh_size": 1100,
+	"key_deposit": "2000000",
+	"pool_deposit": "500000000",
+	"max_epoch": 18,
+	"optimal_pool_count": 500,
+	"influence": 0.3,
+	"monetary_expand_rate": 0.003,
+	"treasury_growth_rate": 0.2,
+	"decentralisation": 0,
+	"extra_entropy": null,
+	"protocol_major": 10,
+	"protocol_minor": 0,
+	"min_utxo_value": "0",
+	"min_pool_cost": "170000000",
+	"nonce": "1a3be38bcbb7911969283716ad7aa550250226b76a61fc51cc9a9a35d9276d81",
+	"block_hash": "b0d5c1d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1",
+	"cost_models": {
+		"PlutusV1": [100788, 420, 1],
+		"PlutusV3": [100788, 420, 1, 1]
+	},
+	"price_mem": 0.0577,
+	"price_step": 0.0000721,
+	"max_tx_ex_mem": 14000000,
+	"max_tx_ex_steps": 10000000000,
+	"max_block_ex_mem": 62000000,
+	"max_block_ex_steps": 20000000000,
+	"max_val_size": 5000,
+	"collateral_percent": 150,
+	"max_collateral_inputs": 3,
+	"coins_per_utxo_size": "4310",
+	"pvt_motion_no_confidence": 0.51,
+	"pvt_committee_normal": 0.51,
+	"pvt_committee_no_confidence": 0.51,
+	"pvt_hard_fork_initiation": 0.51,
+	"pvtpp_security_group": 0.51,
+	"dvt_motion_no_confidence": 0.67,
+	"dvt_committee_normal": 0.67,
+	"dvt_committee_no_confidence": 0.6,
+	"dvt_update_to_constitution": 0.75,
+	"dvt_hard_fork_initiation": 0.6,
+	"dvt_p_p_network_group": 0.67,
+	"dvt_p_p_economic_group": 0.67,
+	"dvt_p_p_technical_group": 0.67,
+	"dvt_p_p_gov_group": 0.75,
+	"dvt_treasury_withdrawal": 0.67,
+	"committee_min_size": 7,
+	"committee_max_term_length": 146,
+	"gov_action_lifetime": 6,
+	"gov_action_deposit": "100000000000",
+	"drep_deposit": "500000000",
+	"drep_activity": 20,
+	"min_fee_ref_script_cost_per_byte": 15
+}`
+
+const ogmiosProtocolParamsJSON = `{
+	"minFeeCoefficient": 44,
+	"minFeeConstant": {"ada": {"lovelace": 155381}},
+	"minFeeReferenceScripts": {"range": 25600, "base": 15.0, "multiplier": 1.2},
+	"maxBlockBodySize": {"bytes": 90112},
+	"maxBlockHeaderSize": {"bytes": 1100},
+	"maxTransactionSize": {"bytes": 16384},
+	"stakeCredentialDeposit": {"ada": {"lovelace": 2000000}},
+	"stakePoolDeposit": {"ada": {"lovelace": 500000000}},
+	"stakePoolRetirementEpochBound": 18,
+	"desiredNumberOfStakePools": 500,
+	"stakePoolPledgeInfluence": "3/10",
+	"monetaryExpansion": "3/1000",
+	"treasuryExpansion": "1/5",
+	"minStakePoolCost": {"ada": {"lovelace": 170000000}},
+	"minUtxoDepositConstant": {"ada": {"lovelace": 0}},
+	"minUtxoDepositCoefficient": 4310,
+	"plutusCostModels": {
+		"plutus:v1": [100788, 420, 1],
+		"plutus:v3": [100788, 420, 1, 1]
+	},
+	"scriptExecutionPrices": {"memory": "577/10000", "cpu": "721/10000000"},
+	"maxExecutionUnitsPerTransaction": {"memory": 14000000, "cpu": 10000000000},
+	"maxExecutionUnitsPerBlock": {"memory": 62000000, "cpu": 20000000000},
+	"maxValueSize": {"bytes": 5000},
+	"collateralPercentage": 150,
+	"maxCollateralInputs": 3,
+	"version": {"major": 10, "minor": 0},
+	"stakePoolVotingThresholds": {
+		"noConfidence": "51/100",
+		"constitutionalCommittee": {"default": "51/100", "stateOfNoConfidence": "51/100"},
+		"hardForkInitiation": "51/100",
+		"protocolParametersUpdate": {"security": "51/100"}
+	},
+	"delegateRepresentativeVotingThresholds": {
+		"noConfidence": "67/100",
+		"constitutionalCommittee": {"default": "67/100", "stateOfNoConfidence": "3/5"},
+		"constitution": "3/4",
+		"hardForkInitiation": "3/5",
+		"protocolParametersUpdate": {"network": "67/100", "economic": "67/100", "technical": "67/100", "governance": "3/4"},
+		"treasuryWithdrawals": "67/100"
+	},
+	"constitutionalCommitteeMinSize": 7,
+	"constitutionalCommitteeMaxTermLength": 146,
+	"governanceActionLifetime": 6,
+	"governanceActionDeposit": {"ada": {"lovelace": 100000000000}},
+	"delegateRepresentativeDeposit": {"ada": {"lovelace": 500000000}},
+	"delegateRepresentativeMaxIdleTime": 20
+}`
+
+func TestProtocolParamsJSONDecoding(t *testing.T) {
+	testcases := []struct {
+		name  string
+		parse func([]byte) (*ProtocolParams, error)
+		json  string
+		want  func() *ProtocolParams
+	}{
+		{
+			name:  "CardanoCLI",
+			parse: NewProtocolParamsFromCardanoCLIJSON,
+			json:  cardanoCLIProtocolParams,
+			want:  func() *ProtocolParams { return wantConwayParams },
+		},
+		{
+			name:  "Blockfrost",
+			parse: NewProtocolParamsFromBlockfrostJSON,
+			json:  blockfrostProtocolParamsJSON,
+			want: func() *ProtocolParams {
+				want := *wantConwayParams
+				want.CoinsPerUTXOWord = 4310
+				want.D = Rational{P: 0, Q: 1}
+				return &want
+			},
+		},
+		{
+			name:  "Koios",
+			parse: NewProtocolParamsFromKoiosJSON,
+			json:  koiosProtocolParamsJSON,
+			want: func() *ProtocolParams {
+				want := *wantConwayParams
+				want.D = Rational{P: 0, Q: 1}
+				return &want
+			},
+		},
+		{
+			name:  "Ogmios",
+			parse: NewProtocolParamsFromOgmiosJSON,
+			json:  ogmiosProtocolParamsJSON,
+			want:  func() *ProtocolParams { return wantConwayParams },
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.parse([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want(), got, cmpopts.IgnoreUnexported(ProtocolVersion{}, Rational{}, ExUnits{}, ExUnitPrices{}, PoolVotingThresholds{}, DRepVotingThresholds{})); diff != "" {
+				t.Errorf("invalid protocol params (-want +got):\n%s", diff)
+			}
+			if got.Era() != ConwayEra {
+				t.Errorf("invalid era: got %v want %v", got.Era(), ConwayEra)
+			}
+		})
+	}
+}
+
+func TestProtocolParamsCBORDecoding(t *testing.T) {
+	babbage, conway := wantConwayParams.ledgerFields()
+	wantBabbage := *wantConwayParams
+	wantBabbage.PoolVotingThresholds = PoolVotingThresholds{}
+	wantBabbage.DRepVotingThresholds = DRepVotingThresholds{}
+	wantBabbage.CommitteeMinSize = 0
+	wantBabbage.CommitteeMaxTermLength = 0
+	wantBabbage.GovActionLifetime = 0
+	wantBabbage.GovActionDeposit = 0
+	wantBabbage.DRepDeposit = 0
+	wantBabbage.DRepActivity = 0
+	wantBabbage.MinFeeRefScriptCostPerByte = Rational{}
+
+	testcases := []struct {
+		name   string
+		fields []any
+		want   *ProtocolParams
+	}{
+		{name: "Conway", fields: append(babbage, conway...), want: wantConwayParams},
+		{name: "Babbage", fields: babbage, want: &wantBabbage},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := cborEnc.Marshal(tc.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := NewProtocolParamsFromCBOR(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(ProtocolVersion{}, Rational{}, ExUnits{}, ExUnitPrices{}, PoolVotingThresholds{}, DRepVotingThresholds{})); diff != "" {
+				t.Errorf("invalid protocol params (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := NewProtocolParamsFromCBOR([]byte{0x82, 0x01, 0x02}); err == nil {
+		t.Error("expected error for a truncated parameters array")
+	}
+}
+
+func TestCostModelsNamedJSONDecoding(t *testing.T) {
+	var got CostModels
+	err := got.UnmarshalJSON([]byte(`{"PlutusScriptV2": {"b-param": 2, "a-param": 1, "c-param": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(CostModels{PlutusV2: {1, 2, 3}}, got); diff != "" {
+		t.Errorf("invalid cost models (-want +got):\n%s", diff)
+	}
+
+	// The PlutusV3 parameters are not ordered by name.
+	err = got.UnmarshalJSON([]byte(`{"PlutusV3": {"b-param": 2, "a-param": 1}}`))
+	if err == nil {
+		t.Error("expected error for named PlutusV3 parameters")
+	}
+}
+
+func TestProtocolParamUpdateEncoding(t *testing.T) {
+	minFeeA := Coin(44)
+	update := &ProtocolParamUpdate{
+		MinFeeA:                    &minFeeA,
+		CostModels:                 CostModels{PlutusV2: {1, 2, -3}},
+		ExecutionCosts:             &wantConwayParams.ExecutionCosts,
+		MaxTxExUnits:               &wantConwayParams.MaxTxExUnits,
+		MinFeeRefScriptCostPerByte: &wantConwayParams.MinFeeRefScriptCostPerByte,
+	}
+	want := "a500182c12a101830102221382d81e82190241192710d81e821902d11a00989680" +
+		"14821a00d59f801b00000002540be4001821d81e820f01"
+
+	got, err := update.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(got) != want {
+		t.Errorf("invalid protocol param update encoding:\ngot:  %x\nwant: %v", got, want)
+	}
+
+	genesisHash := make(Hash28, 28)
+	proposal := NewUpdate(500).Set(genesisHash, update)
+	proposalBytes, err := cborEnc.Marshal(proposal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Update
+	if err := cborDec.Unmarshal(proposalBytes, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Epoch != 500 || len(decoded.Keys()) != 1 {
+		t.Fatalf("invalid update: got %+v", decoded)
+	}
+	decodedBytes, err := decoded.Get(genesisHash).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(decodedBytes) != want {
+		t.Errorf("invalid decoded protocol param update:\ngot:  %x\nwant: %v", decodedBytes, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
 )
 
 type ScriptHashNamespace uint8
@@ -11,6 +12,8 @@ type ScriptHashNamespace uint8
 const (
 	NativeScriptNamespace ScriptHashNamespace = iota
 	PlutusScriptNamespace
+	PlutusV2ScriptNamespace
+	PlutusV3ScriptNamespace
 )
 
 type NativeScriptType uint64
@@ -79,6 +82,60 @@ func NewScriptPubKey(publicKey crypto.PubKey) (NativeScript, error) {
 	return NativeScript{Type: ScriptPubKey, KeyHash: keyHash}, nil
 }
 
+// keyHashes returns all the key hashes found in the script.
+func (ns *NativeScript) keyHashes() []AddrKeyHash {
+	switch ns.Type {
+	case ScriptPubKey:
+		return []AddrKeyHash{ns.KeyHash}
+	case ScriptAll, ScriptAny, ScriptNofK:
+		keyHashes := []AddrKeyHash{}
+		for i := range ns.Scripts {
+			keyHashes = append(keyHashes, ns.Scripts[i].keyHashes()...)
+		}
+		return keyHashes
+	default:
+		return nil
+	}
+}
+
+// satisfied returns true if the script is satisfied by the witnessed key hashes
+// and the validity interval of a transaction.
+func (ns *NativeScript) satisfied(witnessed map[string]struct{}, validityStart, ttl Uint64) bool {
+	switch ns.Type {
+	case ScriptPubKey:
+		_, ok := witnessed[ns.KeyHash.String()]
+		return ok
+	case ScriptAll:
+		for i := range ns.Scripts {
+			if !ns.Scripts[i].satisfied(witnessed, validityStart, ttl) {
+				return false
+			}
+		}
+		return true
+	case ScriptAny:
+		for i := range ns.Scripts {
+			if ns.Scripts[i].satisfied(witnessed, validityStart, ttl) {
+				return true
+			}
+		}
+		return false
+	case ScriptNofK:
+		var n uint64
+		for i := range ns.Scripts {
+			if ns.Scripts[i].satisfied(witnessed, validityStart, ttl) {
+				n++
+			}
+		}
+		return n >= ns.N
+	case ScriptInvalidBefore:
+		return validityStart != nil && *validityStart >= ns.IntervalValue
+	case ScriptInvalidAfter:
+		return ttl != nil && *ttl <= ns.IntervalValue
+	default:
+		return false
+	}
+}
+
 // Hash returns the script hash using blake2b224.
 func (ns *NativeScript) Hash() (Hash28, error) {
 	bytes, err := ns.Bytes()
@@ -165,3 +222,107 @@ func (ns *NativeScript) UnmarshalCBOR(data []byte) error {
 
 	return nil
 }
+
+// ScriptRef is a script stored in a transaction output so it can be referenced
+// by other transactions (CIP-33).
+type ScriptRef struct {
+	Type         ScriptHashNamespace
+	NativeScript NativeScript
+	PlutusScript PlutusScript
+}
+
+// NewNativeScriptRef returns a new ScriptRef holding a native script.
+func NewNativeScriptRef(script NativeScript) *ScriptRef {
+	return &ScriptRef{Type: NativeScriptNamespace, NativeScript: script}
+}
+
+// NewPlutusScriptRef returns a new ScriptRef holding a plutus script of the given version.
+func NewPlutusScriptRef(version ScriptHashNamespace, script PlutusScript) *ScriptRef {
+	return &ScriptRef{Type: version, PlutusScript: script}
+}
+
+// Bytes returns the serialized script, as used for the script hash and
+// the reference script size.
+func (s *ScriptRef) Bytes() ([]byte, error) {
+	if s.Type == NativeScriptNamespace {
+		return s.NativeScript.Bytes()
+	}
+	return s.PlutusScript, nil
+}
+
+// Hash returns the script hash using blake2b224.
+func (s *ScriptRef) Hash() (Hash28, error) {
+	bytes, err := s.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	bytes = append([]byte{byte(s.Type)}, bytes...)
+	return Blake224Hash(bytes)
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (s *ScriptRef) MarshalCBOR() ([]byte, error) {
+	var script []any
+	switch s.Type {
+	case NativeScriptNamespace:
+		script = append(script, s.Type, &s.NativeScript)
+	case PlutusScriptNamespace, PlutusV2ScriptNamespace, PlutusV3ScriptNamespace:
+		script = append(script, s.Type, []byte(s.PlutusScript))
+	default:
+		return nil, fmt.Errorf("unknown script type %v", s.Type)
+	}
+	scriptBytes, err := cborEnc.Marshal(script)
+	if err != nil {
+		return nil, err
+	}
+	return cborEnc.Marshal(cbor.Tag{Number: 24, Content: scriptBytes})
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (s *ScriptRef) UnmarshalCBOR(data []byte) error {
+	var tag cbor.RawTag
+	if err := cborDec.Unmarshal(data, &tag); err != nil {
+		return err
+	}
+	if tag.Number != 24 {
+		return fmt.Errorf("cbor: invalid script ref tag %v", tag.Number)
+	}
+	var scriptBytes []byte
+	if err := cborDec.Unmarshal(tag.Content, &scriptBytes); err != nil {
+		return err
+	}
+
+	scriptType, err := getTypeFromCBORArray(scriptBytes)
+	if err != nil {
+		return fmt.Errorf("cbor: cannot unmarshal CBOR array into ScriptRef (%v)", err)
+	}
+
+	switch ScriptHashNamespace(scriptType) {
+	case NativeScriptNamespace:
+		script := struct {
+			_      struct{} `cbor:"_,toarray"`
+			Type   ScriptHashNamespace
+			Script NativeScript
+		}{}
+		if err := cborDec.Unmarshal(scriptBytes, &script); err != nil {
+			return err
+		}
+		s.Type = script.Type
+		s.NativeScript = script.Script
+	case PlutusScriptNamespace, PlutusV2ScriptNamespace, PlutusV3ScriptNamespace:
+		script := struct {
+			_      struct{} `cbor:"_,toarray"`
+			Type   ScriptHashNamespace
+			Script []byte
+		}{}
+		if err := cborDec.Unmarshal(scriptBytes, &script); err != nil {
+			return err
+		}
+		s.Type = script.Type
+		s.PlutusScript = script.Script
+	default:
+		return fmt.Errorf("cbor: unknown script type %v", scriptType)
+	}
+
+	return nil
+}
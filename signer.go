@@ -0,0 +1,56 @@
+package cardano
+
+import (
+	"context"
+
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+// Signer signs transaction body hashes with an ed25519 key, which may be held
+// outside of the process, e.g. by a remote signer.
+type Signer interface {
+	// PubKey returns the public key of the signer.
+	PubKey() crypto.PubKey
+
+	// KeyHash returns the blake2b-224 hash of the public key.
+	KeyHash() (AddrKeyHash, error)
+
+	// Sign returns the ed25519 signature of the transaction body hash.
+	Sign(ctx context.Context, txHash Hash32) ([]byte, error)
+}
+
+type prvKeySigner struct {
+	prv crypto.PrvKey
+}
+
+// NewPrvKeySigner returns a Signer using an extended ed25519 private key.
+func NewPrvKeySigner(prv crypto.PrvKey) Signer {
+	return &prvKeySigner{prv: prv}
+}
+
+// NewXPrvKeySigner returns a Signer using the private key of an extended
+// private key.
+func NewXPrvKeySigner(xprv crypto.XPrvKey) Signer {
+	return &prvKeySigner{prv: xprv.PrvKey()}
+}
+
+func (s *prvKeySigner) PubKey() crypto.PubKey {
+	return s.prv.PubKey()
+}
+
+func (s *prvKeySigner) KeyHash() (AddrKeyHash, error) {
+	return s.prv.PubKey().Hash()
+}
+
+func (s *prvKeySigner) Sign(_ context.Context, txHash Hash32) ([]byte, error) {
+	return s.prv.Sign(txHash), nil
+}
+
+// NewVKeyWitness returns the vkey witness of a transaction body hash signed by the signer.
+func NewVKeyWitness(ctx context.Context, signer Signer, txHash Hash32) (VKeyWitness, error) {
+	signature, err := signer.Sign(ctx, txHash)
+	if err != nil {
+		return VKeyWitness{}, err
+	}
+	return VKeyWitness{VKey: signer.PubKey(), Signature: signature}, nil
+}
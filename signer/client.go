@@ -0,0 +1,156 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+// ClientOptions are the options of a remote signer Client.
+type ClientOptions struct {
+	// HTTPClient is the client used for the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+
+	// Token is sent as a bearer token if not empty.
+	Token string
+}
+
+// Client is a client of a remote signer server.
+type Client struct {
+	url    string
+	opts   ClientOptions
+	nextID atomic.Uint64
+}
+
+// NewClient returns a new Client for the signer server listening at url.
+func NewClient(url string, opts *ClientOptions) *Client {
+	c := &Client{url: url}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.HTTPClient == nil {
+		c.opts.HTTPClient = http.DefaultClient
+	}
+	return c
+}
+
+// Keys returns the public keys held by the server.
+func (c *Client) Keys(ctx context.Context) ([]crypto.PubKey, error) {
+	var result []string
+	if err := c.call(ctx, methodKeys, nil, &result); err != nil {
+		return nil, err
+	}
+	keys := make([]crypto.PubKey, len(result))
+	for i, key := range result {
+		pubKey, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = pubKey
+	}
+	return keys, nil
+}
+
+// Sign returns the signature of the transaction body hash made by the key.
+func (c *Client) Sign(ctx context.Context, pubKey crypto.PubKey, txHash cardano.Hash32) ([]byte, error) {
+	params := signParams{PubKey: pubKey.String(), TxHash: txHash.String()}
+	var result signResult
+	if err := c.call(ctx, methodSign, params, &result); err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(result.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !pubKey.Verify(txHash, signature) {
+		return nil, fmt.Errorf("invalid signature from signer for key %v", pubKey)
+	}
+	return signature, nil
+}
+
+// Signer returns a cardano.Signer signing with a key held by the server.
+func (c *Client) Signer(pubKey crypto.PubKey) cardano.Signer {
+	return &remoteSigner{client: c, pubKey: pubKey}
+}
+
+// Signers returns a cardano.Signer for each key held by the server.
+func (c *Client) Signers(ctx context.Context) ([]cardano.Signer, error) {
+	keys, err := c.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signers := make([]cardano.Signer, len(keys))
+	for i, key := range keys {
+		signers[i] = c.Signer(key)
+	}
+	return signers, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	req := request{JSONRPC: jsonRPCVersion, ID: c.nextID.Add(1), Method: method}
+	if params != nil {
+		rawParams, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = rawParams
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.opts.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.opts.Token)
+	}
+
+	httpResp, err := c.opts.HTTPClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	var resp response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("signer: %v: %w", httpResp.Status, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if resp.ID != req.ID {
+		return fmt.Errorf("signer: response id %v, want %v", resp.ID, req.ID)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+type remoteSigner struct {
+	client *Client
+	pubKey crypto.PubKey
+}
+
+// check interface
+var _ cardano.Signer = (*remoteSigner)(nil)
+
+func (s *remoteSigner) PubKey() crypto.PubKey {
+	return s.pubKey
+}
+
+func (s *remoteSigner) KeyHash() (cardano.AddrKeyHash, error) {
+	return s.pubKey.Hash()
+}
+
+func (s *remoteSigner) Sign(ctx context.Context, txHash cardano.Hash32) ([]byte, error) {
+	return s.client.Sign(ctx, s.pubKey, txHash)
+}
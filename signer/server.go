@@ -0,0 +1,107 @@
+package signer
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cryptogarageinc/cardano-go"
+)
+
+// maxRequestSize is the maximum size of a request body.
+const maxRequestSize = 1 << 16
+
+// Server serves signing requests for a set of signers.
+type Server struct {
+	signers map[string]cardano.Signer
+	keys    []string
+	token   string
+}
+
+// check interface
+var _ http.Handler = (*Server)(nil)
+
+// NewServer returns a new Server for the signers. If token is not empty,
+// requests must provide it as a bearer token.
+func NewServer(token string, signers ...cardano.Signer) *Server {
+	s := &Server{signers: map[string]cardano.Signer{}, token: token}
+	for _, signer := range signers {
+		key := signer.PubKey().String()
+		if _, ok := s.signers[key]; ok {
+			continue
+		}
+		s.signers[key] = signer
+		s.keys = append(s.keys, key)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		s.reply(w, req.ID, nil, &Error{Code: CodeParseError, Message: err.Error()})
+		return
+	}
+	if s.token != "" {
+		auth := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("Bearer "+s.token)) != 1 {
+			s.reply(w, req.ID, nil, &Error{Code: CodeUnauthorized, Message: "unauthorized"})
+			return
+		}
+	}
+	if req.JSONRPC != jsonRPCVersion {
+		s.reply(w, req.ID, nil, &Error{Code: CodeInvalidRequest, Message: "invalid jsonrpc version"})
+		return
+	}
+
+	switch req.Method {
+	case methodKeys:
+		s.reply(w, req.ID, s.keys, nil)
+	case methodSign:
+		var params signParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.reply(w, req.ID, nil, &Error{Code: CodeInvalidParams, Message: err.Error()})
+			return
+		}
+		txHash, err := hex.DecodeString(params.TxHash)
+		if err != nil || len(txHash) != 32 {
+			s.reply(w, req.ID, nil, &Error{Code: CodeInvalidParams, Message: "invalid tx hash " + params.TxHash})
+			return
+		}
+		signer, ok := s.signers[params.PubKey]
+		if !ok {
+			s.reply(w, req.ID, nil, &Error{Code: CodeUnknownKey, Message: "unknown key " + params.PubKey})
+			return
+		}
+		signature, err := signer.Sign(r.Context(), txHash)
+		if err != nil {
+			s.reply(w, req.ID, nil, &Error{Code: CodeInternalError, Message: err.Error()})
+			return
+		}
+		s.reply(w, req.ID, signResult{Signature: hex.EncodeToString(signature)}, nil)
+	default:
+		s.reply(w, req.ID, nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method})
+	}
+}
+
+func (s *Server) reply(w http.ResponseWriter, id uint64, result interface{}, rpcErr *Error) {
+	resp := response{JSONRPC: jsonRPCVersion, ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		rawResult, err := json.Marshal(result)
+		if err != nil {
+			resp.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+		} else {
+			resp.Result = rawResult
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
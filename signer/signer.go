@@ -0,0 +1,64 @@
+// Package signer implements a remote signer, so signing keys can be kept in an
+// isolated process.
+//
+// The client and the server talk JSON-RPC 2.0 over HTTP POST, with two methods:
+//
+//	keys: returns the hex encoded public keys held by the server.
+//	sign: params {"pubKey": hex, "txHash": hex}, returns {"signature": hex}.
+package signer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	jsonRPCVersion = "2.0"
+
+	methodKeys = "keys"
+	methodSign = "sign"
+)
+
+// JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeUnauthorized   = -32001
+	CodeUnknownKey     = -32002
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type signParams struct {
+	PubKey string `json:"pubKey"`
+	TxHash string `json:"txHash"`
+}
+
+type signResult struct {
+	Signature string `json:"signature"`
+}
+
+// Error is a JSON-RPC error returned by the signer server.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("signer error %d: %s", e.Code, e.Message)
+}
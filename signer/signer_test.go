@@ -0,0 +1,113 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	aliceKey := crypto.NewXPrvKeyFromEntropy([]byte("alice"), "")
+	bobKey := crypto.NewXPrvKeyFromEntropy([]byte("bob"), "")
+
+	server := httptest.NewServer(NewServer("secret",
+		cardano.NewXPrvKeySigner(aliceKey),
+		cardano.NewXPrvKeySigner(bobKey),
+	))
+	defer server.Close()
+
+	client := NewClient(server.URL, &ClientOptions{Token: "secret"})
+	keys, err := client.Keys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(keys), 2; got != want {
+		t.Fatalf("invalid number of keys: got %v want %v", got, want)
+	}
+	if got, want := keys[0].String(), aliceKey.PubKey().String(); got != want {
+		t.Errorf("invalid key: got %v want %v", got, want)
+	}
+
+	txHash, err := cardano.NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := client.Signer(bobKey.PubKey()).Sign(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bobKey.PubKey().Verify(txHash, signature) {
+		t.Error("invalid signature")
+	}
+
+	unknownKey := crypto.NewXPrvKeyFromEntropy([]byte("carol"), "")
+	var rpcErr *Error
+	if _, err := client.Signer(unknownKey.PubKey()).Sign(ctx, txHash); !errors.As(err, &rpcErr) || rpcErr.Code != CodeUnknownKey {
+		t.Errorf("unknown key: got %v want code %v", err, CodeUnknownKey)
+	}
+
+	unauthorized := NewClient(server.URL, nil)
+	if _, err := unauthorized.Keys(ctx); !errors.As(err, &rpcErr) || rpcErr.Code != CodeUnauthorized {
+		t.Errorf("unauthorized: got %v want code %v", err, CodeUnauthorized)
+	}
+}
+
+func TestBuildWithRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+
+	server := httptest.NewServer(NewServer("", cardano.NewXPrvKeySigner(paymentKey)))
+	defer server.Close()
+
+	signers, err := NewClient(server.URL, nil).Signers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payment, err := cardano.NewKeyCredential(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := cardano.NewEnterpriseAddress(cardano.Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := cardano.NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{
+		MinFeeA:          44,
+		MinFeeB:          155381,
+		CoinsPerUTXOByte: 4310,
+	})
+	txBuilder.AddInputs(&cardano.TxInput{TxHash: txHash, Index: 0, Amount: cardano.NewValue(10e6), Spender: &addr})
+	txBuilder.AddOutputs(cardano.NewTxOutput(addr, cardano.NewValue(5e6)))
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.AddSigners(signers...)
+	tx, err := txBuilder.BuildContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(tx.WitnessSet.VKeyWitnessSet), 1; got != want {
+		t.Fatalf("invalid number of witnesses: got %v want %v", got, want)
+	}
+	if err := tx.VerifyWitnesses(); err != nil {
+		t.Error(err)
+	}
+
+	server.Close()
+	txBuilder.Reset()
+	txBuilder.AddInputs(&cardano.TxInput{TxHash: txHash, Index: 0, Amount: cardano.NewValue(10e6), Spender: &addr})
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.AddSigners(signers...)
+	if _, err := txBuilder.BuildContext(ctx); err == nil {
+		t.Error("expected error with the signer down")
+	}
+}
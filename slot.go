@@ -0,0 +1,167 @@
+package cardano
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EraSummary describes the slots and epochs of a range of the chain
+// sharing the same slot and epoch lengths.
+type EraSummary struct {
+	StartSlot   uint64
+	StartEpoch  uint64
+	StartTime   time.Time
+	SlotLength  time.Duration
+	EpochLength uint64
+}
+
+// SlotConfig converts between slots, epochs and time using the era history
+// of the chain. The last era is assumed to last forever.
+type SlotConfig struct {
+	Eras []EraSummary
+}
+
+var (
+	// MainnetSlotConfig is the slot configuration of the mainnet.
+	MainnetSlotConfig = newSlotConfig(
+		time.Date(2017, 9, 23, 21, 44, 51, 0, time.UTC), 20*time.Second, 21600,
+		208, time.Second, 432000,
+	)
+
+	// PreprodSlotConfig is the slot configuration of the preprod testnet.
+	PreprodSlotConfig = newSlotConfig(
+		time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), 20*time.Second, 21600,
+		4, time.Second, 432000,
+	)
+
+	// PreviewSlotConfig is the slot configuration of the preview testnet.
+	PreviewSlotConfig = newSlotConfig(
+		time.Date(2022, 10, 25, 0, 0, 0, 0, time.UTC), 20*time.Second, 4320,
+		0, time.Second, 86400,
+	)
+)
+
+func newSlotConfig(
+	systemStart time.Time,
+	byronSlotLength time.Duration,
+	byronEpochLength uint64,
+	shelleyStartEpoch uint64,
+	shelleySlotLength time.Duration,
+	shelleyEpochLength uint64,
+) *SlotConfig {
+	byron := EraSummary{
+		StartTime:   systemStart,
+		SlotLength:  byronSlotLength,
+		EpochLength: byronEpochLength,
+	}
+	byronSlots := shelleyStartEpoch * byronEpochLength
+	shelley := EraSummary{
+		StartSlot:   byronSlots,
+		StartEpoch:  shelleyStartEpoch,
+		StartTime:   systemStart.Add(time.Duration(byronSlots) * byronSlotLength),
+		SlotLength:  shelleySlotLength,
+		EpochLength: shelleyEpochLength,
+	}
+	if shelleyStartEpoch == 0 {
+		return &SlotConfig{Eras: []EraSummary{shelley}}
+	}
+	return &SlotConfig{Eras: []EraSummary{byron, shelley}}
+}
+
+// SlotConfigForNetwork returns the slot configuration preset of the network.
+func SlotConfigForNetwork(network Network) *SlotConfig {
+	switch network {
+	case Mainnet:
+		return MainnetSlotConfig
+	case Preprod:
+		return PreprodSlotConfig
+	default:
+		return PreviewSlotConfig
+	}
+}
+
+// NewSlotConfigFromGenesis returns the slot configuration described by the Byron and
+// Shelley genesis files. The epoch of the transition to Shelley is not part of the
+// genesis files and must be provided, it is found in the node configuration as
+// TestShelleyHardForkAtEpoch or in the chain history.
+func NewSlotConfigFromGenesis(byron *ByronGenesis, shelley *ShelleyGenesis, shelleyStartEpoch uint64) (*SlotConfig, error) {
+	byronSlotLength, err := byron.SlotLength()
+	if err != nil {
+		return nil, err
+	}
+	if byron.EpochLength() == 0 || shelley.EpochLength == 0 {
+		return nil, errors.New("epoch length must be positive")
+	}
+	if shelley.SlotLengthDuration() <= 0 {
+		return nil, errors.New("slot length must be positive")
+	}
+	return newSlotConfig(
+		byron.SystemStart(), byronSlotLength, byron.EpochLength(),
+		shelleyStartEpoch, shelley.SlotLengthDuration(), shelley.EpochLength,
+	), nil
+}
+
+// SystemStart returns the start time of the chain.
+func (sc *SlotConfig) SystemStart() time.Time {
+	return sc.Eras[0].StartTime
+}
+
+// SlotToTime returns the start time of the slot.
+func (sc *SlotConfig) SlotToTime(slot uint64) time.Time {
+	era := sc.eraBySlot(slot)
+	return era.StartTime.Add(time.Duration(slot-era.StartSlot) * era.SlotLength)
+}
+
+// TimeToSlot returns the slot containing the given time.
+func (sc *SlotConfig) TimeToSlot(t time.Time) (uint64, error) {
+	if t.Before(sc.SystemStart()) {
+		return 0, fmt.Errorf("time %v is before the system start %v", t, sc.SystemStart())
+	}
+	era := sc.Eras[0]
+	for _, e := range sc.Eras[1:] {
+		if t.Before(e.StartTime) {
+			break
+		}
+		era = e
+	}
+	return era.StartSlot + uint64(t.Sub(era.StartTime)/era.SlotLength), nil
+}
+
+// SlotToEpoch returns the epoch containing the slot.
+func (sc *SlotConfig) SlotToEpoch(slot uint64) uint64 {
+	era := sc.eraBySlot(slot)
+	return era.StartEpoch + (slot-era.StartSlot)/era.EpochLength
+}
+
+// EpochFirstSlot returns the first slot of the epoch.
+func (sc *SlotConfig) EpochFirstSlot(epoch uint64) uint64 {
+	era := sc.Eras[0]
+	for _, e := range sc.Eras[1:] {
+		if epoch < e.StartEpoch {
+			break
+		}
+		era = e
+	}
+	return era.StartSlot + (epoch-era.StartEpoch)*era.EpochLength
+}
+
+// TimeToEpoch returns the epoch containing the given time.
+func (sc *SlotConfig) TimeToEpoch(t time.Time) (uint64, error) {
+	slot, err := sc.TimeToSlot(t)
+	if err != nil {
+		return 0, err
+	}
+	return sc.SlotToEpoch(slot), nil
+}
+
+func (sc *SlotConfig) eraBySlot(slot uint64) EraSummary {
+	era := sc.Eras[0]
+	for _, e := range sc.Eras[1:] {
+		if slot < e.StartSlot {
+			break
+		}
+		era = e
+	}
+	return era
+}
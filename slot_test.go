@@ -0,0 +1,149 @@
+package cardano
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	mainnetByronGenesis = `{
+		"startTime": 1506203091,
+		"protocolConsts": {"k": 2160, "protocolMagic": 764824073},
+		"blockVersionData": {"slotDuration": "20000"}
+	}`
+	mainnetShelleyGenesis = `{
+		"systemStart": "2017-09-23T21:44:51Z",
+		"networkMagic": 764824073,
+		"networkId": "Mainnet",
+		"epochLength": 432000,
+		"slotLength": 1,
+		"securityParam": 2160,
+		"activeSlotsCoeff": 0.05
+	}`
+)
+
+func TestSlotConfig(t *testing.T) {
+	testcases := []struct {
+		name       string
+		slotConfig *SlotConfig
+		slot       uint64
+		time       time.Time
+		epoch      uint64
+	}{
+		{
+			name:       "MainnetSystemStart",
+			slotConfig: MainnetSlotConfig,
+			slot:       0,
+			time:       time.Date(2017, 9, 23, 21, 44, 51, 0, time.UTC),
+			epoch:      0,
+		},
+		{
+			name:       "MainnetByron",
+			slotConfig: MainnetSlotConfig,
+			slot:       21601,
+			time:       time.Date(2017, 9, 28, 21, 45, 11, 0, time.UTC),
+			epoch:      1,
+		},
+		{
+			name:       "MainnetShelleyStart",
+			slotConfig: MainnetSlotConfig,
+			slot:       4492800,
+			time:       time.Date(2020, 7, 29, 21, 44, 51, 0, time.UTC),
+			epoch:      208,
+		},
+		{
+			name:       "MainnetShelley",
+			slotConfig: MainnetSlotConfig,
+			slot:       100000000,
+			time:       time.Unix(1591566291+100000000, 0).UTC(),
+			epoch:      429,
+		},
+		{
+			name:       "Preprod",
+			slotConfig: PreprodSlotConfig,
+			slot:       86400 + 432000,
+			time:       time.Unix(1654041600+86400*20+432000, 0).UTC(),
+			epoch:      5,
+		},
+		{
+			name:       "Preview",
+			slotConfig: PreviewSlotConfig,
+			slot:       86400*3 + 10,
+			time:       time.Unix(1666656000+86400*3+10, 0).UTC(),
+			epoch:      3,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.slotConfig.SlotToTime(tc.slot); !got.Equal(tc.time) {
+				t.Errorf("invalid slot time: got %v want %v", got, tc.time)
+			}
+			got, err := tc.slotConfig.TimeToSlot(tc.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.slot {
+				t.Errorf("invalid slot: got %v want %v", got, tc.slot)
+			}
+			if got := tc.slotConfig.SlotToEpoch(tc.slot); got != tc.epoch {
+				t.Errorf("invalid epoch: got %v want %v", got, tc.epoch)
+			}
+			if got := tc.slotConfig.EpochFirstSlot(tc.epoch); got > tc.slot || tc.slotConfig.SlotToEpoch(got) != tc.epoch {
+				t.Errorf("invalid epoch first slot: got %v", got)
+			}
+		})
+	}
+
+	if _, err := MainnetSlotConfig.TimeToSlot(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected error for time before system start")
+	}
+}
+
+func TestSlotConfigFromGenesis(t *testing.T) {
+	byron, err := ParseByronGenesis([]byte(mainnetByronGenesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shelley, err := ParseShelleyGenesis([]byte(mainnetShelleyGenesis))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewSlotConfigFromGenesis(byron, shelley, 208)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Eras) != len(MainnetSlotConfig.Eras) {
+		t.Fatalf("invalid number of eras: got %v want %v", len(got.Eras), len(MainnetSlotConfig.Eras))
+	}
+	for i, era := range got.Eras {
+		want := MainnetSlotConfig.Eras[i]
+		if !era.StartTime.Equal(want.StartTime) || era.StartSlot != want.StartSlot ||
+			era.StartEpoch != want.StartEpoch || era.SlotLength != want.SlotLength ||
+			era.EpochLength != want.EpochLength {
+			t.Errorf("invalid era %v: got %+v want %+v", i, era, want)
+		}
+	}
+}
+
+func TestSetValidityTime(t *testing.T) {
+	txBuilder := NewTxBuilder(babbageProtocol)
+	if err := txBuilder.SetValidUntil(time.Now()); err == nil {
+		t.Fatal("expected error without slot config")
+	}
+
+	txBuilder.SetSlotConfig(MainnetSlotConfig)
+	validFrom := time.Unix(1591566291+100000000, int64(500*time.Millisecond))
+	if err := txBuilder.SetValidFrom(validFrom); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := *txBuilder.tx.Body.ValidityIntervalStart, uint64(100000001); got != want {
+		t.Errorf("invalid validity interval start: got %v want %v", got, want)
+	}
+	if err := txBuilder.SetValidUntil(validFrom.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := *txBuilder.tx.Body.TTL, uint64(100003600); got != want {
+		t.Errorf("invalid ttl: got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package cardano
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
+)
+
+const (
+	keyWitnessTag       = 0
+	bootstrapWitnessTag = 1
+)
+
+// TextEnvelope is the JSON envelope used by cardano-cli for transaction and
+// witness files.
+type TextEnvelope struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	CborHex     string `json:"cborHex"`
+}
+
+// NewTxTextEnvelope returns a cardano-cli transaction file envelope for the era.
+func NewTxTextEnvelope(tx *Tx, era Era) *TextEnvelope {
+	txType := "Unwitnessed Tx "
+	if len(tx.WitnessSet.VKeyWitnessSet) > 0 || len(tx.WitnessSet.BootstrapWitnesses) > 0 {
+		txType = "Witnessed Tx "
+	}
+	return &TextEnvelope{
+		Type:        txType + era.String() + "Era",
+		Description: "Ledger Cddl Format",
+		CborHex:     tx.Hex(),
+	}
+}
+
+// NewWitnessTextEnvelope returns a cardano-cli witness file envelope for the era.
+func NewWitnessTextEnvelope(witness VKeyWitness, era Era) (*TextEnvelope, error) {
+	data, err := cborEnc.Marshal([]interface{}{keyWitnessTag, witness})
+	if err != nil {
+		return nil, err
+	}
+	return &TextEnvelope{
+		Type:        "TxWitness " + era.String() + "Era",
+		Description: "Key Witness ShelleyEra",
+		CborHex:     hex.EncodeToString(data),
+	}, nil
+}
+
+// ReadTextEnvelopeFile reads a cardano-cli text envelope file.
+func ReadTextEnvelopeFile(path string) (*TextEnvelope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	te := &TextEnvelope{}
+	if err := json.Unmarshal(data, te); err != nil {
+		return nil, err
+	}
+	return te, nil
+}
+
+// WriteFile writes the envelope to a file.
+func (te *TextEnvelope) WriteFile(path string) error {
+	data, err := json.MarshalIndent(te, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Era returns the era of the envelope type, e.g. BabbageEra for
+// "Tx BabbageEra" or "TxWitness BabbageEra".
+func (te *TextEnvelope) Era() (Era, error) {
+	fields := strings.Fields(te.Type)
+	if len(fields) > 0 {
+		name := strings.TrimSuffix(fields[len(fields)-1], "Era")
+		for era := ShelleyEra; era <= ConwayEra; era++ {
+			if name == era.String() {
+				return era, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("text envelope type %q has no known era", te.Type)
+}
+
+// Tx decodes the transaction of a transaction file envelope.
+func (te *TextEnvelope) Tx() (*Tx, error) {
+	if !strings.Contains(te.Type, "Tx ") || strings.HasPrefix(te.Type, "TxWitness") {
+		return nil, fmt.Errorf("text envelope type %q is not a transaction", te.Type)
+	}
+	data, err := hex.DecodeString(te.CborHex)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{}
+	if err := tx.UnmarshalCBOR(data); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// WitnessSet decodes the key or bootstrap witness of a witness file envelope as
+// a witness set, to be merged using Tx.MergeWitnessSet.
+func (te *TextEnvelope) WitnessSet() (*WitnessSet, error) {
+	if !strings.HasPrefix(te.Type, "TxWitness ") {
+		return nil, fmt.Errorf("text envelope type %q is not a witness", te.Type)
+	}
+	data, err := hex.DecodeString(te.CborHex)
+	if err != nil {
+		return nil, err
+	}
+	var witness struct {
+		_       struct{} `cbor:",toarray"`
+		Tag     uint64
+		Witness cbor.RawMessage
+	}
+	if err := cborDec.Unmarshal(data, &witness); err != nil {
+		return nil, err
+	}
+
+	ws := &WitnessSet{}
+	switch witness.Tag {
+	case keyWitnessTag:
+		var vkeyWitness VKeyWitness
+		if err := cborDec.Unmarshal(witness.Witness, &vkeyWitness); err != nil {
+			return nil, err
+		}
+		ws.VKeyWitnessSet = []VKeyWitness{vkeyWitness}
+	case bootstrapWitnessTag:
+		var bootstrapWitness BootstrapWitness
+		if err := cborDec.Unmarshal(witness.Witness, &bootstrapWitness); err != nil {
+			return nil, err
+		}
+		ws.BootstrapWitnesses = []BootstrapWitness{bootstrapWitness}
+	default:
+		return nil, fmt.Errorf("unknown witness tag %v", witness.Tag)
+	}
+	return ws, nil
+}
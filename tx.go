@@ -1,21 +1,44 @@
 package cardano
 
 import (
+	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
+	"reflect"
 
 	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/internal/cbor"
 	"golang.org/x/crypto/blake2b"
 )
 
-const utxoEntrySizeWithoutVal = 27
+const (
+	utxoEntrySizeWithoutVal = 27
+	utxoEntryDatumHashSize  = 10
+	utxoEntryOverheadBytes  = 160
+
+	ed25519PubKeySize    = 32
+	ed25519SignatureSize = 64
+
+	refScriptCostSizeIncrement = 25600
+)
+
+// refScriptCostMultiplier is the increase of the reference scripts price per byte
+// for each refScriptCostSizeIncrement bytes.
+var refScriptCostMultiplier = big.NewRat(6, 5)
 
 // UTxO is a Cardano Unspent Transaction Output.
 type UTxO struct {
-	TxHash  Hash32
-	Spender Address
-	Amount  *Value
-	Index   uint64
+	TxHash    Hash32
+	Spender   Address
+	Amount    *Value
+	Index     uint64
+	ScriptRef *ScriptRef
+
+	// Optionals
+	DatumHash Hash32     // or null
+	Datum     PlutusData // inline datum, or null
 }
 
 // Tx is a Cardano transaction.
@@ -46,11 +69,29 @@ func (tx *Tx) Hash() (Hash32, error) {
 	return tx.Body.Hash()
 }
 
-// UnmarshalCBOR implements cbor.Unmarshaler.
+// UnmarshalCBOR implements cbor.Unmarshaler. The transactions of the
+// Shelley to Mary eras, without the validity flag, are accepted.
 func (tx *Tx) UnmarshalCBOR(data []byte) error {
 	type rawTx Tx
 	var rt rawTx
 
+	if len(data) > 0 && data[0] == 0x83 { // array of 3 items
+		var shelleyTx struct {
+			_             struct{} `cbor:",toarray"`
+			Body          TxBody
+			WitnessSet    WitnessSet
+			AuxiliaryData *AuxiliaryData
+		}
+		if err := cborDec.Unmarshal(data, &shelleyTx); err != nil {
+			return err
+		}
+		tx.Body = shelleyTx.Body
+		tx.WitnessSet = shelleyTx.WitnessSet
+		tx.IsValid = true
+		tx.AuxiliaryData = shelleyTx.AuxiliaryData
+		return nil
+	}
+
 	err := cborDec.Unmarshal(data, &rt)
 	if err != nil {
 		return err
@@ -71,8 +112,125 @@ func (tx *Tx) MarshalCBOR() ([]byte, error) {
 
 // WitnessSet represents the witnesses of the transaction.
 type WitnessSet struct {
-	VKeyWitnessSet []VKeyWitness  `cbor:"0,keyasint,omitempty"`
-	Scripts        []NativeScript `cbor:"1,keyasint,omitempty"`
+	VKeyWitnessSet     []VKeyWitness      `cbor:"0,keyasint,omitempty"`
+	Scripts            []NativeScript     `cbor:"1,keyasint,omitempty"`
+	BootstrapWitnesses []BootstrapWitness `cbor:"2,keyasint,omitempty"`
+	PlutusV1Scripts    []PlutusScript     `cbor:"3,keyasint,omitempty"`
+	PlutusData         []PlutusData       `cbor:"4,keyasint,omitempty"`
+	Redeemers          Redeemers          `cbor:"5,keyasint,omitempty"`
+	PlutusV2Scripts    []PlutusScript     `cbor:"6,keyasint,omitempty"`
+	PlutusV3Scripts    []PlutusScript     `cbor:"7,keyasint,omitempty"`
+
+	// raw is the original encoding of a decoded witness set.
+	raw []byte
+	// rawFields are the original encodings of the decoded fields, by key.
+	rawFields map[uint64][]byte
+	// encodedFields are the re-encodings of the decoded fields, used to detect
+	// changes.
+	encodedFields map[uint64][]byte
+}
+
+// setTag is the tag of the sets of the Conway era.
+var setTag = []byte{0xd9, 0x01, 0x02}
+
+// fields returns the fields of the witness set by key. Decoded redeemers
+// are encoded in the format they were received.
+func (ws *WitnessSet) fields() map[uint64]any {
+	var redeemers any = ws.Redeemers
+	if raw := ws.rawFields[5]; len(raw) > 0 && raw[0]>>5 == 5 { // map
+		redeemers = redeemerMap(ws.Redeemers)
+	}
+	return map[uint64]any{
+		0: ws.VKeyWitnessSet,
+		1: ws.Scripts,
+		2: ws.BootstrapWitnesses,
+		3: ws.PlutusV1Scripts,
+		4: ws.PlutusData,
+		5: redeemers,
+		6: ws.PlutusV2Scripts,
+		7: ws.PlutusV3Scripts,
+	}
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+//
+// The fields of a decoded witness set are encoded as received unless they
+// were modified, as the script data hash covers the original encoding of the
+// datums and redeemers.
+func (ws *WitnessSet) MarshalCBOR() ([]byte, error) {
+	fields := map[uint64]cbor.RawMessage{}
+	for key, raw := range ws.rawFields {
+		if _, known := ws.encodedFields[key]; !known {
+			fields[key] = raw
+		}
+	}
+	changed := false
+	for key, value := range ws.fields() {
+		encoded, err := cborEnc.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		raw, decoded := ws.rawFields[key]
+		switch {
+		case decoded && bytes.Equal(encoded, ws.encodedFields[key]):
+			fields[key] = raw
+		case reflect.ValueOf(value).Len() == 0:
+			changed = changed || decoded
+		default:
+			changed = true
+			if decoded && bytes.HasPrefix(raw, setTag) {
+				encoded = append(append([]byte{}, setTag...), encoded...)
+			}
+			fields[key] = encoded
+		}
+	}
+	if !changed && ws.raw != nil {
+		return ws.raw, nil
+	}
+	return cborEnc.Marshal(fields)
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (ws *WitnessSet) UnmarshalCBOR(data []byte) error {
+	type witnessSet WitnessSet
+	var decoded witnessSet
+	if err := cborDec.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	var rawFields map[uint64]cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &rawFields); err != nil {
+		return err
+	}
+
+	*ws = WitnessSet(decoded)
+	ws.raw = append([]byte{}, data...)
+	ws.rawFields = make(map[uint64][]byte, len(rawFields))
+	ws.encodedFields = make(map[uint64][]byte, len(rawFields))
+	for key, raw := range rawFields {
+		ws.rawFields[key] = append([]byte{}, raw...)
+	}
+	fields := ws.fields()
+	for key := range rawFields {
+		value, ok := fields[key]
+		if !ok {
+			continue // unknown field, copied through
+		}
+		encoded, err := cborEnc.Marshal(value)
+		if err != nil {
+			return err
+		}
+		ws.encodedFields[key] = encoded
+	}
+	return nil
+}
+
+// BootstrapWitness is a witness of a Byron address.
+type BootstrapWitness struct {
+	_          struct{}      `cbor:",toarray"`
+	VKey       crypto.PubKey // ed25519 public key
+	Signature  []byte        // ed25519 signature
+	ChainCode  []byte
+	Attributes []byte
 }
 
 // VKeyWitness is a witnesses that uses verification keys.
@@ -88,6 +246,14 @@ type TxInput struct {
 	TxHash Hash32
 	Index  uint64
 	Amount *Value `cbor:"-"`
+
+	// Spender is the address holding the input, used to estimate the
+	// witnesses required by the transaction.
+	Spender *Address `cbor:"-"`
+
+	// ScriptRef is the reference script held by the input, used to
+	// calculate the reference scripts fee.
+	ScriptRef *ScriptRef `cbor:"-"`
 }
 
 // NewTxInput creates a new instance of TxInput
@@ -100,13 +266,59 @@ func (t TxInput) String() string {
 	return fmt.Sprintf("{TxHash: %v, Index: %v, Amount: %v}", t.TxHash, t.Index, t.Amount)
 }
 
+// outRef returns the reference of the spent output, as txHash#index.
+func (t *TxInput) outRef() string {
+	return outRef(t.TxHash, t.Index)
+}
+
+func outRef(txHash Hash32, index uint64) string {
+	return fmt.Sprintf("%v#%v", txHash, index)
+}
+
 // TxInput is the transaction output.
 type TxOutput struct {
+	Address Address
+	Amount  *Value
+
+	// Optionals
+	DatumHash Hash32     // or null
+	Datum     PlutusData // inline datum, or null
+	ScriptRef *ScriptRef // or null
+}
+
+type legacyTxOutput struct {
 	_       struct{} `cbor:",toarray"`
 	Address Address
 	Amount  *Value
 }
 
+type legacyTxOutputWithDatumHash struct {
+	_         struct{} `cbor:",toarray"`
+	Address   Address
+	Amount    *Value
+	DatumHash Hash32
+}
+
+type postAlonzoTxOutput struct {
+	Address   Address      `cbor:"0,keyasint"`
+	Amount    *Value       `cbor:"1,keyasint"`
+	Datum     *datumOption `cbor:"2,keyasint,omitempty"`
+	ScriptRef *ScriptRef   `cbor:"3,keyasint,omitempty"`
+}
+
+type datumOptionType uint64
+
+const (
+	datumOptionHash datumOptionType = iota
+	datumOptionInline
+)
+
+type datumOption struct {
+	_    struct{} `cbor:",toarray"`
+	Type datumOptionType
+	Data cbor.RawMessage
+}
+
 // NewTxOutput creates a new instance of TxOutput
 func NewTxOutput(addr Address, amount *Value) *TxOutput {
 	return &TxOutput{Address: addr, Amount: amount}
@@ -116,6 +328,113 @@ func (t TxOutput) String() string {
 	return fmt.Sprintf("{Address: %v, Amount: %v}", t.Address, t.Amount)
 }
 
+// Bytes returns the CBOR encoding of the transaction output as bytes.
+func (t *TxOutput) Bytes() []byte {
+	bytes, err := cborEnc.Marshal(t)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+//
+// Outputs without inline datum nor reference script are encoded using the
+// legacy array format, otherwise the post-alonzo map format is used.
+func (t *TxOutput) MarshalCBOR() ([]byte, error) {
+	if t.Datum == nil && t.ScriptRef == nil {
+		if t.DatumHash == nil {
+			return cborEnc.Marshal(legacyTxOutput{Address: t.Address, Amount: t.Amount})
+		}
+		return cborEnc.Marshal(legacyTxOutputWithDatumHash{
+			Address:   t.Address,
+			Amount:    t.Amount,
+			DatumHash: t.DatumHash,
+		})
+	}
+
+	out := postAlonzoTxOutput{Address: t.Address, Amount: t.Amount, ScriptRef: t.ScriptRef}
+	switch {
+	case t.Datum != nil:
+		data, err := cborEnc.Marshal(cbor.Tag{Number: 24, Content: []byte(t.Datum)})
+		if err != nil {
+			return nil, err
+		}
+		out.Datum = &datumOption{Type: datumOptionInline, Data: data}
+	case t.DatumHash != nil:
+		data, err := cborEnc.Marshal(t.DatumHash)
+		if err != nil {
+			return nil, err
+		}
+		out.Datum = &datumOption{Type: datumOptionHash, Data: data}
+	}
+	return cborEnc.Marshal(out)
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (t *TxOutput) UnmarshalCBOR(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("cbor: empty transaction output")
+	}
+
+	// Major type 5 (map) is used by the post-alonzo format
+	if data[0]>>5 == 5 {
+		var out postAlonzoTxOutput
+		if err := cborDec.Unmarshal(data, &out); err != nil {
+			return err
+		}
+		t.Address = out.Address
+		t.Amount = out.Amount
+		t.ScriptRef = out.ScriptRef
+		if out.Datum != nil {
+			switch out.Datum.Type {
+			case datumOptionHash:
+				if err := cborDec.Unmarshal(out.Datum.Data, &t.DatumHash); err != nil {
+					return err
+				}
+			case datumOptionInline:
+				var tag cbor.RawTag
+				if err := cborDec.Unmarshal(out.Datum.Data, &tag); err != nil {
+					return err
+				}
+				var datum []byte
+				if err := cborDec.Unmarshal(tag.Content, &datum); err != nil {
+					return err
+				}
+				t.Datum = datum
+			default:
+				return fmt.Errorf("cbor: unknown datum option %v", out.Datum.Type)
+			}
+		}
+		return nil
+	}
+
+	var fields []cbor.RawMessage
+	if err := cborDec.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	switch len(fields) {
+	case 2:
+		var out legacyTxOutput
+		if err := cborDec.Unmarshal(data, &out); err != nil {
+			return err
+		}
+		t.Address = out.Address
+		t.Amount = out.Amount
+	case 3:
+		var out legacyTxOutputWithDatumHash
+		if err := cborDec.Unmarshal(data, &out); err != nil {
+			return err
+		}
+		t.Address = out.Address
+		t.Amount = out.Amount
+		t.DatumHash = out.DatumHash
+	default:
+		return fmt.Errorf("cbor: invalid transaction output length %v", len(fields))
+	}
+	return nil
+}
+
 type TxBody struct {
 	Inputs  []*TxInput  `cbor:"0,keyasint"`
 	Outputs []*TxOutput `cbor:"1,keyasint"`
@@ -124,15 +443,21 @@ type TxBody struct {
 	// Optionals
 	TTL                   Uint64        `cbor:"3,keyasint,omitempty"`
 	Certificates          []Certificate `cbor:"4,keyasint,omitempty"`
-	Withdrawals           any           `cbor:"5,keyasint,omitempty"` // unsupported
-	Update                any           `cbor:"6,keyasint,omitempty"` // unsupported
+	Withdrawals           *Withdrawals  `cbor:"5,keyasint,omitempty"`
+	Update                *Update       `cbor:"6,keyasint,omitempty"`
 	AuxiliaryDataHash     *Hash32       `cbor:"7,keyasint,omitempty"`
 	ValidityIntervalStart Uint64        `cbor:"8,keyasint,omitempty"`
 	Mint                  *Mint         `cbor:"9,keyasint,omitempty"`
-	ScriptDataHash        *Hash32       `cbor:"10,keyasint,omitempty"`
-	Collateral            []TxInput     `cbor:"11,keyasint,omitempty"`
-	RequiredSigners       []AddrKeyHash `cbor:"12,keyasint,omitempty"`
-	NetworkID             Uint64        `cbor:"13,keyasint,omitempty"`
+	ScriptDataHash        *Hash32       `cbor:"11,keyasint,omitempty"`
+	Collateral            []TxInput     `cbor:"13,keyasint,omitempty"`
+	RequiredSigners       []AddrKeyHash `cbor:"14,keyasint,omitempty"`
+	NetworkID             Uint64        `cbor:"15,keyasint,omitempty"`
+	ReferenceInputs       []*TxInput    `cbor:"18,keyasint,omitempty"`
+
+	// raw is the original encoding of a decoded body.
+	raw []byte
+	// encoded is the re-encoding of the decoded body, used to detect changes.
+	encoded []byte
 }
 
 // Hash returns the transaction body hash using blake2b256.
@@ -144,3 +469,96 @@ func (body *TxBody) Hash() (Hash32, error) {
 	hash := blake2b.Sum256(bytes)
 	return hash[:], nil
 }
+
+// MarshalCBOR implements cbor.Marshaler.
+//
+// A decoded body is encoded as received unless it was modified.
+func (body *TxBody) MarshalCBOR() ([]byte, error) {
+	type rawTxBody TxBody
+	encoded, err := cborEnc.Marshal((*rawTxBody)(body))
+	if err != nil {
+		return nil, err
+	}
+	if body.raw != nil && bytes.Equal(encoded, body.encoded) {
+		return body.raw, nil
+	}
+	return encoded, nil
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (body *TxBody) UnmarshalCBOR(data []byte) error {
+	type rawTxBody TxBody
+	var rb rawTxBody
+	if err := cborDec.Unmarshal(data, &rb); err != nil {
+		return err
+	}
+	encoded, err := cborEnc.Marshal(&rb)
+	if err != nil {
+		return err
+	}
+	*body = TxBody(rb)
+	body.raw = append([]byte{}, data...)
+	body.encoded = encoded
+	return nil
+}
+
+// Withdrawals are the rewards withdrawn from reward accounts.
+type Withdrawals struct {
+	m map[cbor.ByteString]Coin
+}
+
+// NewWithdrawals returns a new empty Withdrawals.
+func NewWithdrawals() *Withdrawals {
+	return &Withdrawals{m: make(map[cbor.ByteString]Coin)}
+}
+
+// Set sets the amount withdrawn from a reward account.
+func (w *Withdrawals) Set(rewardAccount Address, amount Coin) *Withdrawals {
+	w.m[cbor.NewByteString(rewardAccount.Bytes())] = amount
+	return w
+}
+
+// Get returns the amount withdrawn from a reward account.
+func (w *Withdrawals) Get(rewardAccount Address) Coin {
+	return w.m[cbor.NewByteString(rewardAccount.Bytes())]
+}
+
+// Keys returns the reward accounts of the withdrawals.
+func (w *Withdrawals) Keys() []Address {
+	rewardAccounts := []Address{}
+	for k := range w.m {
+		rewardAccount, err := NewAddressFromBytes(k.Bytes())
+		if err != nil {
+			continue // validated on decoding
+		}
+		rewardAccounts = append(rewardAccounts, rewardAccount)
+	}
+	return rewardAccounts
+}
+
+// Total returns the total amount withdrawn.
+func (w *Withdrawals) Total() Coin {
+	var total Coin
+	for _, amount := range w.m {
+		total += amount
+	}
+	return total
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (w *Withdrawals) MarshalCBOR() ([]byte, error) {
+	return cborEnc.Marshal(w.m)
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+func (w *Withdrawals) UnmarshalCBOR(data []byte) error {
+	if err := cborDec.Unmarshal(data, &w.m); err != nil {
+		return err
+	}
+	for k := range w.m {
+		if _, err := NewAddressFromBytes(k.Bytes()); err != nil {
+			return fmt.Errorf("invalid reward account %x: %w", k.Bytes(), err)
+		}
+	}
+	return nil
+}
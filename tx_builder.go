@@ -1,27 +1,35 @@
 package cardano
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"sort"
+	"time"
 
 	"github.com/cryptogarageinc/cardano-go/crypto"
-	"golang.org/x/crypto/blake2b"
 )
 
 // TxBuilder is a transaction builder.
 type TxBuilder struct {
 	tx       *Tx
 	protocol *ProtocolParams
-	pkeys    []crypto.PrvKey
+	signers  []Signer
 
-	changeReceiver *Address
+	changeReceiver        *Address
+	changeGroupedByPolicy bool
+
+	slotConfig *SlotConfig
 }
 
 // NewTxBuilder returns a new instance of TxBuilder.
 func NewTxBuilder(protocol *ProtocolParams) *TxBuilder {
 	return &TxBuilder{
 		protocol: protocol,
-		pkeys:    []crypto.PrvKey{},
+		signers:  []Signer{},
 		tx: &Tx{
 			IsValid: true,
 		},
@@ -53,6 +61,52 @@ func (tb *TxBuilder) SetTTL(ttl uint64) {
 	tb.tx.Body.TTL = NewUint64(ttl)
 }
 
+// SetValidityIntervalStart sets the slot from which the transaction is valid.
+func (tb *TxBuilder) SetValidityIntervalStart(slot uint64) {
+	tb.tx.Body.ValidityIntervalStart = NewUint64(slot)
+}
+
+// SetSlotConfig sets the slot configuration used to convert times into slots.
+func (tb *TxBuilder) SetSlotConfig(slotConfig *SlotConfig) {
+	tb.slotConfig = slotConfig
+}
+
+// SetValidUntil sets the transaction's time to live so that it is not valid
+// in the slot containing the given time, nor after it.
+func (tb *TxBuilder) SetValidUntil(t time.Time) error {
+	if tb.slotConfig == nil {
+		return errors.New("slot config not set")
+	}
+	slot, err := tb.slotConfig.TimeToSlot(t)
+	if err != nil {
+		return err
+	}
+	tb.SetTTL(slot)
+	return nil
+}
+
+// SetValidFrom sets the validity interval start of the transaction to the first
+// slot starting at or after the given time.
+func (tb *TxBuilder) SetValidFrom(t time.Time) error {
+	if tb.slotConfig == nil {
+		return errors.New("slot config not set")
+	}
+	slot, err := tb.slotConfig.TimeToSlot(t)
+	if err != nil {
+		return err
+	}
+	if tb.slotConfig.SlotToTime(slot).Before(t) {
+		slot++
+	}
+	tb.SetValidityIntervalStart(slot)
+	return nil
+}
+
+// SetNetworkID sets the network ID of the transaction.
+func (tb *TxBuilder) SetNetworkID(network Network) {
+	tb.tx.Body.NetworkID = NewUint64(uint64(network.ID()))
+}
+
 // SetFee sets the transactions's fee.
 func (tb *TxBuilder) SetFee(fee Coin) {
 	tb.tx.Body.Fee = fee
@@ -73,6 +127,49 @@ func (tb *TxBuilder) AddNativeScript(script NativeScript) {
 	tb.tx.WitnessSet.Scripts = append(tb.tx.WitnessSet.Scripts, script)
 }
 
+// AddPlutusScript adds a plutus script of the given version to the transaction.
+func (tb *TxBuilder) AddPlutusScript(version ScriptHashNamespace, script PlutusScript) error {
+	switch version {
+	case PlutusScriptNamespace:
+		tb.tx.WitnessSet.PlutusV1Scripts = append(tb.tx.WitnessSet.PlutusV1Scripts, script)
+	case PlutusV2ScriptNamespace:
+		tb.tx.WitnessSet.PlutusV2Scripts = append(tb.tx.WitnessSet.PlutusV2Scripts, script)
+	case PlutusV3ScriptNamespace:
+		tb.tx.WitnessSet.PlutusV3Scripts = append(tb.tx.WitnessSet.PlutusV3Scripts, script)
+	default:
+		return fmt.Errorf("invalid plutus script version %v", version)
+	}
+	return nil
+}
+
+// AddPlutusData adds datums to the transaction witness set.
+func (tb *TxBuilder) AddPlutusData(data ...PlutusData) {
+	tb.tx.WitnessSet.PlutusData = append(tb.tx.WitnessSet.PlutusData, data...)
+}
+
+// AddRedeemers adds redeemers to the transaction witness set.
+func (tb *TxBuilder) AddRedeemers(redeemers ...Redeemer) {
+	tb.tx.WitnessSet.Redeemers = append(tb.tx.WitnessSet.Redeemers, redeemers...)
+}
+
+// AddWithdrawal adds a withdrawal of rewards from a reward account to the transaction.
+func (tb *TxBuilder) AddWithdrawal(rewardAccount Address, amount Coin) {
+	if tb.tx.Body.Withdrawals == nil {
+		tb.tx.Body.Withdrawals = NewWithdrawals()
+	}
+	tb.tx.Body.Withdrawals.Set(rewardAccount, amount)
+}
+
+// AddReferenceInputs adds reference inputs to the transaction.
+func (tb *TxBuilder) AddReferenceInputs(inputs ...*TxInput) {
+	tb.tx.Body.ReferenceInputs = append(tb.tx.Body.ReferenceInputs, inputs...)
+}
+
+// SetScriptDataHash sets the hash of the redeemers, datums and cost models of the transaction.
+func (tb *TxBuilder) SetScriptDataHash(hash Hash32) {
+	tb.tx.Body.ScriptDataHash = &hash
+}
+
 // Mint adds a new multiasset to mint.
 func (tb *TxBuilder) Mint(asset *Mint) {
 	tb.tx.Body.Mint = asset
@@ -84,6 +181,12 @@ func (tb *TxBuilder) AddChangeIfNeeded(changeAddr Address) {
 	tb.changeReceiver = &changeAddr
 }
 
+// SetChangeGroupedByPolicy sets whether the change assets of different policies are
+// sent to different change outputs.
+func (tb *TxBuilder) SetChangeGroupedByPolicy(grouped bool) {
+	tb.changeGroupedByPolicy = grouped
+}
+
 func (tb *TxBuilder) calculateAmounts() (*Value, *Value) {
 	input, output := NewValue(0), NewValue(tb.totalDeposits())
 	for _, in := range tb.tx.Body.Inputs {
@@ -92,6 +195,9 @@ func (tb *TxBuilder) calculateAmounts() (*Value, *Value) {
 	for _, out := range tb.tx.Body.Outputs {
 		output = output.Add(out.Amount)
 	}
+	if tb.tx.Body.Withdrawals != nil {
+		input = input.Add(NewValue(tb.tx.Body.Withdrawals.Total()))
+	}
 	if tb.tx.Body.Mint != nil {
 		input = input.Add(NewValueWithAssets(0, tb.tx.Body.Mint.MultiAsset()))
 	}
@@ -112,23 +218,38 @@ func (tb *TxBuilder) totalDeposits() Coin {
 }
 
 // MinFee computes the minimal fee required for the transaction.
-// This assumes that the inputs-outputs are defined and signing keys are present.
+// This assumes that the inputs-outputs are defined. Signing keys are not required,
+// the witnesses are estimated from the spenders of the inputs, the required signers,
+// the native scripts and the certificates.
 func (tb *TxBuilder) MinFee() (Coin, error) {
 	// Set a temporary realistic fee in order to serialize a valid transaction
 	currentFee := tb.tx.Body.Fee
 	tb.tx.Body.Fee = 200000
-	if err := tb.build(); err != nil {
+	defer func() { tb.tx.Body.Fee = currentFee }()
+	if err := tb.buildBody(); err != nil {
 		return 0, err
 	}
-	minFee := tb.calculateMinFee()
-	tb.tx.Body.Fee = currentFee
-	return minFee, nil
+	return tb.calculateMinFee()
 }
 
 // MinCoinsForTxOut computes the minimal amount of coins required for a given transaction output.
+func (tb *TxBuilder) MinCoinsForTxOut(txOut *TxOutput) Coin {
+	return MinCoinsForTxOut(tb.protocol, txOut)
+}
+
+// MinCoinsForTxOut computes the minimal amount of coins required for a given transaction output
+// using the rule of the era selected from the protocol parameters.
+func MinCoinsForTxOut(protocol *ProtocolParams, txOut *TxOutput) Coin {
+	if protocol.Era() >= BabbageEra {
+		return minCoinsForTxOutBabbage(protocol, txOut)
+	}
+	return minCoinsForTxOutAlonzo(protocol, txOut)
+}
+
+// minCoinsForTxOutAlonzo computes the minimal amount of coins using the alonzo rule.
 // More info could be found in
 // <https://github.com/input-output-hk/cardano-ledger/blob/master/doc/explanations/min-utxo-alonzo.rst>
-func (tb *TxBuilder) MinCoinsForTxOut(txOut *TxOutput) Coin {
+func minCoinsForTxOutAlonzo(protocol *ProtocolParams, txOut *TxOutput) Coin {
 	var size uint
 	if txOut.Amount.OnlyCoin() {
 		size = 1
@@ -141,35 +262,220 @@ func (tb *TxBuilder) MinCoinsForTxOut(txOut *TxOutput) Coin {
 			float64(numAssets*12+assetsLength+numPIDs*28+7)/8,
 		))
 	}
-	return Coin(utxoEntrySizeWithoutVal+size) * tb.protocol.CoinsPerUTXOWord
+	if txOut.DatumHash != nil {
+		size += utxoEntryDatumHashSize
+	}
+	return Coin(utxoEntrySizeWithoutVal+size) * protocol.CoinsPerUTXOWord
 }
 
-// Calculate minimum lovelace a transaction output needs to hold post alonzo.
-// This implementation is copied from the origianl Haskell implementation:
+// minCoinsForTxOutBabbage computes the minimal amount of coins post alonzo, that is
+// (160 + serialized output size) * coinsPerUTxOByte.
+// The coin field is part of the serialized output, so the computation is repeated
+// until its encoded size stabilizes.
+// This implementation follows the original Haskell implementation:
 // https://github.com/input-output-hk/cardano-ledger/blob/eb053066c1d3bb51fb05978eeeab88afc0b049b2/eras/babbage/impl/src/Cardano/Ledger/Babbage/Rules/Utxo.hs#L242-L265
-// TODO:
+func minCoinsForTxOutBabbage(protocol *ProtocolParams, txOut *TxOutput) Coin {
+	coinsPerUTXOByte := protocol.CoinsPerUTXOByte
+	if coinsPerUTXOByte == 0 {
+		// Babbage translated the alonzo parameter to bytes
+		coinsPerUTXOByte = protocol.CoinsPerUTXOWord / 8
+	}
+
+	out := *txOut
+	out.Amount = &Value{Coin: 0, MultiAsset: txOut.Amount.MultiAsset}
+	for {
+		size := Coin(len(out.Bytes()))
+		minCoins := (utxoEntryOverheadBytes + size) * coinsPerUTXOByte
+		if minCoins <= out.Amount.Coin {
+			return minCoins
+		}
+		out.Amount.Coin = minCoins
+	}
+}
+
+// MinTxFee computes the minimal fee required for a transaction, that is the sum of:
+//   - the size fee: minFeeA * size + minFeeB
+//   - the script fee: the execution units of the redeemers times their prices, rounded up
+//   - the reference scripts fee: tiered over the total size of the reference scripts
+//     held by the spent and referenced inputs
+//
+// This implementation follows the original Haskell implementation:
+// https://github.com/IntersectMBO/cardano-ledger/blob/master/eras/conway/impl/src/Cardano/Ledger/Conway/Tx.hs
+func MinTxFee(protocol *ProtocolParams, tx *Tx, refScriptsSize uint) Coin {
+	txLength := uint64(len(tx.Bytes()))
+	sizeFee := protocol.MinFeeA*Coin(txLength) + protocol.MinFeeB
+	return sizeFee +
+		scriptFee(protocol.ExecutionCosts, tx.WitnessSet.Redeemers.ExUnits()) +
+		refScriptsFee(protocol.MinFeeRefScriptCostPerByte, refScriptsSize)
+}
+
+// scriptFee computes the cost of the execution units, rounded up.
+func scriptFee(prices ExUnitPrices, exUnits ExUnits) Coin {
+	fee := new(big.Rat).Mul(prices.MemPrice.Rat(), new(big.Rat).SetUint64(exUnits.Mem))
+	fee.Add(fee, new(big.Rat).Mul(prices.StepPrice.Rat(), new(big.Rat).SetUint64(exUnits.Steps)))
+	return ratCeil(fee)
+}
+
+// refScriptsFee computes the reference scripts fee. The price per byte is multiplied
+// by refScriptCostMultiplier for each refScriptCostSizeIncrement bytes, and the result
+// is rounded down.
+func refScriptsFee(costPerByte Rational, size uint) Coin {
+	fee := new(big.Rat)
+	price := costPerByte.Rat()
+	for size > 0 {
+		tierSize := min(size, refScriptCostSizeIncrement)
+		fee.Add(fee, new(big.Rat).Mul(price, new(big.Rat).SetUint64(uint64(tierSize))))
+		price = new(big.Rat).Mul(price, refScriptCostMultiplier)
+		size -= tierSize
+	}
+	return Coin(new(big.Int).Quo(fee.Num(), fee.Denom()).Uint64())
+}
+
+func ratCeil(r *big.Rat) Coin {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return Coin(q.Uint64())
+}
 
 // calculateMinFee computes the minimal fee required for the transaction.
-func (tb *TxBuilder) calculateMinFee() Coin {
-	txBytes := tb.tx.Bytes()
-	txLength := uint64(len(txBytes))
-	return tb.protocol.MinFeeA*Coin(txLength) + tb.protocol.MinFeeB
+func (tb *TxBuilder) calculateMinFee() (Coin, error) {
+	fakeTx, err := tb.fakeTx()
+	if err != nil {
+		return 0, err
+	}
+	return MinTxFee(tb.protocol, fakeTx, tb.refScriptsSize()), nil
+}
+
+// refScriptsSize returns the total size of the reference scripts held by the
+// spent and referenced inputs. An input both spent and referenced is counted once.
+func (tb *TxBuilder) refScriptsSize() uint {
+	var size uint
+	seen := map[string]struct{}{}
+	inputs := append(append([]*TxInput{}, tb.tx.Body.Inputs...), tb.tx.Body.ReferenceInputs...)
+	for _, in := range inputs {
+		if in.ScriptRef == nil {
+			continue
+		}
+		key := in.outRef()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		script, err := in.ScriptRef.Bytes()
+		if err != nil {
+			continue
+		}
+		size += uint(len(script))
+	}
+	return size
+}
+
+// fakeTx returns a copy of the transaction with a dummy vkey witness for each
+// required key, so it has the same size as the signed transaction.
+func (tb *TxBuilder) fakeTx() (*Tx, error) {
+	keyHashes, err := tb.requiredKeyHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	fakeTx := *tb.tx
+	if fakeTx.Body.ScriptDataHash == nil &&
+		(len(fakeTx.WitnessSet.Redeemers) != 0 || len(fakeTx.WitnessSet.PlutusData) != 0) {
+		scriptDataHash := Hash32(make([]byte, 32))
+		fakeTx.Body.ScriptDataHash = &scriptDataHash
+	}
+	fakeTx.WitnessSet.VKeyWitnessSet = make([]VKeyWitness, len(keyHashes))
+	for i := range fakeTx.WitnessSet.VKeyWitnessSet {
+		fakeTx.WitnessSet.VKeyWitnessSet[i] = VKeyWitness{
+			VKey:      make([]byte, ed25519PubKeySize),
+			Signature: make([]byte, ed25519SignatureSize),
+		}
+	}
+	return &fakeTx, nil
+}
+
+// requiredKeyHashes returns the distinct key hashes expected to sign the transaction.
+func (tb *TxBuilder) requiredKeyHashes() (map[string]struct{}, error) {
+	keyHashes := map[string]struct{}{}
+	addKeyHash := func(keyHash AddrKeyHash) {
+		if len(keyHash) != 0 {
+			keyHashes[keyHash.String()] = struct{}{}
+		}
+	}
+	addCredential := func(cred StakeCredential) {
+		if cred.Type == KeyCredential {
+			addKeyHash(cred.KeyHash)
+		}
+	}
+
+	for _, signer := range tb.signers {
+		keyHash, err := signer.KeyHash()
+		if err != nil {
+			return nil, err
+		}
+		addKeyHash(keyHash)
+	}
+	for _, in := range tb.tx.Body.Inputs {
+		if in.Spender != nil {
+			addCredential(in.Spender.Payment)
+		}
+	}
+	for _, in := range tb.tx.Body.Collateral {
+		if in.Spender != nil {
+			addCredential(in.Spender.Payment)
+		}
+	}
+	for _, signer := range tb.tx.Body.RequiredSigners {
+		addKeyHash(signer)
+	}
+	for _, script := range tb.tx.WitnessSet.Scripts {
+		for _, keyHash := range script.keyHashes() {
+			addKeyHash(keyHash)
+		}
+	}
+	for _, cert := range tb.tx.Body.Certificates {
+		for _, cred := range cert.witnessCredentials() {
+			addCredential(cred)
+		}
+	}
+	if tb.tx.Body.Withdrawals != nil {
+		for _, rewardAccount := range tb.tx.Body.Withdrawals.Keys() {
+			addCredential(rewardAccount.Stake)
+		}
+	}
+
+	return keyHashes, nil
 }
 
 // Sign adds signing keys to create signatures for the witness set.
 func (tb *TxBuilder) Sign(privateKeys ...crypto.PrvKey) {
-	tb.pkeys = append(tb.pkeys, privateKeys...)
+	for _, prv := range privateKeys {
+		tb.signers = append(tb.signers, NewPrvKeySigner(prv))
+	}
+}
+
+// AddSigners adds signers to create signatures for the witness set.
+func (tb *TxBuilder) AddSigners(signers ...Signer) {
+	tb.signers = append(tb.signers, signers...)
 }
 
 // Reset resets the builder to its initial state.
 func (tb *TxBuilder) Reset() {
 	tb.tx = &Tx{IsValid: true}
-	tb.pkeys = []crypto.PrvKey{}
+	tb.signers = []Signer{}
 	tb.changeReceiver = nil
+	tb.changeGroupedByPolicy = false
 }
 
 // Build returns a new transaction using the inputs, outputs and keys provided.
 func (tb *TxBuilder) Build() (*Tx, error) {
+	return tb.BuildContext(context.Background())
+}
+
+// BuildContext is like Build, the context is passed to the signers.
+func (tb *TxBuilder) BuildContext(ctx context.Context) (*Tx, error) {
 	inputAmount, outputAmount := tb.calculateAmounts()
 
 	// Check input-output value conservation
@@ -197,7 +503,11 @@ func (tb *TxBuilder) Build() (*Tx, error) {
 		}
 	}
 
-	if err := tb.build(); err != nil {
+	if err := tb.build(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := tb.Validate(); err != nil {
 		return nil, err
 	}
 
@@ -208,12 +518,14 @@ func (tb *TxBuilder) addChangeIfNeeded(inputAmount, outputAmount *Value) error {
 	// Temporary fee to serialize a valid transaction
 	tb.tx.Body.Fee = 2e5
 
-	// TODO: We should build a fake tx with hardcoded data like signatures, hashes, etc
-	if err := tb.build(); err != nil {
+	if err := tb.buildBody(); err != nil {
 		return err
 	}
 
-	minFee := tb.calculateMinFee()
+	minFee, err := tb.calculateMinFee()
+	if err != nil {
+		return err
+	}
 	outputAmount = outputAmount.Add(NewValue(minFee))
 
 	if inputOutputCmp := inputAmount.Cmp(outputAmount); inputOutputCmp == -1 || inputOutputCmp == 2 {
@@ -227,12 +539,16 @@ func (tb *TxBuilder) addChangeIfNeeded(inputAmount, outputAmount *Value) error {
 		return nil
 	}
 
-	// Construct change output
+	// Construct change outputs
 	changeAmount := inputAmount.Sub(outputAmount)
-	changeOutput := NewTxOutput(*tb.changeReceiver, changeAmount)
+	changeOutputs, err := tb.changeOutputs(changeAmount)
+	if err != nil {
+		return err
+	}
 
+	changeOutput := changeOutputs[0]
 	changeMinCoins := tb.MinCoinsForTxOut(changeOutput)
-	if changeAmount.Coin < changeMinCoins {
+	if changeOutput.Amount.Coin < changeMinCoins {
 		if changeAmount.OnlyCoin() {
 			tb.tx.Body.Fee = minFee + changeAmount.Coin // burn change
 			return nil
@@ -240,33 +556,150 @@ func (tb *TxBuilder) addChangeIfNeeded(inputAmount, outputAmount *Value) error {
 		return fmt.Errorf(
 			"insuficient input for change output with multiassets, got %v want %v",
 			inputAmount.Coin,
-			inputAmount.Coin+changeMinCoins-changeAmount.Coin,
+			inputAmount.Coin+changeMinCoins-changeOutput.Amount.Coin,
 		)
 	}
 
-	tb.tx.Body.Outputs = append([]*TxOutput{changeOutput}, tb.tx.Body.Outputs...)
+	tb.tx.Body.Outputs = append(changeOutputs, tb.tx.Body.Outputs...)
 
-	newMinFee := tb.calculateMinFee()
-	changeAmount.Coin = changeAmount.Coin + minFee - newMinFee
-	if changeAmount.Coin < changeMinCoins {
+	newMinFee, err := tb.calculateMinFee()
+	if err != nil {
+		return err
+	}
+	if changeOutput.Amount.Coin+minFee < newMinFee+changeMinCoins {
 		if changeAmount.OnlyCoin() {
-			tb.tx.Body.Fee = newMinFee + changeAmount.Coin // burn change
-			tb.tx.Body.Outputs = tb.tx.Body.Outputs[1:]    // remove change output
+			tb.tx.Body.Fee = minFee + changeAmount.Coin // burn change
+			tb.tx.Body.Outputs = tb.tx.Body.Outputs[1:] // remove change output
 			return nil
 		}
 		return fmt.Errorf(
 			"insuficient input for change output with multiassets, got %v want %v",
 			inputAmount.Coin,
-			changeMinCoins,
+			inputAmount.Coin+newMinFee+changeMinCoins-minFee-changeOutput.Amount.Coin,
 		)
 	}
+	changeOutput.Amount.Coin = changeOutput.Amount.Coin + minFee - newMinFee
 
 	tb.tx.Body.Fee = newMinFee
 
 	return nil
 }
 
-func (tb *TxBuilder) build() error {
+// changeOutputs splits the change into outputs whose value fits in the protocol
+// MaxValueSize, grouping the assets by policy if required. Each output holds its
+// minimal amount of coins and the remaining coins are added to the first output.
+func (tb *TxBuilder) changeOutputs(change *Value) ([]*TxOutput, error) {
+	bundles, err := tb.splitChangeAssets(change)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return []*TxOutput{NewTxOutput(*tb.changeReceiver, NewValue(change.Coin))}, nil
+	}
+
+	var totalMinCoins Coin
+	outputs := make([]*TxOutput, len(bundles))
+	for i, bundle := range bundles {
+		outputs[i] = NewTxOutput(*tb.changeReceiver, NewValueWithAssets(0, bundle))
+		outputs[i].Amount.Coin = tb.MinCoinsForTxOut(outputs[i])
+		totalMinCoins += outputs[i].Amount.Coin
+	}
+	if change.Coin < totalMinCoins {
+		return nil, fmt.Errorf(
+			"insuficient input for %v change outputs with multiassets, got %v change coins want %v",
+			len(outputs),
+			change.Coin,
+			totalMinCoins,
+		)
+	}
+	outputs[0].Amount.Coin += change.Coin - totalMinCoins
+
+	return outputs, nil
+}
+
+// splitChangeAssets splits the assets of the change into bundles whose value,
+// holding the whole change coins, fits in the protocol MaxValueSize.
+// Assets with a zero quantity are dropped.
+func (tb *TxBuilder) splitChangeAssets(change *Value) ([]*MultiAsset, error) {
+	if change.OnlyCoin() {
+		return nil, nil
+	}
+
+	policyIDs := change.MultiAsset.Keys()
+	sort.Slice(policyIDs, func(i, j int) bool {
+		return bytes.Compare(policyIDs[i].Bytes(), policyIDs[j].Bytes()) < 0
+	})
+
+	var bundles []*MultiAsset
+	bundle := NewMultiAsset()
+	flush := func() {
+		if len(bundle.m) != 0 {
+			bundles = append(bundles, bundle)
+			bundle = NewMultiAsset()
+		}
+	}
+	for _, policyID := range policyIDs {
+		if tb.changeGroupedByPolicy {
+			flush()
+		}
+		assets := change.MultiAsset.Get(policyID)
+		assetNames := assets.Keys()
+		sort.Slice(assetNames, func(i, j int) bool {
+			return bytes.Compare(assetNames[i].Bytes(), assetNames[j].Bytes()) < 0
+		})
+		for _, assetName := range assetNames {
+			quantity := assets.Get(assetName)
+			if quantity == 0 {
+				continue
+			}
+			if bundle.Get(policyID) == nil {
+				bundle.Set(policyID, NewAssets())
+			}
+			bundle.Get(policyID).Set(assetName, quantity)
+			if tb.fitsMaxValueSize(NewValueWithAssets(change.Coin, bundle)) {
+				continue
+			}
+
+			bundle.removeAsset(policyID, assetName)
+			if len(bundle.m) == 0 {
+				return nil, fmt.Errorf(
+					"asset %v.%v exceeds the max value size %v",
+					policyID.String(),
+					assetName,
+					tb.protocol.MaxValueSize,
+				)
+			}
+			flush()
+			bundle.Set(policyID, NewAssets().Set(assetName, quantity))
+			if !tb.fitsMaxValueSize(NewValueWithAssets(change.Coin, bundle)) {
+				return nil, fmt.Errorf(
+					"asset %v.%v exceeds the max value size %v",
+					policyID.String(),
+					assetName,
+					tb.protocol.MaxValueSize,
+				)
+			}
+		}
+	}
+	flush()
+
+	return bundles, nil
+}
+
+// fitsMaxValueSize returns true if the serialized value fits in the protocol MaxValueSize.
+// A zero MaxValueSize means no limit.
+func (tb *TxBuilder) fitsMaxValueSize(value *Value) bool {
+	if tb.protocol.MaxValueSize == 0 {
+		return true
+	}
+	valueBytes, err := cborEnc.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return uint(len(valueBytes)) <= tb.protocol.MaxValueSize
+}
+
+func (tb *TxBuilder) build(ctx context.Context) error {
 	if err := tb.buildBody(); err != nil {
 		return err
 	}
@@ -277,12 +710,13 @@ func (tb *TxBuilder) build() error {
 	}
 
 	// Create witness set
-	tb.tx.WitnessSet.VKeyWitnessSet = make([]VKeyWitness, len(tb.pkeys))
-	for i, pkey := range tb.pkeys {
-		tb.tx.WitnessSet.VKeyWitnessSet[i] = VKeyWitness{
-			VKey:      pkey.PubKey(),
-			Signature: pkey.Sign(txHash),
+	tb.tx.WitnessSet.VKeyWitnessSet = make([]VKeyWitness, len(tb.signers))
+	for i, signer := range tb.signers {
+		witness, err := NewVKeyWitness(ctx, signer, txHash)
+		if err != nil {
+			return err
 		}
+		tb.tx.WitnessSet.VKeyWitnessSet[i] = witness
 	}
 
 	return nil
@@ -290,13 +724,11 @@ func (tb *TxBuilder) build() error {
 
 func (tb *TxBuilder) buildBody() error {
 	if tb.tx.AuxiliaryData != nil {
-		auxBytes, err := cborEnc.Marshal(tb.tx.AuxiliaryData)
+		auxHash, err := tb.tx.AuxiliaryData.Hash()
 		if err != nil {
 			return err
 		}
-		auxHash := blake2b.Sum256(auxBytes)
-		auxHash32 := Hash32(auxHash[:])
-		tb.tx.Body.AuxiliaryDataHash = &auxHash32
+		tb.tx.Body.AuxiliaryDataHash = &auxHash
 	}
 	return nil
 }
@@ -1,7 +1,12 @@
 package cardano
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/cryptogarageinc/cardano-go/crypto"
@@ -99,6 +104,98 @@ func TestMinUTXO(t *testing.T) {
 	}
 }
 
+var babbageProtocol = &ProtocolParams{
+	CoinsPerUTXOByte: 4310,
+	MinFeeA:          44,
+	MinFeeB:          155381,
+	ProtocolVersion:  ProtocolVersion{Major: 8},
+}
+
+func TestMinUTXOBabbage(t *testing.T) {
+	baseAddr, err := NewAddress("addr1qxn0t7jnv8lrdd5xa6mlcap6qf8ln08pc6k8qxa7un0new2pkrthnm4f5hn6eg3nju6jn6l3994ucy099cw42xu7rmjq8l960u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enterpriseAddr, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyScript, err := NewScriptPubKey(crypto.NewXPrvKeyFromEntropy([]byte("pol1"), "").PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyID, err := NewPolicyID(policyScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datum, err := NewPlutusData("d87980")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name    string
+		txOut   *TxOutput
+		minUTXO Coin
+	}{
+		{
+			name:    "base address, only coin",
+			txOut:   NewTxOutput(baseAddr, NewValue(0)),
+			minUTXO: Coin(969750),
+		},
+		{
+			name:    "enterprise address, only coin",
+			txOut:   NewTxOutput(enterpriseAddr, NewValue(0)),
+			minUTXO: Coin(849070),
+		},
+		{
+			name: "base address, one asset",
+			txOut: NewTxOutput(baseAddr, NewValueWithAssets(
+				0,
+				NewMultiAsset().Set(policyID, NewAssets().Set(NewAssetName("cardanogo"), 1)),
+			)),
+			minUTXO: Coin(1159390),
+		},
+		{
+			name:    "enterprise address, datum hash",
+			txOut:   &TxOutput{Address: enterpriseAddr, Amount: NewValue(0), DatumHash: datum.Hash()},
+			minUTXO: Coin(995610),
+		},
+		{
+			name:    "enterprise address, inline datum",
+			txOut:   &TxOutput{Address: enterpriseAddr, Amount: NewValue(0), Datum: datum},
+			minUTXO: Coin(896480),
+		},
+		{
+			name: "enterprise address, reference script",
+			txOut: &TxOutput{
+				Address:   enterpriseAddr,
+				Amount:    NewValue(0),
+				ScriptRef: NewNativeScriptRef(policyScript),
+			},
+			minUTXO: Coin(1025780),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			txBuilder := NewTxBuilder(babbageProtocol)
+
+			got := txBuilder.MinCoinsForTxOut(tc.txOut)
+			if got != tc.minUTXO {
+				t.Errorf("invalid minUTXO\ngot: %d\nwant: %d", got, tc.minUTXO)
+			}
+
+			// The minimum must hold once the coin is set in the output
+			txOut := *tc.txOut
+			txOut.Amount = NewValueWithAssets(got, tc.txOut.Amount.MultiAsset)
+			if size := Coin(len(txOut.Bytes())); (160+size)*babbageProtocol.CoinsPerUTXOByte != got {
+				t.Errorf("minUTXO does not match output size %d", size)
+			}
+		})
+	}
+}
+
 func TestSimpleTx(t *testing.T) {
 	testcases := []struct {
 		name     string
@@ -593,3 +690,376 @@ func TestAddChangeIfNeeded(t *testing.T) {
 		})
 	}
 }
+
+func TestMinFeeWithoutKeys(t *testing.T) {
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	policyKey := crypto.NewXPrvKeyFromEntropy([]byte("policy"), "")
+	payment, err := NewKeyCredential(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := NewEnterpriseAddress(Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyScript, err := NewScriptPubKey(policyKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyID, err := NewPolicyID(policyScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAsset := NewMint().Set(policyID, NewMintAssets().Set(NewAssetName("cardanogo"), big.NewInt(1e9)))
+
+	newBuilder := func() *TxBuilder {
+		txBuilder := NewTxBuilder(babbageProtocol)
+		txBuilder.AddInputs(
+			&TxInput{TxHash: txHash, Index: 0, Amount: NewValue(50e6), Spender: &sender},
+			&TxInput{TxHash: txHash, Index: 1, Amount: NewValue(50e6), Spender: &sender},
+		)
+		txBuilder.AddOutputs(NewTxOutput(receiver, NewValueWithAssets(10e6, newAsset.MultiAsset())))
+		txBuilder.Mint(newAsset)
+		txBuilder.AddNativeScript(policyScript)
+		txBuilder.SetTTL(100000)
+		txBuilder.AddChangeIfNeeded(sender)
+		return txBuilder
+	}
+
+	unsignedBuilder := newBuilder()
+	unsignedTx, err := unsignedBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(unsignedTx.WitnessSet.VKeyWitnessSet); got != 0 {
+		t.Errorf("unexpected witnesses in unsigned tx: got %v", got)
+	}
+
+	signedBuilder := newBuilder()
+	signedBuilder.Sign(paymentKey.PrvKey(), policyKey.PrvKey())
+	signedTx, err := signedBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(signedTx.WitnessSet.VKeyWitnessSet); got != 2 {
+		t.Errorf("invalid number of witnesses: got %v want 2", got)
+	}
+
+	if got, want := unsignedTx.Body.Fee, signedTx.Body.Fee; got != want {
+		t.Errorf("invalid unsigned tx fee:\ngot: %v\nwant: %v", got, want)
+	}
+	wantFee := babbageProtocol.MinFeeA*Coin(len(signedTx.Bytes())) + babbageProtocol.MinFeeB
+	if got := signedTx.Body.Fee; got != wantFee {
+		t.Errorf("invalid signed tx fee:\ngot: %v\nwant: %v", got, wantFee)
+	}
+}
+
+var conwayProtocol = &ProtocolParams{
+	CoinsPerUTXOByte: 4310,
+	MinFeeA:          44,
+	MinFeeB:          155381,
+	ExecutionCosts: ExUnitPrices{
+		MemPrice:  Rational{P: 577, Q: 10000},
+		StepPrice: Rational{P: 721, Q: 10000000},
+	},
+	MinFeeRefScriptCostPerByte: Rational{P: 15, Q: 1},
+	ProtocolVersion:            ProtocolVersion{Major: 9},
+}
+
+func TestRefScriptsFee(t *testing.T) {
+	testcases := []struct {
+		name string
+		size uint
+		want Coin
+	}{
+		{name: "Empty", size: 0, want: 0},
+		{name: "FirstTier", size: 100, want: 1500},
+		{name: "FullFirstTier", size: 25600, want: 384000},
+		{name: "SecondTier", size: 25601, want: 384018},
+		{name: "FullSecondTier", size: 51200, want: 844800},
+		{name: "ThirdTier", size: 51205, want: 844908},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := refScriptsFee(conwayProtocol.MinFeeRefScriptCostPerByte, tc.size)
+			if got != tc.want {
+				t.Errorf("invalid reference scripts fee: got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScriptFee(t *testing.T) {
+	testcases := []struct {
+		name    string
+		exUnits ExUnits
+		want    Coin
+	}{
+		{name: "Empty", exUnits: ExUnits{}, want: 0},
+		{name: "Exact", exUnits: ExUnits{Mem: 1000000, Steps: 500000000}, want: 93750},
+		{name: "RoundedUp", exUnits: ExUnits{Mem: 1000001, Steps: 500000000}, want: 93751},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := scriptFee(conwayProtocol.ExecutionCosts, tc.exUnits)
+			if got != tc.want {
+				t.Errorf("invalid script fee: got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMinFeeConway(t *testing.T) {
+	sender, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := hex.DecodeString(strings.Repeat("00", 30000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptRef := NewPlutusScriptRef(PlutusV2ScriptNamespace, script)
+	redeemer := Redeemer{
+		Tag:     RedeemerTagSpend,
+		Data:    PlutusData{0xd8, 0x79, 0x80},
+		ExUnits: ExUnits{Mem: 1000000, Steps: 500000000},
+	}
+
+	minFee := func(withScriptRef, withRedeemer bool) Coin {
+		t.Helper()
+		txBuilder := NewTxBuilder(conwayProtocol)
+		spent := &TxInput{TxHash: txHash, Index: 0, Amount: NewValue(50e6), Spender: &sender}
+		referenced := &TxInput{TxHash: txHash, Index: 1, Amount: NewValue(10e6)}
+		if withScriptRef {
+			spent.ScriptRef = scriptRef
+			referenced.ScriptRef = scriptRef
+		}
+		txBuilder.AddInputs(spent)
+		txBuilder.AddReferenceInputs(referenced, spent)
+		if withRedeemer {
+			txBuilder.AddRedeemers(redeemer)
+		} else {
+			txBuilder.AddRedeemers(Redeemer{Tag: RedeemerTagSpend, Data: redeemer.Data})
+		}
+		txBuilder.AddOutputs(NewTxOutput(sender, NewValue(10e6)))
+		fee, err := txBuilder.MinFee()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fee
+	}
+
+	base := minFee(false, false)
+	// the script is held by two distinct inputs, the spent input also referenced is counted once
+	if got, want := minFee(true, false)-base, refScriptsFee(conwayProtocol.MinFeeRefScriptCostPerByte, 2*uint(len(script))); got != want {
+		t.Errorf("invalid reference scripts fee: got %v want %v", got, want)
+	}
+	// the execution units are encoded on 8 more bytes than the zero execution units
+	if got, want := minFee(false, true)-base, 93750+8*conwayProtocol.MinFeeA; got != want {
+		t.Errorf("invalid script fee: got %v want %v", got, want)
+	}
+}
+
+func TestChangeSplitting(t *testing.T) {
+	sender, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nfts := NewMultiAsset()
+	for p := 0; p < 3; p++ {
+		policyID := NewPolicyIDFromHash(bytes.Repeat([]byte{byte(p + 1)}, 28))
+		assets := NewAssets()
+		for i := 0; i < 100; i++ {
+			assets.Set(NewAssetName(fmt.Sprintf("nft%03d", i)), 1)
+		}
+		nfts.Set(policyID, assets)
+	}
+	testcases := []struct {
+		name         string
+		coins        Coin
+		maxValueSize uint
+		grouped      bool
+		wantOutputs  int
+		wantErr      bool
+	}{
+		{name: "SingleOutput", coins: 100e6, maxValueSize: 0, wantOutputs: 1},
+		{name: "Split", coins: 100e6, maxValueSize: 500, wantOutputs: 6},
+		{name: "GroupedByPolicy", coins: 100e6, maxValueSize: 5000, grouped: true, wantOutputs: 3},
+		{name: "GroupedByPolicyAndSplit", coins: 100e6, maxValueSize: 500, grouped: true, wantOutputs: 6},
+		{name: "InsufficientMinCoins", coins: 10e6, maxValueSize: 500, wantErr: true},
+		{name: "AssetTooLarge", coins: 100e6, maxValueSize: 40, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			protocol := *babbageProtocol
+			protocol.MaxValueSize = tc.maxValueSize
+			txBuilder := NewTxBuilder(&protocol)
+			txBuilder.AddInputs(&TxInput{TxHash: txHash, Index: 0, Amount: NewValueWithAssets(tc.coins, nfts), Spender: &sender})
+			txBuilder.AddOutputs(NewTxOutput(sender, NewValue(5e6)))
+			txBuilder.AddChangeIfNeeded(sender)
+			txBuilder.SetChangeGroupedByPolicy(tc.grouped)
+
+			tx, err := txBuilder.Build()
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected error")
+			}
+
+			changeOutputs := tx.Body.Outputs[:len(tx.Body.Outputs)-1]
+			if got := len(changeOutputs); got != tc.wantOutputs {
+				t.Errorf("invalid number of change outputs: got %v want %v", got, tc.wantOutputs)
+			}
+			total := NewValue(tx.Body.Fee + 5e6)
+			for _, out := range changeOutputs {
+				total = total.Add(out.Amount)
+				if minCoins := MinCoinsForTxOut(&protocol, out); out.Amount.Coin < minCoins {
+					t.Errorf("change output below min coins: got %v want %v", out.Amount.Coin, minCoins)
+				}
+				if !txBuilder.fitsMaxValueSize(out.Amount) {
+					t.Errorf("change output exceeds max value size %v", tc.maxValueSize)
+				}
+				if tc.grouped && len(out.Amount.MultiAsset.Keys()) != 1 {
+					t.Errorf("change output holds %v policies, want 1", len(out.Amount.MultiAsset.Keys()))
+				}
+			}
+			if got, want := total, NewValueWithAssets(tc.coins, nfts); got.Cmp(want) != 0 {
+				t.Errorf("invalid value conservation: got %v want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	receiver, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainnetReceiver, err := NewEnterpriseAddress(Mainnet, receiver.Payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name    string
+		setup   func(protocol *ProtocolParams, txBuilder *TxBuilder)
+		wantErr any
+	}{
+		{
+			name:  "Valid",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {},
+		},
+		{
+			name: "TxSize",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				protocol.MaxTxSize = 100
+			},
+			wantErr: new(*TxSizeError),
+		},
+		{
+			name: "CollateralInputs",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				protocol.MaxCollateralInputs = 1
+				txBuilder.tx.Body.Collateral = []TxInput{
+					{TxHash: txHash, Index: 2},
+					{TxHash: txHash, Index: 3},
+				}
+			},
+			wantErr: new(*CollateralInputsError),
+		},
+		{
+			name: "ExUnits",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				protocol.MaxTxExUnits = ExUnits{Mem: 1000, Steps: 1000}
+				txBuilder.AddRedeemers(Redeemer{Data: PlutusData{0xd8, 0x79, 0x80}, ExUnits: ExUnits{Mem: 10, Steps: 1001}})
+			},
+			wantErr: new(*ExUnitsError),
+		},
+		{
+			name: "OutputMinCoins",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				txBuilder.AddOutputs(NewTxOutput(receiver, NewValue(1e5)))
+			},
+			wantErr: new(*OutputMinCoinsError),
+		},
+		{
+			name: "OutputValueSize",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				protocol.MaxValueSize = 4
+			},
+			wantErr: new(*OutputValueSizeError),
+		},
+		{
+			name: "ValidityInterval",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				txBuilder.SetValidityIntervalStart(100)
+				txBuilder.SetTTL(100)
+			},
+			wantErr: new(*ValidityIntervalError),
+		},
+		{
+			name: "NetworkIDOutputs",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				txBuilder.AddOutputs(NewTxOutput(mainnetReceiver, NewValue(5e6)))
+			},
+			wantErr: new(*NetworkIDError),
+		},
+		{
+			name: "NetworkIDBody",
+			setup: func(protocol *ProtocolParams, txBuilder *TxBuilder) {
+				txBuilder.SetNetworkID(Mainnet)
+			},
+			wantErr: new(*NetworkIDError),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			protocol := *babbageProtocol
+			txBuilder := NewTxBuilder(&protocol)
+			txBuilder.AddInputs(&TxInput{TxHash: txHash, Index: 0, Amount: NewValue(20e6), Spender: &receiver})
+			txBuilder.AddOutputs(NewTxOutput(receiver, NewValue(10e6)))
+			txBuilder.AddChangeIfNeeded(receiver)
+			tc.setup(&protocol, txBuilder)
+
+			_, err := txBuilder.Build()
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.As(err, tc.wantErr) {
+				t.Errorf("invalid error: got %v want %T", err, tc.wantErr)
+			}
+		})
+	}
+}
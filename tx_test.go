@@ -1,6 +1,7 @@
 package cardano
 
 import (
+	"bytes"
 	"encoding/hex"
 	"math/big"
 	"reflect"
@@ -88,9 +89,17 @@ func TestTxEncoding(t *testing.T) {
 		txInput.Amount = nil
 	}
 
+	// the decoded body keeps its original encoding
+	if !bytes.Equal(gotTx.Bytes(), txBytes) {
+		t.Errorf("invalid tx re-encoding:\ngot: %x\nwant: %x", gotTx.Bytes(), txBytes)
+	}
+	gotTx.Body.raw, gotTx.Body.encoded = nil, nil
+	gotTx.WitnessSet.raw, gotTx.WitnessSet.rawFields, gotTx.WitnessSet.encodedFields = nil, nil, nil
+	gotTx.AuxiliaryData.raw, gotTx.AuxiliaryData.encoded = nil, nil
+
 	if diff := cmp.Diff(
 		wantTx, gotTx,
-		cmpopts.IgnoreUnexported(MultiAsset{}, Mint{}),
+		cmpopts.IgnoreUnexported(MultiAsset{}, Mint{}, TxBody{}, WitnessSet{}, AuxiliaryData{}),
 	); diff != "" {
 		t.Error(diff)
 	}
@@ -373,3 +382,215 @@ func TestRelayEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestTxOutputEncoding(t *testing.T) {
+	addr, err := NewAddress("addr_test1vp9uhllavnhwc6m6422szvrtq3eerhleer4eyu00rmx8u6c42z3v8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	datum, err := NewPlutusData("d87980")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := NewPlutusScript("4e4d01000033222220051200120011")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name    string
+		cborHex string
+		output  TxOutput
+	}{
+		{
+			name:    "Legacy",
+			cborHex: "82581d604bcbfffd64eeec6b7aaa9501306b047391dff9c8eb9271ef1ecc7e6b1a000f4240",
+			output:  TxOutput{Address: addr, Amount: NewValue(1e6)},
+		},
+		{
+			name:    "LegacyWithDatumHash",
+			cborHex: "83581d604bcbfffd64eeec6b7aaa9501306b047391dff9c8eb9271ef1ecc7e6b1a000f42405820923918e403bf43c34b4ef6b48eb2ee04babed17320d8d1b9ff9ad086e86f44ec",
+			output:  TxOutput{Address: addr, Amount: NewValue(1e6), DatumHash: datum.Hash()},
+		},
+		{
+			name:    "InlineDatum",
+			cborHex: "a300581d604bcbfffd64eeec6b7aaa9501306b047391dff9c8eb9271ef1ecc7e6b011a000f4240028201d81843d87980",
+			output:  TxOutput{Address: addr, Amount: NewValue(1e6), Datum: datum},
+		},
+		{
+			name:    "ReferenceScript",
+			cborHex: "a300581d604bcbfffd64eeec6b7aaa9501306b047391dff9c8eb9271ef1ecc7e6b011a000f424003d8185182024e4d01000033222220051200120011",
+			output: TxOutput{
+				Address:   addr,
+				Amount:    NewValue(1e6),
+				ScriptRef: NewPlutusScriptRef(PlutusV2ScriptNamespace, script),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.cborHex)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out TxOutput
+			if err := cbor.Unmarshal(data, &out); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.output, out, cmpopts.IgnoreUnexported(MultiAsset{})); diff != "" {
+				t.Error(diff)
+			}
+
+			rb, err := cbor.Marshal(&tc.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(rb, data) {
+				t.Errorf("got: %x\nwant: %x", rb, data)
+			}
+		})
+	}
+}
+
+func TestRedeemersDecoding(t *testing.T) {
+	want := Redeemers{{Tag: RedeemerTagSpend, Index: 0, Data: PlutusData{0xd8, 0x79, 0x80}, ExUnits: ExUnits{Mem: 1, Steps: 2}}}
+	added := Redeemer{Tag: RedeemerTagMint, Index: 0, Data: PlutusData{0xd8, 0x79, 0x80}, ExUnits: ExUnits{Mem: 3, Steps: 4}}
+	testcases := []struct {
+		name        string
+		cborHex     string
+		modifiedHex string
+	}{
+		{
+			name:        "Array",
+			cborHex:     "81840000d87980820102",
+			modifiedHex: "82840000d87980820102840100d87980820304",
+		},
+		{
+			name:        "Map",
+			cborHex:     "a182000082d87980820102",
+			modifiedHex: "a282000082d8798082010282010082d87980820304",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.cborHex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Redeemers
+			if err := cborDec.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("invalid redeemers: got %+v want %+v", got, want)
+			}
+
+			// The redeemers of a witness set are encoded in their format.
+			var ws WitnessSet
+			if err := cborDec.Unmarshal(append([]byte{0xa1, 0x05}, data...), &ws); err != nil {
+				t.Fatal(err)
+			}
+			ws.Redeemers = append(ws.Redeemers, added)
+			encoded, err := cborEnc.Marshal(&ws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotHex, wantHex := hex.EncodeToString(encoded), "a105"+tc.modifiedHex; gotHex != wantHex {
+				t.Errorf("invalid encoding:\ngot: %v\nwant: %v", gotHex, wantHex)
+			}
+		})
+	}
+}
+
+func TestWitnessSetEncoding(t *testing.T) {
+	testcases := []struct {
+		name    string
+		cborHex string
+	}{
+		{
+			name:    "MapRedeemers",
+			cborHex: "a105a18200008200820102",
+		},
+		{
+			name:    "TaggedPlutusData",
+			cborHex: "a104d901028100",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.cborHex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ws WitnessSet
+			if err := cborDec.Unmarshal(data, &ws); err != nil {
+				t.Fatal(err)
+			}
+			got, err := cborEnc.Marshal(&ws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotHex := hex.EncodeToString(got); gotHex != tc.cborHex {
+				t.Errorf("invalid encoding:\ngot: %v\nwant: %v", gotHex, tc.cborHex)
+			}
+		})
+	}
+}
+
+func TestAuxiliaryDataEncoding(t *testing.T) {
+	testcases := []struct {
+		name    string
+		cborHex string
+	}{
+		{
+			name:    "Shelley",
+			cborHex: "a11902a2626869",
+		},
+		{
+			name:    "Allegra",
+			cborHex: "82a11902a262686980",
+		},
+		{
+			name:    "Alonzo",
+			cborHex: "d90103a100a11902a2626869",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.cborHex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var aux AuxiliaryData
+			if err := aux.UnmarshalCBOR(data); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := aux.Metadata[674], "hi"; got != want {
+				t.Errorf("invalid metadata: got %v want %v", got, want)
+			}
+			got, err := cborEnc.Marshal(&aux)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotHex := hex.EncodeToString(got); gotHex != tc.cborHex {
+				t.Errorf("invalid encoding:\ngot: %v\nwant: %v", gotHex, tc.cborHex)
+			}
+
+			aux.Metadata[674] = "hello"
+			got, err = cborEnc.Marshal(&aux)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "d90103a100a11902a26568656c6c6f"; hex.EncodeToString(got) != want {
+				t.Errorf("invalid encoding of modified data:\ngot: %x\nwant: %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package cardano
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TxSizeError is returned when the transaction exceeds the protocol MaxTxSize.
+type TxSizeError struct {
+	Size    uint
+	MaxSize uint
+}
+
+func (e *TxSizeError) Error() string {
+	return fmt.Sprintf("transaction size %v exceeds max tx size %v", e.Size, e.MaxSize)
+}
+
+// CollateralInputsError is returned when the transaction has more collateral inputs
+// than the protocol MaxCollateralInputs.
+type CollateralInputsError struct {
+	Count uint
+	Max   uint
+}
+
+func (e *CollateralInputsError) Error() string {
+	return fmt.Sprintf("%v collateral inputs exceed max collateral inputs %v", e.Count, e.Max)
+}
+
+// ExUnitsError is returned when the execution units of the transaction exceed
+// the protocol MaxTxExUnits.
+type ExUnitsError struct {
+	ExUnits ExUnits
+	Max     ExUnits
+}
+
+func (e *ExUnitsError) Error() string {
+	return fmt.Sprintf(
+		"execution units (mem %v, steps %v) exceed max tx execution units (mem %v, steps %v)",
+		e.ExUnits.Mem, e.ExUnits.Steps, e.Max.Mem, e.Max.Steps,
+	)
+}
+
+// OutputMinCoinsError is returned when an output holds less coins than required.
+type OutputMinCoinsError struct {
+	Index    int
+	Coin     Coin
+	MinCoins Coin
+}
+
+func (e *OutputMinCoinsError) Error() string {
+	return fmt.Sprintf("output %v holds %v coins, want at least %v", e.Index, e.Coin, e.MinCoins)
+}
+
+// OutputValueSizeError is returned when the value of an output exceeds the
+// protocol MaxValueSize.
+type OutputValueSizeError struct {
+	Index   int
+	Size    uint
+	MaxSize uint
+}
+
+func (e *OutputValueSizeError) Error() string {
+	return fmt.Sprintf("output %v value size %v exceeds max value size %v", e.Index, e.Size, e.MaxSize)
+}
+
+// ValidityIntervalError is returned when the TTL of the transaction is not after
+// its validity interval start.
+type ValidityIntervalError struct {
+	ValidityStart uint64
+	TTL           uint64
+}
+
+func (e *ValidityIntervalError) Error() string {
+	return fmt.Sprintf("ttl %v is not after validity interval start %v", e.TTL, e.ValidityStart)
+}
+
+// NetworkIDError is returned when the network ID of an output address does not
+// match the network ID of the transaction.
+type NetworkIDError struct {
+	Index     int
+	Address   Address
+	NetworkID uint64
+}
+
+func (e *NetworkIDError) Error() string {
+	return fmt.Sprintf(
+		"output %v address %v does not belong to network id %v",
+		e.Index, e.Address, e.NetworkID,
+	)
+}
+
+// Validate checks the transaction against the protocol limits, the outputs min coins,
+// the validity interval and the network ID of the output addresses.
+// Limits set to zero in the protocol parameters are not checked.
+// All the violations are returned joined, each of them can be inspected using errors.As.
+func (tb *TxBuilder) Validate() error {
+	var errs []error
+
+	fakeTx, err := tb.fakeTx()
+	if err != nil {
+		return err
+	}
+	if size := uint(len(fakeTx.Bytes())); tb.protocol.MaxTxSize != 0 && size > tb.protocol.MaxTxSize {
+		errs = append(errs, &TxSizeError{Size: size, MaxSize: tb.protocol.MaxTxSize})
+	}
+
+	body := &tb.tx.Body
+	if count := uint(len(body.Collateral)); tb.protocol.MaxCollateralInputs != 0 && count > tb.protocol.MaxCollateralInputs {
+		errs = append(errs, &CollateralInputsError{Count: count, Max: tb.protocol.MaxCollateralInputs})
+	}
+
+	maxExUnits := tb.protocol.MaxTxExUnits
+	exUnits := tb.tx.WitnessSet.Redeemers.ExUnits()
+	if maxExUnits != (ExUnits{}) && (exUnits.Mem > maxExUnits.Mem || exUnits.Steps > maxExUnits.Steps) {
+		errs = append(errs, &ExUnitsError{ExUnits: exUnits, Max: maxExUnits})
+	}
+
+	for i, out := range body.Outputs {
+		if minCoins := tb.MinCoinsForTxOut(out); out.Amount.Coin < minCoins {
+			errs = append(errs, &OutputMinCoinsError{Index: i, Coin: out.Amount.Coin, MinCoins: minCoins})
+		}
+		if tb.protocol.MaxValueSize != 0 && !tb.fitsMaxValueSize(out.Amount) {
+			valueBytes, err := cborEnc.Marshal(out.Amount)
+			if err != nil {
+				return err
+			}
+			errs = append(errs, &OutputValueSizeError{Index: i, Size: uint(len(valueBytes)), MaxSize: tb.protocol.MaxValueSize})
+		}
+	}
+
+	if body.TTL != nil && body.ValidityIntervalStart != nil && *body.TTL <= *body.ValidityIntervalStart {
+		errs = append(errs, &ValidityIntervalError{ValidityStart: *body.ValidityIntervalStart, TTL: *body.TTL})
+	}
+
+	var networkID *uint64 = body.NetworkID
+	for i, out := range body.Outputs {
+		outNetworkID := uint64(out.Address.Network.ID())
+		if networkID == nil {
+			networkID = &outNetworkID
+			continue
+		}
+		if outNetworkID != *networkID {
+			errs = append(errs, &NetworkIDError{Index: i, Address: out.Address, NetworkID: *networkID})
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -36,6 +36,7 @@ func (c *Client) CreateWallet(name, password string) (*Wallet, string, error) {
 	wallet := newWallet(name, password, entropy)
 	wallet.node = c.opts.Node
 	wallet.network = c.network
+	wallet.signers = c.opts.Signers
 	err := c.opts.DB.Put(wallet)
 	if err != nil {
 		return nil, "", err
@@ -43,7 +44,8 @@ func (c *Client) CreateWallet(name, password string) (*Wallet, string, error) {
 	return wallet, mnemonic, nil
 }
 
-// RestoreWallet restores a Wallet from a mnemonic and password.
+// RestoreWallet restores a Wallet from a mnemonic and password, discovering
+// its used addresses if the gap limit of the options is set.
 func (c *Client) RestoreWallet(name, password, mnemonic string) (*Wallet, error) {
 	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
 	if err != nil {
@@ -52,6 +54,12 @@ func (c *Client) RestoreWallet(name, password, mnemonic string) (*Wallet, error)
 	wallet := newWallet(name, password, entropy)
 	wallet.node = c.opts.Node
 	wallet.network = c.network
+	wallet.signers = c.opts.Signers
+	if c.opts.GapLimit > 0 {
+		if err := wallet.DiscoverAddresses(c.opts.GapLimit); err != nil {
+			return nil, err
+		}
+	}
 	if err = c.opts.DB.Put(wallet); err != nil {
 		return nil, err
 	}
@@ -72,6 +80,7 @@ func (c *Client) Wallets() ([]*Wallet, error) {
 	}
 	for i := range wallets {
 		wallets[i].node = c.opts.Node
+		wallets[i].signers = c.opts.Signers
 	}
 	return wallets, nil
 }
@@ -0,0 +1,92 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+// DefaultGapLimit is the number of consecutive unused addresses ending the
+// discovery of a chain, as recommended by BIP-44.
+const DefaultGapLimit = 20
+
+// DiscoverAddresses scans the external and internal chains of the wallet for
+// used addresses, until gapLimit consecutive addresses are unused, and adds the
+// keys up to the last address used. An address is used if its base or
+// enterprise address is in a transaction, read from the node if it supports
+// cardano.CapabilityAddressHistory. Otherwise an address is used if the node
+// has unspent outputs at it, and the funds of the addresses found after
+// gapLimit emptied addresses are missed.
+//
+// The internal chain is only scanned for the wallets created or restored since
+// its key is stored. The wallet must be saved to persist the keys discovered.
+func (w *Wallet) DiscoverAddresses(gapLimit int) error {
+	if gapLimit <= 0 {
+		return errors.New("gap limit must be positive")
+	}
+	ctx := context.Background()
+
+	addrKeys, err := w.discoverChain(ctx, w.rootKey, w.addrKeys, gapLimit)
+	if err != nil {
+		return err
+	}
+	w.addrKeys = addrKeys
+
+	if w.changeKey == nil {
+		return nil
+	}
+	changeKeys, err := w.discoverChain(ctx, w.changeKey, w.changeKeys, gapLimit)
+	if err != nil {
+		return err
+	}
+	w.changeKeys = changeKeys
+	return nil
+}
+
+// discoverChain returns the keys of a chain up to its last used address,
+// starting with the keys known.
+func (w *Wallet) discoverChain(ctx context.Context, chainKey crypto.XPrvKey, known []crypto.XPrvKey, gapLimit int) ([]crypto.XPrvKey, error) {
+	keys := known
+	var unused []crypto.XPrvKey
+	for index := len(known); len(unused) < gapLimit; index++ {
+		key := chainKey.Derive(uint32(index))
+		used, err := w.keyUsed(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		unused = append(unused, key)
+		if used {
+			keys = append(keys, unused...)
+			unused = nil
+		}
+	}
+	return keys, nil
+}
+
+// keyUsed reports whether the base or enterprise address of a payment key is
+// used.
+func (w *Wallet) keyUsed(ctx context.Context, key crypto.XPrvKey) (bool, error) {
+	if !cardano.Supports(w.node, cardano.CapabilityAddressHistory) {
+		utxos, err := w.keyUtxos(ctx, key)
+		return len(utxos) > 0, err
+	}
+
+	baseAddr, err := w.address(key)
+	if err != nil {
+		return false, err
+	}
+	enterpriseAddr, err := w.enterpriseAddress(key)
+	if err != nil {
+		return false, err
+	}
+	node := w.node.(cardano.AddressHistoryNode)
+	for _, addr := range []cardano.Address{baseAddr, enterpriseAddr} {
+		used, err := node.AddressUsed(ctx, addr)
+		if err != nil || used {
+			return used, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,164 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/crypto"
+	"github.com/cryptogarageinc/cardano-go/emulator"
+)
+
+func TestDiscoverAddresses(t *testing.T) {
+	node := emulator.NewNode(cardano.Testnet, &cardano.ProtocolParams{CoinsPerUTXOByte: 4310})
+	mnemonic := testVectors[0].mnemonic
+
+	// the addresses of a wallet used by another client
+	other := NewClient(&Options{Node: node})
+	defer other.Close()
+	w, err := other.RestoreWallet("other", "", mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w.addrKeys) != 1 || len(w.changeKeys) != 0 {
+		t.Fatalf("unexpected discovery: got %v external and %v internal keys", len(w.addrKeys), len(w.changeKeys))
+	}
+	fund := func(chainKey crypto.XPrvKey, index uint32, enterprise bool, coin cardano.Coin) {
+		key := chainKey.Derive(index)
+		addr, err := w.address(key)
+		if enterprise {
+			addr, err = w.enterpriseAddress(key)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.AddUTxO(addr, cardano.NewValue(coin))
+	}
+	fund(w.rootKey, 3, false, 1e6)
+	fund(w.rootKey, 10, true, 2e6)
+	fund(w.rootKey, 31, false, 4e6) // beyond the gap limit
+	fund(w.changeKey, 5, false, 8e6)
+
+	client := NewClient(&Options{Node: node, GapLimit: DefaultGapLimit})
+	defer client.Close()
+	restored, err := client.RestoreWallet("restored", "", mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(restored.addrKeys), 11; got != want {
+		t.Errorf("invalid external keys: got %v want %v", got, want)
+	}
+	if got, want := len(restored.changeKeys), 6; got != want {
+		t.Errorf("invalid internal keys: got %v want %v", got, want)
+	}
+	balance, err := restored.Balance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cardano.NewValue(11e6); balance.Cmp(want) != 0 {
+		t.Errorf("invalid balance:\ngot: %v\nwant: %v", balance, want)
+	}
+
+	// the keys discovered are persisted
+	data, err := restored.marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Wallet{node: node}
+	if err := loaded.unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.addrKeys) != len(restored.addrKeys) || len(loaded.changeKeys) != len(restored.changeKeys) {
+		t.Errorf("invalid keys loaded: got %v external and %v internal keys", len(loaded.addrKeys), len(loaded.changeKeys))
+	}
+
+	// a later discovery resumes after the keys known
+	fund(w.rootKey, 20, false, 16e6)
+	if err := loaded.DiscoverAddresses(DefaultGapLimit); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(loaded.addrKeys), 32; got != want {
+		t.Errorf("invalid external keys after rediscovery: got %v want %v", got, want)
+	}
+}
+
+func TestDiscoverAddressesEarlierDump(t *testing.T) {
+	node := emulator.NewNode(cardano.Testnet, &cardano.ProtocolParams{CoinsPerUTXOByte: 4310})
+	w := newWallet("test", "", []byte("0123456789abcdef"))
+	w.node = node
+	w.network = cardano.Testnet
+	changeAddr, err := w.address(w.changeKey.Derive(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.AddUTxO(changeAddr, cardano.NewValue(1e6))
+
+	// the internal chain key is missing from the earlier dumps
+	w.changeKey = nil
+	if err := w.DiscoverAddresses(5); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.addrKeys) != 1 || len(w.changeKeys) != 0 {
+		t.Errorf("invalid keys: got %v external and %v internal keys", len(w.addrKeys), len(w.changeKeys))
+	}
+
+	if err := w.DiscoverAddresses(0); err == nil {
+		t.Error("unexpected success of a zero gap limit")
+	}
+}
+
+// historyNode is a MockNode knowing the addresses used.
+type historyNode struct {
+	*MockNode
+	used map[string]bool
+}
+
+func (n *historyNode) AddressUsed(_ context.Context, addr cardano.Address) (bool, error) {
+	return n.used[addr.Bech32()], nil
+}
+
+func TestDiscoverAddressesHistory(t *testing.T) {
+	w := newWallet("test", "", []byte("0123456789abcdef"))
+	w.network = cardano.Testnet
+	mock := &MockNode{}
+	history := &historyNode{MockNode: mock, used: map[string]bool{}}
+
+	// the first addresses were used and emptied, beyond the gap limit
+	for index := uint32(0); index < 25; index++ {
+		addr, err := w.address(w.rootKey.Derive(index))
+		if err != nil {
+			t.Fatal(err)
+		}
+		history.used[addr.Bech32()] = true
+	}
+	addr, err := w.address(w.rootKey.Derive(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.used[addr.Bech32()] = true
+	mock.utxos = append(mock.utxos, cardano.UTxO{Spender: addr, Amount: cardano.NewValue(1e6)})
+
+	// the unspent outputs are not enough to find the funds
+	w.node = mock
+	if err := w.DiscoverAddresses(DefaultGapLimit); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(w.addrKeys), 1; got != want {
+		t.Errorf("invalid external keys without history: got %v want %v", got, want)
+	}
+
+	w.node = history
+	if err := w.DiscoverAddresses(DefaultGapLimit); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(w.addrKeys), 31; got != want {
+		t.Errorf("invalid external keys: got %v want %v", got, want)
+	}
+	balance, err := w.Balance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cardano.NewValue(1e6); balance.Cmp(want) != 0 {
+		t.Errorf("invalid balance:\ngot: %v\nwant: %v", balance, want)
+	}
+}
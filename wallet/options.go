@@ -8,11 +8,20 @@ import (
 type Options struct {
 	Node cardano.Node
 	DB   DB
+
+	// Signers sign the wallets transactions instead of the wallet keys with the
+	// same key hash, e.g. to keep the signing keys in a remote signer.
+	Signers []cardano.Signer
+
+	// GapLimit is the gap limit of the discovery of the addresses of the
+	// restored wallets, e.g. DefaultGapLimit. The addresses are not discovered
+	// if zero, see Wallet.DiscoverAddresses.
+	GapLimit int
 }
 
 func (o *Options) init() {
 	if o.Node == nil {
-		o.Node = cardanocli.NewNode(cardano.Testnet)
+		o.Node = cardanocli.NewNode(cardano.Testnet, nil)
 	}
 	if o.DB == nil {
 		o.DB = newMemoryDB()
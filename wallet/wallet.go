@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -18,13 +19,16 @@ const (
 )
 
 type Wallet struct {
-	ID       string
-	Name     string
-	addrKeys []crypto.XPrvKey
-	stakeKey crypto.XPrvKey
-	rootKey  crypto.XPrvKey
-	node     cardano.Node
-	network  cardano.Network
+	ID         string
+	Name       string
+	addrKeys   []crypto.XPrvKey
+	changeKeys []crypto.XPrvKey // internal chain keys, discovered only
+	stakeKey   crypto.XPrvKey
+	rootKey    crypto.XPrvKey // external chain key
+	changeKey  crypto.XPrvKey // internal chain key, nil in the earlier dumps
+	node       cardano.Node
+	network    cardano.Network
+	signers    []cardano.Signer
 }
 
 // Transfer sends an amount of lovelace to the receiver address and returns the transaction hash
@@ -63,18 +67,12 @@ func (w *Wallet) Transfer(receiver cardano.Address, amount *cardano.Value) (*car
 
 	keys := make(map[int]crypto.XPrvKey)
 	for i, utxo := range pickedUtxos {
-		for _, key := range w.addrKeys {
-			payment, err := cardano.NewKeyCredential(key.PubKey())
-			if err != nil {
-				return nil, err
-			}
-			addr, err := cardano.NewEnterpriseAddress(w.network, payment)
-			if err != nil {
-				return nil, err
-			}
-			if addr.Bech32() == utxo.Spender.Bech32() {
-				keys[i] = key
-			}
+		key, ok, err := w.paymentKey(utxo.Spender)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			keys[i] = key
 		}
 	}
 
@@ -84,7 +82,8 @@ func (w *Wallet) Transfer(receiver cardano.Address, amount *cardano.Value) (*car
 
 	inputAmount := cardano.NewValue(0)
 	for _, utxo := range pickedUtxos {
-		txBuilder.AddInputs(&cardano.TxInput{TxHash: utxo.TxHash, Index: utxo.Index, Amount: utxo.Amount})
+		spender := utxo.Spender
+		txBuilder.AddInputs(&cardano.TxInput{TxHash: utxo.TxHash, Index: utxo.Index, Amount: utxo.Amount, Spender: &spender})
 		inputAmount = inputAmount.Add(utxo.Amount)
 	}
 	txBuilder.AddOutputs(&cardano.TxOutput{Address: receiver, Amount: amount})
@@ -94,10 +93,23 @@ func (w *Wallet) Transfer(receiver cardano.Address, amount *cardano.Value) (*car
 		return nil, err
 	}
 	txBuilder.SetTTL(tip.Slot + 1200)
+	// a key spending several outputs signs once
+	signed := make(map[string]bool)
 	for _, key := range keys {
-		txBuilder.Sign(key.PrvKey())
+		if signed[string(key)] {
+			continue
+		}
+		signed[string(key)] = true
+		signer, err := w.signer(key)
+		if err != nil {
+			return nil, err
+		}
+		txBuilder.AddSigners(signer)
+	}
+	changeAddress, err := w.address(keys[0])
+	if err != nil {
+		return nil, err
 	}
-	changeAddress := pickedUtxos[0].Spender
 	txBuilder.AddChangeIfNeeded(changeAddress)
 	tx, err := txBuilder.Build()
 	if err != nil {
@@ -106,6 +118,24 @@ func (w *Wallet) Transfer(receiver cardano.Address, amount *cardano.Value) (*car
 	return w.node.SubmitTx(context.Background(), tx)
 }
 
+// signer returns the external signer holding the key if any, or a signer using the key.
+func (w *Wallet) signer(key crypto.XPrvKey) (cardano.Signer, error) {
+	keyHash, err := key.PubKey().Hash()
+	if err != nil {
+		return nil, err
+	}
+	for _, signer := range w.signers {
+		signerHash, err := signer.KeyHash()
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(signerHash, keyHash) {
+			return signer, nil
+		}
+	}
+	return cardano.NewXPrvKeySigner(key), nil
+}
+
 // Balance returns the total lovelace amount of the wallet.
 func (w *Wallet) Balance() (*cardano.Value, error) {
 	balance := cardano.NewValue(0)
@@ -119,51 +149,121 @@ func (w *Wallet) Balance() (*cardano.Value, error) {
 	return balance, nil
 }
 
+// findUtxos returns the unspent outputs of the wallet, at the base and
+// enterprise addresses of its keys.
 func (w *Wallet) findUtxos() ([]cardano.UTxO, error) {
-	addrs, err := w.Addresses()
+	walletUtxos := []cardano.UTxO{}
+	for _, key := range w.keys() {
+		keyUtxos, err := w.keyUtxos(context.Background(), key)
+		if err != nil {
+			return nil, err
+		}
+		walletUtxos = append(walletUtxos, keyUtxos...)
+	}
+	return walletUtxos, nil
+}
+
+// keyUtxos returns the unspent outputs at the base and enterprise addresses
+// of a payment key.
+func (w *Wallet) keyUtxos(ctx context.Context, key crypto.XPrvKey) ([]cardano.UTxO, error) {
+	baseAddr, err := w.address(key)
 	if err != nil {
 		return nil, err
 	}
-	walletUtxos := []cardano.UTxO{}
-	for _, addr := range addrs {
-		addrUtxos, err := w.node.UTxOs(context.Background(), addr)
+	enterpriseAddr, err := w.enterpriseAddress(key)
+	if err != nil {
+		return nil, err
+	}
+	utxos := []cardano.UTxO{}
+	for _, addr := range []cardano.Address{baseAddr, enterpriseAddr} {
+		addrUtxos, err := w.node.UTxOs(ctx, addr)
 		if err != nil {
 			return nil, err
 		}
-		walletUtxos = append(walletUtxos, addrUtxos...)
+		utxos = append(utxos, addrUtxos...)
 	}
-	return walletUtxos, nil
+	return utxos, nil
 }
 
-// AddAddress generates a new payment address and adds it to the wallet.
-func (w *Wallet) AddAddress() (cardano.Address, error) {
-	index := uint32(len(w.addrKeys))
-	newKey := w.rootKey.Derive(index)
-	w.addrKeys = append(w.addrKeys, newKey)
-	payment, err := cardano.NewKeyCredential(newKey.PubKey())
+// keys returns the payment keys of the external and internal chains.
+func (w *Wallet) keys() []crypto.XPrvKey {
+	return append(w.addrKeys[:len(w.addrKeys):len(w.addrKeys)], w.changeKeys...)
+}
+
+// paymentKey returns the key of the payment credential of a base or enterprise
+// address, if it belongs to the wallet.
+func (w *Wallet) paymentKey(addr cardano.Address) (crypto.XPrvKey, bool, error) {
+	if addr.Type != cardano.Base && addr.Type != cardano.Enterprise {
+		return nil, false, nil
+	}
+	for _, key := range w.keys() {
+		payment, err := cardano.NewKeyCredential(key.PubKey())
+		if err != nil {
+			return nil, false, err
+		}
+		if payment.Equal(addr.Payment) {
+			return key, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// address returns the CIP-1852 base address of a payment key, delegating to
+// the stake key of the wallet.
+func (w *Wallet) address(key crypto.XPrvKey) (cardano.Address, error) {
+	payment, err := cardano.NewKeyCredential(key.PubKey())
+	if err != nil {
+		return cardano.Address{}, err
+	}
+	stake, err := cardano.NewKeyCredential(w.stakeKey.PubKey())
+	if err != nil {
+		return cardano.Address{}, err
+	}
+	return cardano.NewBaseAddress(w.network, payment, stake)
+}
+
+// enterpriseAddress returns the address of a payment key without delegation,
+// as generated by the earlier versions of the wallet.
+func (w *Wallet) enterpriseAddress(key crypto.XPrvKey) (cardano.Address, error) {
+	payment, err := cardano.NewKeyCredential(key.PubKey())
 	if err != nil {
 		return cardano.Address{}, err
 	}
 	return cardano.NewEnterpriseAddress(w.network, payment)
 }
 
-// Addresses returns all wallet's addresss.
+// AddAddress generates a new payment key and returns its base address.
+func (w *Wallet) AddAddress() (cardano.Address, error) {
+	index := uint32(len(w.addrKeys))
+	newKey := w.rootKey.Derive(index)
+	w.addrKeys = append(w.addrKeys, newKey)
+	return w.address(newKey)
+}
+
+// Addresses returns the base addresses of the wallet's payment keys. The
+// outputs sent to their enterprise addresses belong to the wallet too.
 func (w *Wallet) Addresses() ([]cardano.Address, error) {
 	addresses := make([]cardano.Address, len(w.addrKeys))
 	for i, key := range w.addrKeys {
-		payment, err := cardano.NewKeyCredential(key.PubKey())
+		addr, err := w.address(key)
 		if err != nil {
 			return nil, err
 		}
-		enterpriseAddr, err := cardano.NewEnterpriseAddress(w.network, payment)
-		if err != nil {
-			return nil, err
-		}
-		addresses[i] = enterpriseAddr
+		addresses[i] = addr
 	}
 	return addresses, nil
 }
 
+// RewardAddress returns the stake address of the wallet, receiving the rewards
+// of the funds at its base addresses.
+func (w *Wallet) RewardAddress() (cardano.Address, error) {
+	stake, err := cardano.NewKeyCredential(w.stakeKey.PubKey())
+	if err != nil {
+		return cardano.Address{}, err
+	}
+	return cardano.NewStakeAddress(w.network, stake)
+}
+
 func (w *Wallet) Keys() (crypto.PrvKey, crypto.PrvKey) {
 	return w.addrKeys[0].PrvKey(), w.stakeKey.PrvKey()
 }
@@ -183,28 +283,33 @@ func newWallet(name, password string, entropy []byte) *Wallet {
 	stakeKey := accountKey.Derive(cardano.StakingRole).Derive(0)
 	addr0Key := chainKey.Derive(0)
 	wallet.rootKey = chainKey
+	wallet.changeKey = accountKey.Derive(cardano.InternalChainRole)
 	wallet.addrKeys = []crypto.XPrvKey{addr0Key}
 	wallet.stakeKey = stakeKey
 	return wallet
 }
 
 type walletDump struct {
-	ID       string
-	Name     string
-	Keys     []crypto.XPrvKey
-	StakeKey crypto.XPrvKey
-	RootKey  crypto.XPrvKey
-	Network  cardano.Network
+	ID         string
+	Name       string
+	Keys       []crypto.XPrvKey
+	ChangeKeys []crypto.XPrvKey `json:",omitempty"`
+	StakeKey   crypto.XPrvKey
+	RootKey    crypto.XPrvKey
+	ChangeKey  crypto.XPrvKey `json:",omitempty"`
+	Network    cardano.Network
 }
 
 func (w *Wallet) marshal() ([]byte, error) {
 	wd := &walletDump{
-		ID:       w.ID,
-		Name:     w.Name,
-		Keys:     w.addrKeys,
-		StakeKey: w.stakeKey,
-		RootKey:  w.rootKey,
-		Network:  w.network,
+		ID:         w.ID,
+		Name:       w.Name,
+		Keys:       w.addrKeys,
+		ChangeKeys: w.changeKeys,
+		StakeKey:   w.stakeKey,
+		RootKey:    w.rootKey,
+		ChangeKey:  w.changeKey,
+		Network:    w.network,
 	}
 	bytes, err := json.Marshal(wd)
 	if err != nil {
@@ -222,8 +327,10 @@ func (w *Wallet) unmarshal(bytes []byte) error {
 	w.ID = wd.ID
 	w.Name = wd.Name
 	w.addrKeys = wd.Keys
+	w.changeKeys = wd.ChangeKeys
 	w.stakeKey = wd.StakeKey
 	w.rootKey = wd.RootKey
+	w.changeKey = wd.ChangeKey
 	w.network = wd.Network
 	return nil
 }
@@ -1,10 +1,12 @@
 package wallet
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
 	"github.com/cryptogarageinc/cardano-go"
+	"github.com/cryptogarageinc/cardano-go/emulator"
 	"github.com/cryptogarageinc/cardano-go/internal/bech32"
 	"github.com/tyler-smith/go-bip39"
 )
@@ -43,8 +45,73 @@ func TestGenerateAddress(t *testing.T) {
 			t.Errorf("invalid addrXvk1 :\ngot: %v\nwant: %v", addrXvk1, testVector.addrXvk1)
 		}
 
-		if paymentAddr1.Bech32() != testVector.paymentAddr1 {
-			t.Errorf("invalid paymentAddr1:\ngot: %v\nwant: %v", paymentAddr1, testVector.paymentAddr1)
+		if paymentAddr1.Bech32() != testVector.baseAddr1 {
+			t.Errorf("invalid baseAddr1:\ngot: %v\nwant: %v", paymentAddr1, testVector.baseAddr1)
+		}
+		enterpriseAddr1, err := w.enterpriseAddress(w.addrKeys[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enterpriseAddr1.Bech32() != testVector.paymentAddr1 {
+			t.Errorf("invalid paymentAddr1:\ngot: %v\nwant: %v", enterpriseAddr1, testVector.paymentAddr1)
+		}
+
+		rewardAddr, err := w.RewardAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rewardAddr.Bech32() != testVector.rewardAddr {
+			t.Errorf("invalid rewardAddr:\ngot: %v\nwant: %v", rewardAddr, testVector.rewardAddr)
+		}
+	}
+}
+
+func TestPaymentKey(t *testing.T) {
+	client := NewClient(&Options{Node: &MockNode{}})
+	defer client.Close()
+	w, _, err := client.CreateWallet("test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs, err := w.Addresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewardAddr, err := w.RewardAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enterpriseAddr, err := cardano.NewEnterpriseAddress(w.network, addrs[0].Payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stake key is not a payment key
+	stakeKeyAddr, err := cardano.NewEnterpriseAddress(w.network, rewardAddr.Stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name string
+		addr cardano.Address
+		want bool
+	}{
+		{name: "Base", addr: addrs[0], want: true},
+		{name: "Enterprise", addr: enterpriseAddr, want: true},
+		{name: "Reward", addr: rewardAddr, want: false},
+		{name: "Other", addr: stakeKeyAddr, want: false},
+	}
+
+	for _, tc := range testcases {
+		key, ok, err := w.paymentKey(tc.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tc.want {
+			t.Errorf("invalid %v match: got %v want %v", tc.name, ok, tc.want)
+		}
+		if ok && !bytes.Equal(key, w.addrKeys[0]) {
+			t.Errorf("invalid %v key", tc.name)
 		}
 	}
 }
@@ -54,7 +121,13 @@ type MockNode struct {
 }
 
 func (n *MockNode) UTxOs(_ context.Context, addr cardano.Address) ([]cardano.UTxO, error) {
-	return n.utxos, nil
+	utxos := []cardano.UTxO{}
+	for _, utxo := range n.utxos {
+		if utxo.Spender.Bech32() == addr.Bech32() {
+			utxos = append(utxos, utxo)
+		}
+	}
+	return utxos, nil
 }
 
 func (n *MockNode) Tip(_ context.Context) (*cardano.NodeTip, error) {
@@ -74,13 +147,29 @@ func (n *MockNode) Network() cardano.Network {
 }
 
 func TestWalletBalance(t *testing.T) {
-	client := NewClient(&Options{
-		Node: &MockNode{utxos: []cardano.UTxO{{Amount: cardano.NewValue(100)}, {Amount: cardano.NewValue(33)}}},
-	})
+	node := &MockNode{}
+	client := NewClient(&Options{Node: node})
 	w, _, err := client.CreateWallet("test", "")
 	if err != nil {
 		t.Error(err)
 	}
+	addrs, err := w.Addresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enterpriseAddr, err := w.enterpriseAddress(w.addrKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewardAddr, err := w.RewardAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.utxos = []cardano.UTxO{
+		{Spender: addrs[0], Amount: cardano.NewValue(100)},
+		{Spender: enterpriseAddr, Amount: cardano.NewValue(33)},
+		{Spender: rewardAddr, Amount: cardano.NewValue(7)},
+	}
 
 	got, err := w.Balance()
 	if err != nil {
@@ -93,6 +182,74 @@ func TestWalletBalance(t *testing.T) {
 	}
 }
 
+func TestWalletTransfer(t *testing.T) {
+	node := emulator.NewNode(cardano.Testnet, &cardano.ProtocolParams{
+		MinFeeA:          44,
+		MinFeeB:          155381,
+		CoinsPerUTXOByte: 4310,
+		MaxTxSize:        16384,
+		MaxValueSize:     5000,
+	})
+	client := NewClient(&Options{Node: node})
+	defer client.Close()
+
+	sender, err := client.RestoreWallet("sender", "", testVectors[0].mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := client.RestoreWallet("receiver", "", testVectors[1].mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderAddrs, err := sender.Addresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiverAddrs, err := receiver.Addresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// funds at the enterprise address of the earlier versions are spent too
+	senderEnterpriseAddr, err := sender.enterpriseAddress(sender.addrKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.AddUTxO(senderAddrs[0], cardano.NewValue(60e6))
+	node.AddUTxO(senderEnterpriseAddr, cardano.NewValue(40e6))
+
+	txHash, err := sender.Transfer(receiverAddrs[0], cardano.NewValue(70e6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, ok := node.Tx(*txHash)
+	if !ok {
+		t.Fatal("transaction not found")
+	}
+
+	got, err := receiver.Balance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cardano.NewValue(70e6); got.Cmp(want) != 0 {
+		t.Errorf("invalid receiver balance:\ngot: %v\nwant: %v", got, want)
+	}
+	got, err = sender.Balance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cardano.NewValue(30e6 - tx.Body.Fee); got.Cmp(want) != 0 {
+		t.Errorf("invalid sender balance:\ngot: %v\nwant: %v", got, want)
+	}
+	if len(tx.Body.Inputs) != 2 {
+		t.Errorf("invalid inputs: got %v want %v", len(tx.Body.Inputs), 2)
+	}
+	for _, output := range tx.Body.Outputs {
+		if addr := output.Address.Bech32(); addr != receiverAddrs[0].Bech32() && addr != senderAddrs[0].Bech32() {
+			t.Errorf("invalid change address:\ngot: %v\nwant: %v", output.Address, senderAddrs[0])
+		}
+	}
+}
+
 func bech32From(hrp string, bytes []byte) string {
 	enc, _ := bech32.EncodeFromBase256(hrp, bytes)
 	return enc
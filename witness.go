@@ -0,0 +1,331 @@
+package cardano
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+// InvalidWitnessError is returned when the signature of a witness does not
+// verify the transaction body hash.
+type InvalidWitnessError struct {
+	Index     int
+	Bootstrap bool
+	VKey      crypto.PubKey
+}
+
+func (e *InvalidWitnessError) Error() string {
+	kind := "vkey"
+	if e.Bootstrap {
+		kind = "bootstrap"
+	}
+	return fmt.Sprintf("invalid %v witness %v signature for key %v", kind, e.Index, e.VKey.String())
+}
+
+// VerifyWitnesses verifies the signatures of the vkey and bootstrap witnesses over
+// the transaction body hash. All the invalid witnesses are returned joined, each of
+// them can be inspected using errors.As.
+func (tx *Tx) VerifyWitnesses() error {
+	txHash, err := tx.Hash()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, witness := range tx.WitnessSet.VKeyWitnessSet {
+		if len(witness.VKey) != ed25519PubKeySize || !witness.VKey.Verify(txHash, witness.Signature) {
+			errs = append(errs, &InvalidWitnessError{Index: i, VKey: witness.VKey})
+		}
+	}
+	for i, witness := range tx.WitnessSet.BootstrapWitnesses {
+		if len(witness.VKey) != ed25519PubKeySize || !witness.VKey.Verify(txHash, witness.Signature) {
+			errs = append(errs, &InvalidWitnessError{Index: i, Bootstrap: true, VKey: witness.VKey})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// MissingWitnesses reports the credentials and scripts of a transaction
+// that are not witnessed.
+type MissingWitnesses struct {
+	// PaymentKeyHashes are the payment keys of the spent and collateral inputs.
+	PaymentKeyHashes []AddrKeyHash
+
+	// StakeKeyHashes are the stake and pool keys of the withdrawals and certificates.
+	StakeKeyHashes []AddrKeyHash
+
+	// RequiredSigners are the required signers of the transaction body.
+	RequiredSigners []AddrKeyHash
+
+	// Scripts are the scripts locking the spent inputs, withdrawals and certificates,
+	// and the minting policies, not provided by the witness set nor by reference.
+	Scripts []Hash28
+
+	// NativeScripts are the native scripts provided whose conditions are not satisfied
+	// by the vkey witnesses and the validity interval.
+	NativeScripts []Hash28
+}
+
+// IsEmpty returns true if no witness is missing.
+func (m *MissingWitnesses) IsEmpty() bool {
+	return len(m.PaymentKeyHashes) == 0 &&
+		len(m.StakeKeyHashes) == 0 &&
+		len(m.RequiredSigners) == 0 &&
+		len(m.Scripts) == 0 &&
+		len(m.NativeScripts) == 0
+}
+
+// MissingWitnesses returns the witnesses missing in the transaction, given the
+// UTxOs resolving the spent, collateral and reference inputs.
+// Signatures are not verified, use VerifyWitnesses.
+func (tx *Tx) MissingWitnesses(utxos []UTxO) (*MissingWitnesses, error) {
+	resolved := make(map[string]UTxO, len(utxos))
+	for _, utxo := range utxos {
+		resolved[outRef(utxo.TxHash, utxo.Index)] = utxo
+	}
+	resolve := func(in *TxInput) (UTxO, error) {
+		utxo, ok := resolved[in.outRef()]
+		if !ok {
+			return UTxO{}, fmt.Errorf("unresolved input %v", in)
+		}
+		return utxo, nil
+	}
+
+	witnessed := map[string]struct{}{}
+	for _, witness := range tx.WitnessSet.VKeyWitnessSet {
+		keyHash, err := witness.VKey.Hash()
+		if err != nil {
+			return nil, err
+		}
+		witnessed[Hash28(keyHash).String()] = struct{}{}
+	}
+
+	scripts, err := tx.providedScripts()
+	if err != nil {
+		return nil, err
+	}
+	spent := make([]UTxO, 0, len(tx.Body.Inputs)+len(tx.Body.Collateral))
+	for _, in := range tx.Body.Inputs {
+		utxo, err := resolve(in)
+		if err != nil {
+			return nil, err
+		}
+		if err := addScriptRef(scripts, utxo.ScriptRef); err != nil {
+			return nil, err
+		}
+		spent = append(spent, utxo)
+	}
+	for i := range tx.Body.Collateral {
+		utxo, err := resolve(&tx.Body.Collateral[i])
+		if err != nil {
+			return nil, err
+		}
+		spent = append(spent, utxo)
+	}
+	for _, in := range tx.Body.ReferenceInputs {
+		utxo, err := resolve(in)
+		if err != nil {
+			return nil, err
+		}
+		if err := addScriptRef(scripts, utxo.ScriptRef); err != nil {
+			return nil, err
+		}
+	}
+
+	missing := &MissingWitnesses{}
+	seen := map[string]struct{}{}
+	checkKeyHash := func(list *[]AddrKeyHash, keyHash AddrKeyHash) {
+		key := keyHash.String()
+		if _, ok := witnessed[key]; ok {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		*list = append(*list, keyHash)
+	}
+	checkScript := func(scriptHash Hash28) {
+		key := scriptHash.String()
+		if _, ok := scripts[key]; ok {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		missing.Scripts = append(missing.Scripts, scriptHash)
+	}
+	checkCredential := func(list *[]AddrKeyHash, cred StakeCredential) {
+		if cred.Type == KeyCredential {
+			checkKeyHash(list, cred.KeyHash)
+		} else {
+			checkScript(cred.ScriptHash)
+		}
+	}
+
+	for _, utxo := range spent {
+		checkCredential(&missing.PaymentKeyHashes, utxo.Spender.Payment)
+	}
+	if tx.Body.Withdrawals != nil {
+		for _, rewardAccount := range tx.Body.Withdrawals.Keys() {
+			checkCredential(&missing.StakeKeyHashes, rewardAccount.Stake)
+		}
+	}
+	for _, cert := range tx.Body.Certificates {
+		for _, cred := range cert.witnessCredentials() {
+			checkCredential(&missing.StakeKeyHashes, cred)
+		}
+	}
+	for _, signer := range tx.Body.RequiredSigners {
+		checkKeyHash(&missing.RequiredSigners, signer)
+	}
+	if tx.Body.Mint != nil {
+		for _, policyID := range tx.Body.Mint.Keys() {
+			checkScript(policyID.Bytes())
+		}
+	}
+
+	for i := range tx.WitnessSet.Scripts {
+		script := &tx.WitnessSet.Scripts[i]
+		if script.satisfied(witnessed, tx.Body.ValidityIntervalStart, tx.Body.TTL) {
+			continue
+		}
+		scriptHash, err := script.Hash()
+		if err != nil {
+			return nil, err
+		}
+		missing.NativeScripts = append(missing.NativeScripts, scriptHash)
+	}
+
+	return missing, nil
+}
+
+// providedScripts returns the hashes of the scripts of the witness set.
+func (tx *Tx) providedScripts() (map[string]struct{}, error) {
+	scripts := map[string]struct{}{}
+	for i := range tx.WitnessSet.Scripts {
+		if err := addScriptRef(scripts, NewNativeScriptRef(tx.WitnessSet.Scripts[i])); err != nil {
+			return nil, err
+		}
+	}
+	plutusScripts := map[ScriptHashNamespace][]PlutusScript{
+		PlutusScriptNamespace:   tx.WitnessSet.PlutusV1Scripts,
+		PlutusV2ScriptNamespace: tx.WitnessSet.PlutusV2Scripts,
+		PlutusV3ScriptNamespace: tx.WitnessSet.PlutusV3Scripts,
+	}
+	for version, list := range plutusScripts {
+		for _, script := range list {
+			if err := addScriptRef(scripts, NewPlutusScriptRef(version, script)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return scripts, nil
+}
+
+func addScriptRef(scripts map[string]struct{}, scriptRef *ScriptRef) error {
+	if scriptRef == nil {
+		return nil
+	}
+	scriptHash, err := scriptRef.Hash()
+	if err != nil {
+		return err
+	}
+	scripts[scriptHash.String()] = struct{}{}
+	return nil
+}
+
+// Witness returns a detached vkey witness of the transaction body signed with
+// the private key. The transaction is not modified.
+func (tx *Tx) Witness(key crypto.PrvKey) (VKeyWitness, error) {
+	txHash, err := tx.Hash()
+	if err != nil {
+		return VKeyWitness{}, err
+	}
+	return VKeyWitness{VKey: key.PubKey(), Signature: key.Sign(txHash)}, nil
+}
+
+// AddVKeyWitnesses verifies and adds vkey witnesses to the transaction.
+// Witnesses of keys already witnessing the transaction are skipped.
+func (tx *Tx) AddVKeyWitnesses(witnesses ...VKeyWitness) error {
+	return tx.MergeWitnessSet(&WitnessSet{VKeyWitnessSet: witnesses})
+}
+
+// MergeWitnessSet merges the witnesses of another witness set, for example the
+// one of a copy of the transaction signed by another party. Signatures are verified
+// before merging and duplicated witnesses are skipped.
+func (tx *Tx) MergeWitnessSet(ws *WitnessSet) error {
+	txHash, err := tx.Hash()
+	if err != nil {
+		return err
+	}
+	for i, witness := range ws.VKeyWitnessSet {
+		if len(witness.VKey) != ed25519PubKeySize || !witness.VKey.Verify(txHash, witness.Signature) {
+			return &InvalidWitnessError{Index: i, VKey: witness.VKey}
+		}
+	}
+	for i, witness := range ws.BootstrapWitnesses {
+		if len(witness.VKey) != ed25519PubKeySize || !witness.VKey.Verify(txHash, witness.Signature) {
+			return &InvalidWitnessError{Index: i, Bootstrap: true, VKey: witness.VKey}
+		}
+	}
+
+	set := &tx.WitnessSet
+	for _, witness := range ws.VKeyWitnessSet {
+		if !containsWitness(set.VKeyWitnessSet, witness, func(w VKeyWitness) string { return string(w.VKey) }) {
+			set.VKeyWitnessSet = append(set.VKeyWitnessSet, witness)
+		}
+	}
+	for _, witness := range ws.BootstrapWitnesses {
+		if !containsWitness(set.BootstrapWitnesses, witness, func(w BootstrapWitness) string { return string(w.VKey) }) {
+			set.BootstrapWitnesses = append(set.BootstrapWitnesses, witness)
+		}
+	}
+	for _, script := range ws.Scripts {
+		if !containsWitness(set.Scripts, script, func(s NativeScript) string { b, _ := s.Bytes(); return string(b) }) {
+			set.Scripts = append(set.Scripts, script)
+		}
+	}
+	plutusScript := func(s PlutusScript) string { return string(s) }
+	for _, script := range ws.PlutusV1Scripts {
+		if !containsWitness(set.PlutusV1Scripts, script, plutusScript) {
+			set.PlutusV1Scripts = append(set.PlutusV1Scripts, script)
+		}
+	}
+	for _, script := range ws.PlutusV2Scripts {
+		if !containsWitness(set.PlutusV2Scripts, script, plutusScript) {
+			set.PlutusV2Scripts = append(set.PlutusV2Scripts, script)
+		}
+	}
+	for _, script := range ws.PlutusV3Scripts {
+		if !containsWitness(set.PlutusV3Scripts, script, plutusScript) {
+			set.PlutusV3Scripts = append(set.PlutusV3Scripts, script)
+		}
+	}
+	for _, data := range ws.PlutusData {
+		if !containsWitness(set.PlutusData, data, func(d PlutusData) string { return string(d) }) {
+			set.PlutusData = append(set.PlutusData, data)
+		}
+	}
+	redeemerKey := func(r Redeemer) string { return fmt.Sprintf("%v:%v", r.Tag, r.Index) }
+	for _, redeemer := range ws.Redeemers {
+		if !containsWitness(set.Redeemers, redeemer, redeemerKey) {
+			set.Redeemers = append(set.Redeemers, redeemer)
+		}
+	}
+
+	return nil
+}
+
+func containsWitness[T any](list []T, item T, key func(T) string) bool {
+	itemKey := key(item)
+	for _, v := range list {
+		if key(v) == itemKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,492 @@
+package cardano
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cryptogarageinc/cardano-go/crypto"
+)
+
+func TestVerifyWitnesses(t *testing.T) {
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	payment, err := NewKeyCredential(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := NewEnterpriseAddress(Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBuilder := NewTxBuilder(alonzoProtocol)
+	txBuilder.AddInputs(&TxInput{TxHash: txHash, Index: 0, Amount: NewValue(10e6), Spender: &addr})
+	txBuilder.AddOutputs(NewTxOutput(addr, NewValue(5e6)))
+	txBuilder.AddChangeIfNeeded(addr)
+	txBuilder.Sign(paymentKey.PrvKey())
+	tx, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.VerifyWitnesses(); err != nil {
+		t.Fatalf("valid witnesses: %v", err)
+	}
+
+	signature := tx.WitnessSet.VKeyWitnessSet[0].Signature
+	signature[0] ^= 0xff
+	err = tx.VerifyWitnesses()
+	var witnessErr *InvalidWitnessError
+	if !errors.As(err, &witnessErr) {
+		t.Fatalf("invalid error: got %v want %T", err, witnessErr)
+	}
+	if got, want := witnessErr.Index, 0; got != want {
+		t.Errorf("invalid witness index: got %v want %v", got, want)
+	}
+	signature[0] ^= 0xff
+
+	tx.Body.Fee++
+	if err := tx.VerifyWitnesses(); !errors.As(err, &witnessErr) {
+		t.Errorf("modified body: got %v want %T", err, witnessErr)
+	}
+}
+
+func TestMissingWitnesses(t *testing.T) {
+	paymentKey := crypto.NewXPrvKeyFromEntropy([]byte("payment"), "")
+	stakeKey := crypto.NewXPrvKeyFromEntropy([]byte("stake"), "")
+	signerKey := crypto.NewXPrvKeyFromEntropy([]byte("signer"), "")
+	policyKey := crypto.NewXPrvKeyFromEntropy([]byte("policy"), "")
+
+	payment, err := NewKeyCredential(paymentKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stake, err := NewKeyCredential(stakeKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := NewEnterpriseAddress(Testnet, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewardAccount, err := NewStakeAddress(Testnet, stake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerHash, err := signerKey.PubKey().Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policyScript, err := NewScriptPubKey(policyKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyID, err := NewPolicyID(policyScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timelock := NativeScript{Type: ScriptAll, Scripts: []NativeScript{
+		policyScript,
+		{Type: ScriptInvalidAfter, IntervalValue: 1000},
+	}}
+	timelockHash, err := timelock.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptAddr, err := NewEnterpriseAddress(Testnet, NewScriptCredentialWithHash(timelockHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := []UTxO{
+		{TxHash: txHash, Index: 0, Spender: addr, Amount: NewValue(10e6)},
+		{TxHash: txHash, Index: 1, Spender: scriptAddr, Amount: NewValue(10e6)},
+		{TxHash: txHash, Index: 2, Spender: addr, Amount: NewValue(10e6), ScriptRef: NewNativeScriptRef(timelock)},
+	}
+
+	testcases := []struct {
+		name    string
+		setup   func(tx *Tx)
+		keys    []crypto.XPrvKey
+		want    func(m *MissingWitnesses) []Hash28
+		wantLen int
+	}{
+		{
+			name:    "PaymentKey",
+			setup:   func(tx *Tx) {},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.PaymentKeyHashes },
+			wantLen: 1,
+		},
+		{
+			name:  "Complete",
+			setup: func(tx *Tx) {},
+			keys:  []crypto.XPrvKey{paymentKey},
+		},
+		{
+			name: "Withdrawal",
+			setup: func(tx *Tx) {
+				tx.Body.Withdrawals = NewWithdrawals().Set(rewardAccount, 1e6)
+			},
+			keys:    []crypto.XPrvKey{paymentKey},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.StakeKeyHashes },
+			wantLen: 1,
+		},
+		{
+			name: "Certificate",
+			setup: func(tx *Tx) {
+				tx.Body.Certificates = append(tx.Body.Certificates, Certificate{Type: StakeDeregistration, StakeCredential: stake})
+			},
+			keys:    []crypto.XPrvKey{paymentKey, stakeKey},
+			wantLen: 0,
+		},
+		{
+			name: "RequiredSigner",
+			setup: func(tx *Tx) {
+				tx.Body.RequiredSigners = []AddrKeyHash{signerHash}
+			},
+			keys:    []crypto.XPrvKey{paymentKey},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.RequiredSigners },
+			wantLen: 1,
+		},
+		{
+			name: "MintPolicy",
+			setup: func(tx *Tx) {
+				tx.Body.Mint = NewMint().Set(policyID, NewMintAssets())
+			},
+			keys:    []crypto.XPrvKey{paymentKey},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.Scripts },
+			wantLen: 1,
+		},
+		{
+			name: "MintPolicyUnsigned",
+			setup: func(tx *Tx) {
+				tx.Body.Mint = NewMint().Set(policyID, NewMintAssets())
+				tx.WitnessSet.Scripts = []NativeScript{policyScript}
+			},
+			keys:    []crypto.XPrvKey{paymentKey},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.NativeScripts },
+			wantLen: 1,
+		},
+		{
+			name: "ScriptInput",
+			setup: func(tx *Tx) {
+				tx.Body.Inputs = append(tx.Body.Inputs, &TxInput{TxHash: txHash, Index: 1})
+			},
+			keys:    []crypto.XPrvKey{paymentKey},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.Scripts },
+			wantLen: 1,
+		},
+		{
+			name: "ScriptInputReference",
+			setup: func(tx *Tx) {
+				tx.Body.Inputs = append(tx.Body.Inputs, &TxInput{TxHash: txHash, Index: 1})
+				tx.Body.ReferenceInputs = []*TxInput{{TxHash: txHash, Index: 2}}
+				tx.Body.TTL = NewUint64(1000)
+			},
+			keys: []crypto.XPrvKey{paymentKey, policyKey},
+		},
+		{
+			name: "ScriptInputExpired",
+			setup: func(tx *Tx) {
+				tx.Body.Inputs = append(tx.Body.Inputs, &TxInput{TxHash: txHash, Index: 1})
+				tx.WitnessSet.Scripts = []NativeScript{timelock}
+				tx.Body.TTL = NewUint64(1001)
+			},
+			keys:    []crypto.XPrvKey{paymentKey, policyKey},
+			want:    func(m *MissingWitnesses) []Hash28 { return m.NativeScripts },
+			wantLen: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := &Tx{
+				Body: TxBody{
+					Inputs:  []*TxInput{{TxHash: txHash, Index: 0}},
+					Outputs: []*TxOutput{NewTxOutput(addr, NewValue(5e6))},
+				},
+			}
+			tc.setup(tx)
+			for _, key := range tc.keys {
+				tx.WitnessSet.VKeyWitnessSet = append(tx.WitnessSet.VKeyWitnessSet, VKeyWitness{VKey: key.PubKey()})
+			}
+
+			missing, err := tx.MissingWitnesses(utxos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want == nil {
+				if !missing.IsEmpty() {
+					t.Errorf("unexpected missing witnesses: %+v", missing)
+				}
+				return
+			}
+			if got := len(tc.want(missing)); got != tc.wantLen {
+				t.Errorf("invalid missing witnesses: got %+v", missing)
+			}
+		})
+	}
+
+	tx := &Tx{Body: TxBody{Inputs: []*TxInput{{TxHash: txHash, Index: 9}}}}
+	if _, err := tx.MissingWitnesses(utxos); err == nil {
+		t.Error("expected error for unresolved input")
+	}
+}
+
+func TestMergeWitnesses(t *testing.T) {
+	aliceKey := crypto.NewXPrvKeyFromEntropy([]byte("alice"), "")
+	bobKey := crypto.NewXPrvKeyFromEntropy([]byte("bob"), "")
+	alice, err := NewKeyCredential(aliceKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewKeyCredential(bobKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceAddr, err := NewEnterpriseAddress(Testnet, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobAddr, err := NewEnterpriseAddress(Testnet, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash, err := NewHash32("030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBuilder := NewTxBuilder(alonzoProtocol)
+	txBuilder.AddInputs(
+		&TxInput{TxHash: txHash, Index: 0, Amount: NewValue(10e6), Spender: &aliceAddr},
+		&TxInput{TxHash: txHash, Index: 1, Amount: NewValue(10e6), Spender: &bobAddr},
+	)
+	txBuilder.AddOutputs(NewTxOutput(aliceAddr, NewValue(15e6)))
+	txBuilder.AddChangeIfNeeded(bobAddr)
+	unsigned, err := txBuilder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round trip the unsigned transaction through a cardano-cli file, as a
+	// third party would receive it.
+	dir := t.TempDir()
+	if err := NewTxTextEnvelope(unsigned, ConwayEra).WriteFile(dir + "/tx.unsigned"); err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := ReadTextEnvelopeFile(dir + "/tx.unsigned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := envelope.Type, "Unwitnessed Tx ConwayEra"; got != want {
+		t.Errorf("invalid envelope type: got %v want %v", got, want)
+	}
+	tx, err := envelope.Tx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash, err := unsigned.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, key := range []crypto.XPrvKey{aliceKey, bobKey} {
+		witness, err := tx.Witness(key.PrvKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+		witnessEnvelope, err := NewWitnessTextEnvelope(witness, ConwayEra)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := fmt.Sprintf("%s/tx.witness.%d", dir, i)
+		if err := witnessEnvelope.WriteFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		witnessEnvelope, err := ReadTextEnvelopeFile(fmt.Sprintf("%s/tx.witness.%d", dir, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ws, err := witnessEnvelope.WitnessSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Merging twice must not duplicate the witness.
+		for j := 0; j < 2; j++ {
+			if err := tx.MergeWitnessSet(ws); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	gotHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotHash, wantHash) {
+		t.Errorf("transaction body modified: got %v want %v", gotHash, wantHash)
+	}
+	if got, want := len(tx.WitnessSet.VKeyWitnessSet), 2; got != want {
+		t.Errorf("invalid number of witnesses: got %v want %v", got, want)
+	}
+	if err := tx.VerifyWitnesses(); err != nil {
+		t.Error(err)
+	}
+	utxos := []UTxO{
+		{TxHash: txHash, Index: 0, Spender: aliceAddr, Amount: NewValue(10e6)},
+		{TxHash: txHash, Index: 1, Spender: bobAddr, Amount: NewValue(10e6)},
+	}
+	missing, err := tx.MissingWitnesses(utxos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !missing.IsEmpty() {
+		t.Errorf("unexpected missing witnesses: %+v", missing)
+	}
+
+	// A witness of another transaction is rejected.
+	alicePrv := aliceKey.PrvKey()
+	otherWitness := VKeyWitness{VKey: aliceKey.PubKey(), Signature: alicePrv.Sign(txHash)}
+	var witnessErr *InvalidWitnessError
+	if err := tx.AddVKeyWitnesses(otherWitness); !errors.As(err, &witnessErr) {
+		t.Errorf("invalid error: got %v want %T", err, witnessErr)
+	}
+}
+
+func TestWitnessTextEnvelope(t *testing.T) {
+	key := crypto.NewXPrvKeyFromEntropy([]byte("alice"), "")
+	prv := key.PrvKey()
+	witness := VKeyWitness{VKey: key.PubKey(), Signature: prv.Sign([]byte("body hash"))}
+	envelope, err := NewWitnessTextEnvelope(witness, BabbageEra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := envelope.Type, "TxWitness BabbageEra"; got != want {
+		t.Errorf("invalid envelope type: got %v want %v", got, want)
+	}
+	wantHex := "82008258" + "20" + witness.VKey.String() + "5840" + hex.EncodeToString(witness.Signature)
+	if got := envelope.CborHex; got != wantHex {
+		t.Errorf("invalid witness cbor:\ngot: %v\nwant: %v", got, wantHex)
+	}
+	ws, err := envelope.WitnessSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws.VKeyWitnessSet) != 1 || !bytes.Equal(ws.VKeyWitnessSet[0].Signature, witness.Signature) {
+		t.Errorf("invalid witness set: %+v", ws)
+	}
+	if _, err := envelope.Tx(); err == nil {
+		t.Error("expected error decoding a witness envelope as a transaction")
+	}
+	if era, err := envelope.Era(); err != nil || era != BabbageEra {
+		t.Errorf("invalid envelope era: got %v, %v want %v", era, err, BabbageEra)
+	}
+}
+
+func TestTextEnvelopeEra(t *testing.T) {
+	testcases := []struct {
+		typ     string
+		want    Era
+		wantErr bool
+	}{
+		{typ: "Unwitnessed Tx BabbageEra", want: BabbageEra},
+		{typ: "Witnessed Tx ConwayEra", want: ConwayEra},
+		{typ: "Tx AlonzoEra", want: AlonzoEra},
+		{typ: "TxWitness ShelleyEra", want: ShelleyEra},
+		{typ: "Tx UnknownEra", wantErr: true},
+		{typ: "", wantErr: true},
+	}
+	for _, tc := range testcases {
+		te := &TextEnvelope{Type: tc.typ}
+		got, err := te.Era()
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.typ)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.typ, err)
+		} else if got != tc.want {
+			t.Errorf("%q: invalid era: got %v want %v", tc.typ, got, tc.want)
+		}
+	}
+}
+
+func TestMergeWitnessSetConway(t *testing.T) {
+	// A Conway transaction spending a script output, with tagged sets, map
+	// redeemers and a script data hash covering their encoding.
+	txHex := "84a500d9010281825820030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f3518000181a200581d607e92b789d9ff83876931dfed4d71d6e4ce1e332d4a4ef3816f52bf6a011a001e8480021a00030d400b5820cbf9be10fe739963407e1d842c6a9cdab75d9565f104eb0670bc2dacf9c2423c0dd9010281825820030858db80bf94041b7b1c6fbc0754a9bd7113ec9025b1157a9a4e02135f351801a400d901028182582017166af4b5dd5931d4223371044939894dad823edf166540e6b4334adab0ba8c5840910f7e0e1bcdfca3ef11d61e5092e2a60c8d0712f985ef9b1606cd5583c91ec8f6abe197efc6fc96e0d87eae519bcba941bacdb2a03caeeb810529eeccb4ef0d04d9010281d8798005a182000082d87980821a000f42401a05f5e10006814e4d01000033222220051200120011f5f6"
+	data, err := hex.DecodeString(txHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &Tx{}
+	if err := tx.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := tx.Hex(); got != txHex {
+		t.Fatalf("invalid encoding:\ngot: %v\nwant: %v", got, txHex)
+	}
+	if got, want := len(tx.WitnessSet.Redeemers), 1; got != want {
+		t.Fatalf("invalid number of redeemers: got %v want %v", got, want)
+	}
+	wantHash, err := tx.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bobKey := crypto.NewXPrvKeyFromEntropy([]byte("bob"), "")
+	witness, err := tx.Witness(bobKey.PrvKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.AddVKeyWitnesses(witness); err != nil {
+		t.Fatal(err)
+	}
+
+	got := &Tx{}
+	if err := got.UnmarshalCBOR(tx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	gotHash, err := got.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotHash, wantHash) {
+		t.Errorf("transaction body modified: got %v want %v", gotHash, wantHash)
+	}
+	if err := got.VerifyWitnesses(); err != nil {
+		t.Error(err)
+	}
+	if got, want := len(got.WitnessSet.VKeyWitnessSet), 2; got != want {
+		t.Errorf("invalid number of witnesses: got %v want %v", got, want)
+	}
+	wantFields := map[uint64]string{
+		4: "d9010281d87980",
+		5: "a182000082d87980821a000f42401a05f5e100",
+		6: "814e4d01000033222220051200120011",
+	}
+	for key, want := range wantFields {
+		if got := hex.EncodeToString(got.WitnessSet.rawFields[key]); got != want {
+			t.Errorf("witness set field %v modified:\ngot: %v\nwant: %v", key, got, want)
+		}
+	}
+	if vkeys := got.WitnessSet.rawFields[0]; !bytes.HasPrefix(vkeys, setTag) {
+		t.Errorf("untagged vkey witnesses: %x", vkeys)
+	}
+}